@@ -1,22 +1,268 @@
 package cmd
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/AntonyIS/notelify-articles-service/config"
 	"github.com/AntonyIS/notelify-articles-service/internal/adapters/app"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/events/fanout"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/events/webhook"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/grpcapp"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/imaging"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/dynamodb"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/localfs"
 	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/postgres"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/s3"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/sqlite"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/services"
+	"github.com/redis/go-redis/v9"
 )
 
-func RunService() {
-	// Read application environment and load configurations
-	conf, err := config.NewConfig()
+// Execute dispatches to the subcommand named by the first argument. With no
+// arguments the HTTP service is started.
+func Execute(args []string) {
+	if len(args) == 0 {
+		RunService(nil)
+		return
+	}
+
+	switch args[0] {
+	case "serve":
+		RunService(args[1:])
+	case "export":
+		RunExport(args[1:])
+	case "import":
+		RunImport(args[1:])
+	case "config":
+		RunConfig(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected serve, export, import or config\n", args[0])
+		os.Exit(2)
+	}
+}
+
+// RunService starts the HTTP and gRPC servers and the background workers.
+// Configuration flags override the file and environment.
+func RunService(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	flags.Parse(args)
+
+	conf, databaseRepo, articleService, newLoggerService := newArticleService(loadConfig(loader))
+
+	// Deliver article domain events recorded in the outbox to the webhook
+	// subscriptions and, when configured, the events webhook
+	var webhookService ports.WebhookService
+	publishers := []ports.EventPublisher{}
+	if webhookRepo, ok := databaseRepo.(ports.WebhookRepository); ok {
+		webhooks := services.NewWebhookManagementService(webhookRepo, newLoggerService, conf.WEBHOOK_TIMEOUT, conf.WEBHOOK_MAX_ATTEMPTS, conf.WEBHOOK_RETRY_BASE)
+		go webhooks.Run(make(chan struct{}), conf.WEBHOOK_POLL_INTERVAL, 100)
+		webhookService = webhooks
+		publishers = append(publishers, webhooks)
+	} else {
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  conf.ARTICLE_STORE + " does not support webhook subscriptions, the webhook routes are disabled",
+		}
+		newLoggerService.LogWarning(logEntry)
+	}
+
+	// Keep uploaded media in the configured blob store, recording it next to
+	// the articles, and derive the variants of cover images in the background
+	var mediaService ports.MediaService
+	var blobs ports.BlobStore
+	if mediaRepo, ok := databaseRepo.(ports.MediaRepository); ok {
+		blobs = newBlobStore(*conf)
+		images := imaging.NewImageProcessor(conf.IMAGE_JPEG_QUALITY)
+		pipeline := services.NewImagePipeline(mediaRepo, blobs, images, newLoggerService, mediaBaseURL(*conf), conf.IMAGE_WIDTHS, conf.IMAGE_THUMBNAIL_SIZE, conf.IMAGE_FORMATS, conf.MEDIA_MAX_PIXELS, conf.IMAGE_WORKERS, conf.IMAGE_QUEUE_SIZE)
+		go pipeline.Run(make(chan struct{}))
+		mediaService = services.NewMediaManagementService(mediaRepo, databaseRepo, blobs, images, pipeline, newLoggerService, mediaBaseURL(*conf), conf.MEDIA_MAX_SIZE, conf.MEDIA_MAX_PIXELS, conf.MEDIA_ALLOWED_TYPES)
+	} else {
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  conf.ARTICLE_STORE + " does not support media, the media routes are disabled",
+		}
+		newLoggerService.LogWarning(logEntry)
+	}
+
+	// Permanently remove articles that outlived their time in the trash,
+	// with the content of their media
+	purgeWorker := services.NewPurgeWorker(databaseRepo, blobs, newLoggerService, conf.TRASH_RETENTION, conf.TRASH_PURGE_INTERVAL)
+	go purgeWorker.Run(make(chan struct{}))
+
+	var seriesService ports.SeriesService
+	if seriesRepo, ok := databaseRepo.(ports.SeriesRepository); ok {
+		seriesService = services.NewSeriesManagementService(seriesRepo, databaseRepo, newLoggerService)
+	} else {
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  conf.ARTICLE_STORE + " does not support series, the series routes are disabled",
+		}
+		newLoggerService.LogWarning(logEntry)
+	}
+
+	if outbox, ok := databaseRepo.(ports.OutboxRepository); ok {
+		if conf.EVENTS_WEBHOOK_URL != "" {
+			publishers = append(publishers, webhook.NewWebhookPublisher(conf.EVENTS_WEBHOOK_URL, conf.WEBHOOK_TIMEOUT))
+		}
+		relay := services.NewOutboxRelay(outbox, fanout.NewFanoutPublisher(publishers...), newLoggerService, conf.OUTBOX_POLL_INTERVAL, 100, conf.OUTBOX_MAX_ATTEMPTS, conf.OUTBOX_RETRY_BASE)
+		go relay.Run(make(chan struct{}))
+	}
+
+	// Run gRPC Server
+	go grpcapp.InitGRPCServer(articleService, newLoggerService, *conf)
+
+	// Run HTTP Server
+	app.InitGinRoutes(articleService, webhookService, mediaService, seriesService, newRateLimitStore(*conf), newLoggerService, *conf)
+}
+
+// RunExport writes every article in the configured environment's table to a
+// NDJSON file, or to stdout when no file is given.
+func RunExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	file := flags.String("file", "-", "NDJSON file to write, - for stdout")
+	loader := config.NewLoader(flags)
+	flags.Parse(args)
+
+	_, _, articleService, _ := newArticleService(loadConfig(loader))
+
+	var out io.Writer = os.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := articleService.ExportArticles(out); err != nil {
+		panic(err)
+	}
+}
+
+// RunImport upserts the articles of a NDJSON file, or stdin when no file is
+// given, and prints the import report.
+func RunImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "-", "NDJSON file to read, - for stdin")
+	loader := config.NewLoader(flags)
+	flags.Parse(args)
+
+	_, _, articleService, _ := newArticleService(loadConfig(loader))
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	report, err := articleService.ImportArticles(in)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if err != nil {
+		panic(err)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// newRateLimitStore shares rate limits through Redis when configured, and
+// keeps them per instance otherwise.
+func newRateLimitStore(conf config.Config) ratelimit.Store {
+	if conf.RATE_LIMIT_STORE != "redis" {
+		return ratelimit.NewMemoryStore()
+	}
+	options, err := redis.ParseURL(conf.REDIS_URL)
+	if err != nil {
+		panic(err)
+	}
+	return ratelimit.NewRedisStore(redis.NewClient(options), "notelify:articles:ratelimit:")
+}
+
+// newBlobStore keeps uploaded media in the bucket of MEDIA_STORE s3, or in
+// MEDIA_LOCAL_DIR otherwise.
+func newBlobStore(conf config.Config) ports.BlobStore {
+	var store ports.BlobStore
+	var err error
+	if conf.MEDIA_STORE == "s3" {
+		store, err = s3.NewS3Store(conf)
+	} else {
+		store, err = localfs.NewLocalStore(conf.MEDIA_LOCAL_DIR)
+	}
 	if err != nil {
 		panic(err)
 	}
+	return store
+}
+
+// mediaBaseURL is where the media routes serve uploads unless
+// MEDIA_BASE_URL points at a CDN or the bucket itself.
+func mediaBaseURL(conf config.Config) string {
+	if conf.MEDIA_BASE_URL != "" {
+		return conf.MEDIA_BASE_URL
+	}
+	return strings.TrimSuffix(conf.PUBLIC_BASE_URL, "/") + "/media"
+}
+
+// RunConfig handles "config print", which writes the effective
+// configuration as YAML with secrets redacted.
+func RunConfig(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: config print [flags]")
+		os.Exit(2)
+	}
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	flags.Parse(args[1:])
+
+	if err := loadConfig(loader).Print(os.Stdout); err != nil {
+		panic(err)
+	}
+}
+
+// loadConfig exits listing every invalid setting when the configuration
+// cannot be loaded.
+func loadConfig(loader *config.Loader) *config.Config {
+	conf, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return conf
+}
+
+func newArticleService(conf *config.Config) (*config.Config, ports.ArticleRepository, ports.ArticleService, ports.LoggingService) {
 	newLoggerService := services.NewLoggingManagementService(conf.LOGGER_URL)
 
-	databaseRepo, err := postgres.NewPostgresClient(*conf)
+	var databaseRepo ports.ArticleRepository
+	var err error
+	switch conf.ARTICLE_STORE {
+	case "dynamodb":
+		databaseRepo, err = dynamodb.NewDynamoDBClient(*conf)
+	case "sqlite":
+		databaseRepo, err = sqlite.NewSQLiteClient(*conf)
+	default:
+		databaseRepo, err = postgres.NewPostgresClient(*conf, newLoggerService)
+	}
 	if err != nil {
 		logEntry := domain.LogMessage{
 			LogLevel: "ERROR",
@@ -27,8 +273,6 @@ func RunService() {
 		panic(err)
 	}
 
-	articleService := services.NewArticleManagementService(databaseRepo, newLoggerService)
-	// Run HTTP Server
-	app.InitGinRoutes(articleService, newLoggerService, *conf)
-
+	articleService := services.NewArticleManagementService(databaseRepo, newLoggerService, markdown.NewMarkdownRenderer())
+	return conf, databaseRepo, articleService, newLoggerService
 }
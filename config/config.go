@@ -1,101 +1,293 @@
 package config
 
 import (
-	"os"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
+// Config is the effective configuration of the service. Every field is read
+// from, in increasing order of precedence, the defaults of the environment
+// named by ENV, an optional YAML file, environment variables and command
+// line flags. The YAML key of a field is its lowercase name, the environment
+// variable its name and the flag its lowercase name with dashes, so
+// SERVER_PORT is server_port, SERVER_PORT and --server-port. Fields tagged
+// secret are redacted when the configuration is printed.
 type Config struct {
-	ENV               string
-	SERVER_PORT       string
-	ARTICLE_TABLE     string
-	LOGGER_URL        string
-	SECRET_KEY        string
+	ENV         string
+	SERVER_PORT string
+	// GRPC_PORT serves the gRPC API next to the HTTP API
+	GRPC_PORT     string
+	ARTICLE_TABLE string
+	// ARTICLE_STORE is "postgres", "dynamodb" or "sqlite", the database
+	// holding ARTICLE_TABLE
+	ARTICLE_STORE string
+	LOGGER_URL    string
+	SECRET_KEY    string `secret:"true"`
+	// PUBLIC_BASE_URL is the address readers reach the service at, used for
+	// links in feeds and sitemaps. Links follow the request's host when it is
+	// empty.
+	PUBLIC_BASE_URL   string
 	POSTGRES_DB       string
 	POSTGRES_USER     string
 	POSTGRES_HOST     string
 	POSTGRES_PORT     string
-	POSTGRES_PASSWORD string
-	DEBUG             bool
-	TEST              bool
+	POSTGRES_PASSWORD string `secret:"true"`
+	// POSTGRES_SSLMODE is a libpq sslmode, verified against the CA
+	// certificates in POSTGRES_SSLROOTCERT when set
+	POSTGRES_SSLMODE     string
+	POSTGRES_SSLROOTCERT string
+	// POSTGRES_APPLICATION_NAME identifies the service in pg_stat_activity
+	POSTGRES_APPLICATION_NAME string
+	// POSTGRES_STATEMENT_TIMEOUT aborts statements running longer, 0 waits
+	// forever
+	POSTGRES_STATEMENT_TIMEOUT time.Duration
+	// POSTGRES_MAX_OPEN_CONNS and POSTGRES_MAX_IDLE_CONNS size the
+	// connection pool, 0 open connections meaning no limit
+	POSTGRES_MAX_OPEN_CONNS int
+	POSTGRES_MAX_IDLE_CONNS int
+	// POSTGRES_CONN_MAX_LIFETIME and POSTGRES_CONN_MAX_IDLE_TIME close
+	// connections that are older or were idle longer, 0 keeps them
+	POSTGRES_CONN_MAX_LIFETIME  time.Duration
+	POSTGRES_CONN_MAX_IDLE_TIME time.Duration
+	// POSTGRES_CONNECT_ATTEMPTS is how often connecting is tried at start
+	// up, waiting POSTGRES_CONNECT_BACKOFF after the first failure and twice
+	// as long after each further one
+	POSTGRES_CONNECT_ATTEMPTS int
+	POSTGRES_CONNECT_BACKOFF  time.Duration
+	// POSTGRES_REPLICAS are connection strings of read replicas. Article
+	// reads are spread over the replicas passing the health check made every
+	// POSTGRES_REPLICA_CHECK_INTERVAL, and go to the primary when none does.
+	POSTGRES_REPLICAS               []string `secret:"true"`
+	POSTGRES_REPLICA_CHECK_INTERVAL time.Duration
+	// POSTGRES_READ_AFTER_WRITE_WINDOW is how long reads of a written article
+	// stay on the primary, so writers see their change despite replica lag.
+	// Writes are tracked per process: behind a load balancer, a read served
+	// by another instance than the write may still hit a lagging replica.
+	POSTGRES_READ_AFTER_WRITE_WINDOW time.Duration
+	// DYNAMODB_REGION is the AWS region of the DynamoDB table, reached
+	// through DYNAMODB_ENDPOINT instead when set, such as for DynamoDB Local.
+	// Credentials come from the standard AWS environment variables, shared
+	// configuration or instance role.
+	DYNAMODB_REGION   string
+	DYNAMODB_ENDPOINT string
+	// SQLITE_PATH is the database file of the sqlite store, created when
+	// missing. ":memory:" keeps the articles in memory only.
+	SQLITE_PATH string
+	// MEDIA_STORE is "local" to keep uploaded media below MEDIA_LOCAL_DIR or
+	// "s3" to keep it in S3_BUCKET
+	MEDIA_STORE     string
+	MEDIA_LOCAL_DIR string
+	// MEDIA_BASE_URL is where readers load media from. When empty media is
+	// served by this service under PUBLIC_BASE_URL/media.
+	MEDIA_BASE_URL string
+	// MEDIA_MAX_SIZE is the largest upload accepted, in bytes, and
+	// MEDIA_ALLOWED_TYPES the content types accepted, as detected from the
+	// uploaded bytes
+	MEDIA_MAX_SIZE      int
+	MEDIA_ALLOWED_TYPES []string
+	// MEDIA_MAX_PIXELS is the largest width times height of an uploaded
+	// image. Small files can decode to huge images.
+	MEDIA_MAX_PIXELS int
+	// S3_BUCKET is the bucket of the s3 media store in S3_REGION, reached
+	// through S3_ENDPOINT instead when set. S3-compatible stores such as
+	// MinIO usually need S3_FORCE_PATH_STYLE.
+	S3_BUCKET           string
+	S3_REGION           string
+	S3_ENDPOINT         string
+	S3_FORCE_PATH_STYLE bool
+	DEBUG               bool
+	TEST                bool
+	// Cover images are resized to each of IMAGE_WIDTHS narrower than them
+	// and cropped to a square thumbnail of IMAGE_THUMBNAIL_SIZE, written in
+	// each of IMAGE_FORMATS. Only "jpeg" is supported, WebP uploads are
+	// read but WebP variants are not written.
+	IMAGE_WIDTHS         []int
+	IMAGE_THUMBNAIL_SIZE int
+	IMAGE_FORMATS        []string
+	IMAGE_JPEG_QUALITY   int
+	// IMAGE_WORKERS derive image variants in the background, with up to
+	// IMAGE_QUEUE_SIZE images waiting for them
+	IMAGE_WORKERS    int
+	IMAGE_QUEUE_SIZE int
+	// ALLOW_DELETE_ALL enables deleting every article at once. It defaults
+	// to on everywhere but production, where it must be explicitly enabled.
+	// TEST has no bearing on it.
+	ALLOW_DELETE_ALL bool
+	// TRASH_RETENTION is how long deleted articles stay restorable
+	TRASH_RETENTION time.Duration
+	// TRASH_PURGE_INTERVAL is how often expired articles are purged
+	TRASH_PURGE_INTERVAL time.Duration
+	// EVENTS_WEBHOOK_URL receives article domain events. Events stay in the
+	// outbox while it is empty.
+	EVENTS_WEBHOOK_URL string
+	// OUTBOX_POLL_INTERVAL is how often the outbox is checked for new events
+	OUTBOX_POLL_INTERVAL time.Duration
+	// OUTBOX_MAX_ATTEMPTS is how many times an event is published before
+	// it is dead and the later events of its article go ahead without it
+	OUTBOX_MAX_ATTEMPTS int
+	// OUTBOX_RETRY_BASE is the delay before an event is published again,
+	// doubled for every further attempt
+	OUTBOX_RETRY_BASE time.Duration
+	// WEBHOOK_MAX_ATTEMPTS is how many times a webhook delivery is tried
+	// before it moves to the dead letters
+	WEBHOOK_MAX_ATTEMPTS int
+	// WEBHOOK_RETRY_BASE is the delay before the first retry, doubled for
+	// every further attempt
+	WEBHOOK_RETRY_BASE time.Duration
+	// WEBHOOK_TIMEOUT bounds a single webhook request
+	WEBHOOK_TIMEOUT time.Duration
+	// WEBHOOK_POLL_INTERVAL is how often due webhook deliveries are sent
+	WEBHOOK_POLL_INTERVAL time.Duration
+	// RATE_LIMIT_READ requests per RATE_LIMIT_READ_WINDOW are allowed for
+	// each client on GET routes, and RATE_LIMIT_WRITE per
+	// RATE_LIMIT_WRITE_WINDOW on all others. A window of 0 turns the policy
+	// off.
+	RATE_LIMIT_READ         int
+	RATE_LIMIT_READ_WINDOW  time.Duration
+	RATE_LIMIT_WRITE        int
+	RATE_LIMIT_WRITE_WINDOW time.Duration
+	// RATE_LIMIT_STORE is "memory" for limits per instance or "redis" for
+	// limits shared through REDIS_URL
+	RATE_LIMIT_STORE string
+	REDIS_URL        string
+	// TRUSTED_PROXIES lists the IPs and CIDR ranges of the proxies whose
+	// X-Forwarded-For header names the client, which rate limits are keyed
+	// on. With none, the client is the address that connected.
+	TRUSTED_PROXIES []string
+	// CORS_ALLOW_ORIGINS lists the origins browsers may call the API from.
+	// Cross-origin requests are refused when it is empty.
+	CORS_ALLOW_ORIGINS     []string
+	CORS_ALLOW_METHODS     []string
+	CORS_ALLOW_HEADERS     []string
+	CORS_ALLOW_CREDENTIALS bool
+	// SECURITY_HEADERS adds HSTS, X-Content-Type-Options and, on HTML
+	// responses, CONTENT_SECURITY_POLICY
+	SECURITY_HEADERS        bool
+	HSTS_MAX_AGE            time.Duration
+	CONTENT_SECURITY_POLICY string
 }
 
-func NewConfig() (*Config, error) {
-	ENV := os.Getenv("ENV")
-	switch ENV {
-	case "development":
-		err := godotenv.Load(".env")
-		if err != nil {
-			return nil, err
-		}
-	}
+// environments are the values ENV may take
+var environments = []string{"production", "production_test", "development", "development_test", "docker", "docker_test"}
+
+// localOrigins are the origins of front ends run on a developer's machine
+var localOrigins = []string{"http://localhost:3000"}
 
-	var (
-		SECRET_KEY        = os.Getenv("SECRET_KEY")
-		POSTGRES_PASSWORD = os.Getenv("POSTGRES_PASSWORD")
-		POSTGRES_USER     = "postgres"
-		POSTGRES_DB       = "postgres"
-		POSTGRES_HOST     = "postgres"
-		POSTGRES_PORT     = "5432"
-		SERVER_PORT       = "8001"
-		ARTICLE_TABLE     = "Articles"
-		LOGGER_URL        = "http://localhost:8002/logger/v1/articles"
-		DEBUG             = false
-		TEST              = false
-	)
+// defaults returns the configuration of env before any file, environment
+// variable or flag is applied.
+func defaults(env string) Config {
+	conf := Config{
+		ENV:                              env,
+		ALLOW_DELETE_ALL:                 env != "production",
+		SERVER_PORT:                      "8001",
+		GRPC_PORT:                        "9001",
+		ARTICLE_TABLE:                    "Articles",
+		ARTICLE_STORE:                    "postgres",
+		LOGGER_URL:                       "http://localhost:8002/logger/v1/articles",
+		POSTGRES_DB:                      "postgres",
+		POSTGRES_USER:                    "postgres",
+		POSTGRES_HOST:                    "postgres",
+		POSTGRES_PORT:                    "5432",
+		POSTGRES_SSLMODE:                 "disable",
+		POSTGRES_APPLICATION_NAME:        "notelify-articles-service",
+		POSTGRES_STATEMENT_TIMEOUT:       30 * time.Second,
+		POSTGRES_MAX_OPEN_CONNS:          25,
+		POSTGRES_MAX_IDLE_CONNS:          5,
+		POSTGRES_CONN_MAX_LIFETIME:       30 * time.Minute,
+		POSTGRES_CONN_MAX_IDLE_TIME:      5 * time.Minute,
+		POSTGRES_CONNECT_ATTEMPTS:        10,
+		POSTGRES_CONNECT_BACKOFF:         time.Second,
+		POSTGRES_REPLICAS:                []string{},
+		POSTGRES_REPLICA_CHECK_INTERVAL:  10 * time.Second,
+		POSTGRES_READ_AFTER_WRITE_WINDOW: 5 * time.Second,
+		DYNAMODB_REGION:                  "us-east-1",
+		SQLITE_PATH:                      "notelify-articles.db",
+		MEDIA_STORE:                      "local",
+		MEDIA_LOCAL_DIR:                  "media",
+		MEDIA_MAX_SIZE:                   10 << 20,
+		MEDIA_ALLOWED_TYPES:              []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+		MEDIA_MAX_PIXELS:                 40000000,
+		S3_REGION:                        "us-east-1",
+		IMAGE_WIDTHS:                     []int{480, 960, 1600},
+		IMAGE_THUMBNAIL_SIZE:             320,
+		IMAGE_FORMATS:                    []string{"jpeg"},
+		IMAGE_JPEG_QUALITY:               82,
+		IMAGE_WORKERS:                    2,
+		IMAGE_QUEUE_SIZE:                 100,
+		TRASH_RETENTION:                  30 * 24 * time.Hour,
+		TRASH_PURGE_INTERVAL:             time.Hour,
+		OUTBOX_POLL_INTERVAL:             5 * time.Second,
+		OUTBOX_MAX_ATTEMPTS:              10,
+		OUTBOX_RETRY_BASE:                10 * time.Second,
+		WEBHOOK_MAX_ATTEMPTS:             8,
+		WEBHOOK_RETRY_BASE:               30 * time.Second,
+		WEBHOOK_TIMEOUT:                  10 * time.Second,
+		WEBHOOK_POLL_INTERVAL:            5 * time.Second,
+		RATE_LIMIT_READ:                  300,
+		RATE_LIMIT_READ_WINDOW:           time.Minute,
+		RATE_LIMIT_WRITE:                 30,
+		RATE_LIMIT_WRITE_WINDOW:          time.Minute,
+		RATE_LIMIT_STORE:                 "memory",
+		TRUSTED_PROXIES:                  []string{},
+		CORS_ALLOW_ORIGINS:               []string{},
+		CORS_ALLOW_METHODS:               []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		CORS_ALLOW_HEADERS:               []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Confirmation-Token", "X-Request-ID"},
+		CORS_ALLOW_CREDENTIALS:           true,
+		SECURITY_HEADERS:                 true,
+		HSTS_MAX_AGE:                     365 * 24 * time.Hour,
+		// The docs UI loads its scripts and styles from the release of
+		// swagger-ui-dist pinned in docs.html, and nothing else from unpkg
+		CONTENT_SECURITY_POLICY: "default-src 'self'; script-src 'self' https://unpkg.com/swagger-ui-dist@5.17.14/; style-src 'self' https://unpkg.com/swagger-ui-dist@5.17.14/; img-src 'self' data:; frame-ancestors 'none'; base-uri 'self'",
+	}
 
-	switch ENV {
+	switch env {
 	case "production":
-		TEST = false
-		DEBUG = false
+		conf.TEST = false
+		conf.DEBUG = false
 
 	case "production_test":
-		TEST = true
-		DEBUG = true
-		ARTICLE_TABLE = "PriductionTestArticles"
+		conf.TEST = true
+		conf.DEBUG = true
+		conf.ARTICLE_TABLE = "PriductionTestArticles"
 
 	case "development":
-		TEST = true
-		DEBUG = true
-		POSTGRES_HOST = "localhost"
-		ARTICLE_TABLE = "DevArticles"
+		conf.TEST = true
+		conf.DEBUG = true
+		conf.POSTGRES_HOST = "localhost"
+		conf.ARTICLE_TABLE = "DevArticles"
+		conf.CORS_ALLOW_ORIGINS = localOrigins
+		conf.SECURITY_HEADERS = false
 
 	case "development_test":
-		TEST = true
-		DEBUG = true
-		SECRET_KEY = "testsecret"
-		POSTGRES_PASSWORD = "pass1234"
-		POSTGRES_HOST = "localhost"
-		ARTICLE_TABLE = "TestArticles"
+		conf.TEST = true
+		conf.DEBUG = true
+		conf.SECRET_KEY = "testsecret"
+		conf.POSTGRES_PASSWORD = "pass1234"
+		conf.POSTGRES_HOST = "localhost"
+		conf.ARTICLE_TABLE = "TestArticles"
+		conf.CORS_ALLOW_ORIGINS = localOrigins
+		conf.SECURITY_HEADERS = false
 
 	case "docker":
-		TEST = true
-		DEBUG = true
-		ARTICLE_TABLE = "DockerArticles"
-		LOGGER_URL = "http://logger:8002/logger/v1/articles"
+		conf.TEST = true
+		conf.DEBUG = true
+		conf.ARTICLE_TABLE = "DockerArticles"
+		conf.LOGGER_URL = "http://logger:8002/logger/v1/articles"
+		conf.CORS_ALLOW_ORIGINS = localOrigins
+		conf.SECURITY_HEADERS = false
 
 	case "docker_test":
-		TEST = true
-		DEBUG = true
-		ARTICLE_TABLE = "DockerArticles"
-		LOGGER_URL = "http://logger:8002/logger/v1/articles"
-	}
-
-	config := Config{
-		ENV:               ENV,
-		SERVER_PORT:       SERVER_PORT,
-		ARTICLE_TABLE:     ARTICLE_TABLE,
-		SECRET_KEY:        SECRET_KEY,
-		LOGGER_URL:        LOGGER_URL,
-		DEBUG:             DEBUG,
-		TEST:              TEST,
-		POSTGRES_DB:       POSTGRES_DB,
-		POSTGRES_USER:     POSTGRES_USER,
-		POSTGRES_HOST:     POSTGRES_HOST,
-		POSTGRES_PORT:     POSTGRES_PORT,
-		POSTGRES_PASSWORD: POSTGRES_PASSWORD,
+		conf.TEST = true
+		conf.DEBUG = true
+		conf.ARTICLE_TABLE = "DockerArticles"
+		conf.LOGGER_URL = "http://logger:8002/logger/v1/articles"
+		conf.CORS_ALLOW_ORIGINS = localOrigins
+		conf.SECURITY_HEADERS = false
 	}
+	return conf
+}
 
-	return &config, nil
+// NewConfig loads and validates the configuration from the defaults, the
+// file named by CONFIG_FILE and environment variables.
+func NewConfig() (*Config, error) {
+	return NewLoader(nil).Load()
 }
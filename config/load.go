@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Loader reads the configuration layers. Flags registered by NewLoader take
+// effect once their flag set is parsed.
+type Loader struct {
+	file  string
+	flags map[string]string
+}
+
+// NewLoader registers a flag for every configuration field on flags, plus
+// --config naming the YAML file, which defaults to $CONFIG_FILE. flags may
+// be nil when only the file and environment should be read.
+func NewLoader(flags *flag.FlagSet) *Loader {
+	l := &Loader{file: os.Getenv("CONFIG_FILE"), flags: map[string]string{}}
+	if flags == nil {
+		return l
+	}
+	flags.StringVar(&l.file, "config", l.file, "YAML configuration file")
+	for _, field := range configFields() {
+		flags.Var(&fieldFlag{loader: l, field: field}, flagName(field.Name), "overrides "+field.Name)
+	}
+	return l
+}
+
+// Load applies the file, environment variables and flags on top of the
+// defaults of ENV and validates the result. The error lists every invalid
+// setting rather than only the first.
+func (l *Loader) Load() (*Config, error) {
+	file, err := readFile(l.file)
+	if err != nil {
+		return nil, err
+	}
+
+	// ENV picks the defaults, so it is resolved through the layers first
+	env := l.flags["ENV"]
+	if env == "" {
+		env = os.Getenv("ENV")
+	}
+	if env == "" && file["ENV"] != nil {
+		env = file["ENV"].Value
+	}
+	if env == "development" {
+		if err := godotenv.Load(".env"); err != nil {
+			return nil, err
+		}
+	}
+
+	conf := defaults(env)
+	value := reflect.ValueOf(&conf).Elem()
+	errs := []error{}
+	for _, field := range configFields() {
+		target := value.FieldByIndex(field.Index)
+		if node, ok := file[field.Name]; ok {
+			if err := setNode(target, node); err != nil {
+				errs = append(errs, fmt.Errorf("%s in %s: %v", field.Name, l.file, err))
+			}
+		}
+		if raw := os.Getenv(field.Name); raw != "" {
+			if err := setString(target, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s from the environment: %v", field.Name, err))
+			}
+		}
+		if raw, ok := l.flags[field.Name]; ok {
+			if err := setString(target, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s from --%s: %v", field.Name, flagName(field.Name), err))
+			}
+		}
+		delete(file, field.Name)
+	}
+	for key := range file {
+		errs = append(errs, fmt.Errorf("%s in %s: unknown setting", strings.ToLower(key), l.file))
+	}
+
+	if err := conf.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return &conf, nil
+}
+
+// readFile reads the YAML mapping in path, keyed by field name. A missing
+// path means there is no file layer.
+func readFile(path string) (map[string]*yaml.Node, error) {
+	values := map[string]*yaml.Node{}
+	if path == "" {
+		return values, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: expected a mapping of settings", path)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		values[strings.ToUpper(root.Content[i].Value)] = root.Content[i+1]
+	}
+	return values, nil
+}
+
+// configFields are the settable fields of Config, in declaration order
+func configFields() []reflect.StructField {
+	t := reflect.TypeOf(Config{})
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, t.Field(i))
+	}
+	return fields
+}
+
+func flagName(field string) string {
+	return strings.ReplaceAll(strings.ToLower(field), "_", "-")
+}
+
+// setString parses raw into target according to the field's type. Lists are
+// comma separated.
+func setString(target reflect.Value, raw string) error {
+	switch {
+	case target.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected a value such as 30s or 2h", raw)
+		}
+		target.SetInt(int64(d))
+	case target.Kind() == reflect.String:
+		target.SetString(raw)
+	case target.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", raw)
+		}
+		target.SetBool(b)
+	case target.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		target.SetInt(int64(n))
+	case target.Kind() == reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return setList(target, items)
+	default:
+		return fmt.Errorf("unsupported setting type %s", target.Type())
+	}
+	return nil
+}
+
+// setNode sets target from a YAML value. Lists may be written as YAML
+// sequences or comma separated strings.
+func setNode(target reflect.Value, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return setString(target, "")
+		}
+		return setString(target, node.Value)
+	case yaml.SequenceNode:
+		if target.Kind() != reflect.Slice {
+			return errors.New("expected a single value, not a list")
+		}
+		items := []string{}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return errors.New("expected a list of values")
+			}
+			items = append(items, item.Value)
+		}
+		return setList(target, items)
+	}
+	return errors.New("expected a value or a list of values")
+}
+
+// setList sets a list of strings or integers
+func setList(target reflect.Value, items []string) error {
+	list := reflect.MakeSlice(target.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setString(list.Index(i), item); err != nil {
+			return err
+		}
+	}
+	target.Set(list)
+	return nil
+}
+
+// fieldFlag records a command line override of one field. Values are parsed
+// by Load along with the other layers.
+type fieldFlag struct {
+	loader *Loader
+	field  reflect.StructField
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil || f.loader == nil {
+		return ""
+	}
+	return f.loader.flags[f.field.Name]
+}
+
+func (f *fieldFlag) Set(value string) error {
+	f.loader.flags[f.field.Name] = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be switched on with a bare --flag
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.Type.Kind() == reflect.Bool
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// Print writes the configuration as YAML, in the format read from
+// --config. Secrets and passwords in URLs are redacted.
+func (c Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	value := reflect.ValueOf(c)
+	for _, field := range configFields() {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: strings.ToLower(field.Name)},
+			printNode(field, value.FieldByIndex(field.Index)))
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func printNode(field reflect.StructField, value reflect.Value) *yaml.Node {
+	switch {
+	case value.Type() == durationType:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(value.Int()).String()}
+	case value.Kind() == reflect.Slice:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < value.Len(); i++ {
+			list.Content = append(list.Content, printNode(field, value.Index(i)))
+		}
+		return list
+	case value.Kind() == reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value.Bool())}
+	case value.Kind() == reflect.Int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(value.Int(), 10)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redact(field, value.String())}
+}
+
+// redact hides secret settings, or every secret item of a list, entirely
+// and the password of URLs such as REDIS_URL.
+func redact(field reflect.StructField, value string) string {
+	if value == "" {
+		return value
+	}
+	if field.Tag.Get("secret") == "true" {
+		return redacted
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+	}
+	return value
+}
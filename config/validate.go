@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tableName matches the identifiers ARTICLE_TABLE may hold, as it is used
+// to name tables in SQL statements
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type setting struct {
+	field string
+	value string
+}
+
+type durationSetting struct {
+	field string
+	value time.Duration
+}
+
+// Validate checks every setting and reports all the problems it finds.
+func (c Config) Validate() error {
+	errs := []error{}
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	known := false
+	for _, env := range environments {
+		known = known || c.ENV == env
+	}
+	if !known {
+		fail("ENV", "unknown environment %q, expected one of %s", c.ENV, strings.Join(environments, ", "))
+	}
+
+	required := []setting{{"SECRET_KEY", c.SECRET_KEY}}
+	ports := []setting{{"SERVER_PORT", c.SERVER_PORT}, {"GRPC_PORT", c.GRPC_PORT}}
+	switch c.ARTICLE_STORE {
+	case "postgres":
+		required = append(required,
+			setting{"POSTGRES_PASSWORD", c.POSTGRES_PASSWORD},
+			setting{"POSTGRES_DB", c.POSTGRES_DB},
+			setting{"POSTGRES_USER", c.POSTGRES_USER},
+			setting{"POSTGRES_HOST", c.POSTGRES_HOST},
+		)
+		ports = append(ports, setting{"POSTGRES_PORT", c.POSTGRES_PORT})
+	case "dynamodb":
+		required = append(required, setting{"DYNAMODB_REGION", c.DYNAMODB_REGION})
+		if err := checkURL(c.DYNAMODB_ENDPOINT, false); err != nil {
+			fail("DYNAMODB_ENDPOINT", "%v", err)
+		}
+	case "sqlite":
+		required = append(required, setting{"SQLITE_PATH", c.SQLITE_PATH})
+	default:
+		fail("ARTICLE_STORE", "invalid store %q, expected postgres, dynamodb or sqlite", c.ARTICLE_STORE)
+	}
+
+	switch c.MEDIA_STORE {
+	case "local":
+		required = append(required, setting{"MEDIA_LOCAL_DIR", c.MEDIA_LOCAL_DIR})
+	case "s3":
+		required = append(required, setting{"S3_BUCKET", c.S3_BUCKET}, setting{"S3_REGION", c.S3_REGION})
+		if err := checkURL(c.S3_ENDPOINT, false); err != nil {
+			fail("S3_ENDPOINT", "%v", err)
+		}
+	default:
+		fail("MEDIA_STORE", "invalid store %q, expected local or s3", c.MEDIA_STORE)
+	}
+
+	for _, setting := range required {
+		if setting.value == "" {
+			fail(setting.field, "missing")
+		}
+	}
+
+	for _, setting := range ports {
+		if n, err := strconv.Atoi(setting.value); err != nil || n < 1 || n > 65535 {
+			fail(setting.field, "invalid port %q", setting.value)
+		}
+	}
+
+	switch c.POSTGRES_SSLMODE {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		fail("POSTGRES_SSLMODE", "invalid sslmode %q, expected disable, allow, prefer, require, verify-ca or verify-full", c.POSTGRES_SSLMODE)
+	}
+	if c.POSTGRES_SSLROOTCERT != "" && c.POSTGRES_SSLMODE == "disable" {
+		fail("POSTGRES_SSLROOTCERT", "has no effect with POSTGRES_SSLMODE disable")
+	}
+	if c.POSTGRES_MAX_OPEN_CONNS < 0 {
+		fail("POSTGRES_MAX_OPEN_CONNS", "must not be negative")
+	}
+	if c.POSTGRES_MAX_IDLE_CONNS < 0 {
+		fail("POSTGRES_MAX_IDLE_CONNS", "must not be negative")
+	}
+	if c.POSTGRES_MAX_OPEN_CONNS > 0 && c.POSTGRES_MAX_IDLE_CONNS > c.POSTGRES_MAX_OPEN_CONNS {
+		fail("POSTGRES_MAX_IDLE_CONNS", "must not exceed POSTGRES_MAX_OPEN_CONNS")
+	}
+	if c.POSTGRES_CONNECT_ATTEMPTS < 1 {
+		fail("POSTGRES_CONNECT_ATTEMPTS", "must be at least 1")
+	}
+
+	if !tableName.MatchString(c.ARTICLE_TABLE) {
+		fail("ARTICLE_TABLE", "invalid table name %q, expected letters, digits and underscores", c.ARTICLE_TABLE)
+	}
+
+	if err := checkURL(c.LOGGER_URL, true); err != nil {
+		fail("LOGGER_URL", "%v", err)
+	}
+	if err := checkURL(c.PUBLIC_BASE_URL, false); err != nil {
+		fail("PUBLIC_BASE_URL", "%v", err)
+	}
+	if err := checkURL(c.EVENTS_WEBHOOK_URL, false); err != nil {
+		fail("EVENTS_WEBHOOK_URL", "%v", err)
+	}
+	if err := checkURL(c.MEDIA_BASE_URL, false); err != nil {
+		fail("MEDIA_BASE_URL", "%v", err)
+	}
+
+	for _, setting := range []durationSetting{
+		{"TRASH_RETENTION", c.TRASH_RETENTION},
+		{"TRASH_PURGE_INTERVAL", c.TRASH_PURGE_INTERVAL},
+		{"OUTBOX_POLL_INTERVAL", c.OUTBOX_POLL_INTERVAL},
+		{"OUTBOX_RETRY_BASE", c.OUTBOX_RETRY_BASE},
+		{"WEBHOOK_RETRY_BASE", c.WEBHOOK_RETRY_BASE},
+		{"WEBHOOK_TIMEOUT", c.WEBHOOK_TIMEOUT},
+		{"WEBHOOK_POLL_INTERVAL", c.WEBHOOK_POLL_INTERVAL},
+		{"POSTGRES_CONNECT_BACKOFF", c.POSTGRES_CONNECT_BACKOFF},
+		{"POSTGRES_REPLICA_CHECK_INTERVAL", c.POSTGRES_REPLICA_CHECK_INTERVAL},
+	} {
+		if setting.value <= 0 {
+			fail(setting.field, "must be a positive duration")
+		}
+	}
+	for _, setting := range []durationSetting{
+		{"RATE_LIMIT_READ_WINDOW", c.RATE_LIMIT_READ_WINDOW},
+		{"RATE_LIMIT_WRITE_WINDOW", c.RATE_LIMIT_WRITE_WINDOW},
+		{"HSTS_MAX_AGE", c.HSTS_MAX_AGE},
+		{"POSTGRES_STATEMENT_TIMEOUT", c.POSTGRES_STATEMENT_TIMEOUT},
+		{"POSTGRES_CONN_MAX_LIFETIME", c.POSTGRES_CONN_MAX_LIFETIME},
+		{"POSTGRES_CONN_MAX_IDLE_TIME", c.POSTGRES_CONN_MAX_IDLE_TIME},
+		{"POSTGRES_READ_AFTER_WRITE_WINDOW", c.POSTGRES_READ_AFTER_WRITE_WINDOW},
+	} {
+		if setting.value < 0 {
+			fail(setting.field, "must not be negative")
+		}
+	}
+
+	if c.OUTBOX_MAX_ATTEMPTS < 1 {
+		fail("OUTBOX_MAX_ATTEMPTS", "must be at least 1")
+	}
+	if c.WEBHOOK_MAX_ATTEMPTS < 1 {
+		fail("WEBHOOK_MAX_ATTEMPTS", "must be at least 1")
+	}
+	if c.MEDIA_MAX_SIZE < 1 {
+		fail("MEDIA_MAX_SIZE", "must be at least 1 byte")
+	}
+	if c.MEDIA_MAX_PIXELS < 1 {
+		fail("MEDIA_MAX_PIXELS", "must be at least 1 pixel")
+	}
+	if len(c.MEDIA_ALLOWED_TYPES) == 0 {
+		fail("MEDIA_ALLOWED_TYPES", "missing, no upload would be accepted")
+	}
+	for _, mediaType := range c.MEDIA_ALLOWED_TYPES {
+		if parsed, params, err := mime.ParseMediaType(mediaType); err != nil || len(params) > 0 || !strings.Contains(parsed, "/") {
+			fail("MEDIA_ALLOWED_TYPES", "invalid content type %q, expected type/subtype", mediaType)
+		}
+	}
+	for _, width := range c.IMAGE_WIDTHS {
+		if width < 1 || width > 16383 {
+			fail("IMAGE_WIDTHS", "invalid width %d, expected 1 to 16383 pixels", width)
+		}
+	}
+	if c.IMAGE_THUMBNAIL_SIZE < 1 || c.IMAGE_THUMBNAIL_SIZE > 16383 {
+		fail("IMAGE_THUMBNAIL_SIZE", "must be 1 to 16383 pixels")
+	}
+	if len(c.IMAGE_FORMATS) == 0 {
+		fail("IMAGE_FORMATS", "missing, no variant would be written")
+	}
+	for _, format := range c.IMAGE_FORMATS {
+		if format != "jpeg" {
+			fail("IMAGE_FORMATS", "invalid format %q, expected jpeg", format)
+		}
+	}
+	if c.IMAGE_JPEG_QUALITY < 1 || c.IMAGE_JPEG_QUALITY > 100 {
+		fail("IMAGE_JPEG_QUALITY", "must be 1 to 100")
+	}
+	if c.IMAGE_WORKERS < 1 {
+		fail("IMAGE_WORKERS", "must be at least 1")
+	}
+	if c.IMAGE_QUEUE_SIZE < 1 {
+		fail("IMAGE_QUEUE_SIZE", "must be at least 1")
+	}
+	if c.RATE_LIMIT_READ < 0 {
+		fail("RATE_LIMIT_READ", "must not be negative")
+	}
+	if c.RATE_LIMIT_WRITE < 0 {
+		fail("RATE_LIMIT_WRITE", "must not be negative")
+	}
+
+	switch c.RATE_LIMIT_STORE {
+	case "memory":
+	case "redis":
+		if c.REDIS_URL == "" {
+			fail("REDIS_URL", "missing, required by RATE_LIMIT_STORE redis")
+		}
+	default:
+		fail("RATE_LIMIT_STORE", "invalid store %q, expected memory or redis", c.RATE_LIMIT_STORE)
+	}
+
+	for _, proxy := range c.TRUSTED_PROXIES {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("TRUSTED_PROXIES", "%q is not an IP or CIDR range", proxy)
+			}
+		}
+	}
+
+	for _, origin := range c.CORS_ALLOW_ORIGINS {
+		// Browsers refuse credentials on responses allowing every origin
+		if origin == "*" && c.CORS_ALLOW_CREDENTIALS {
+			fail("CORS_ALLOW_ORIGINS", "* cannot be combined with CORS_ALLOW_CREDENTIALS")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkURL accepts absolute http and https URLs, and an empty value unless
+// the URL is required.
+func checkURL(raw string, required bool) error {
+	if raw == "" {
+		if required {
+			return errors.New("missing")
+		}
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q, expected an absolute http or https URL", raw)
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 package app
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
 	"github.com/gin-gonic/gin"
@@ -11,25 +19,33 @@ import (
 type GinHandler interface {
 	CreateArticle(ctx *gin.Context)
 	GetArticleByID(ctx *gin.Context)
+	GetArticleBySlug(ctx *gin.Context)
 	GetArticles(ctx *gin.Context)
 	GetArticlesByAuthor(ctx *gin.Context)
 	GetArticlesByTag(ctx *gin.Context)
+	SearchArticles(ctx *gin.Context)
 	UpdateArticle(ctx *gin.Context)
 	DeleteArticle(ctx *gin.Context)
 	DeleteArticleAll(ctx *gin.Context)
+	GetDeletedArticles(ctx *gin.Context)
+	RestoreArticle(ctx *gin.Context)
+	ExportArticles(ctx *gin.Context)
+	ImportArticles(ctx *gin.Context)
 }
 
 type handler struct {
-	svc       ports.ArticleService
-	secretKey string
-	logger    ports.LoggingService
+	svc           ports.ArticleService
+	secretKey     string
+	logger        ports.LoggingService
+	confirmations *auth.ConfirmationStore
 }
 
 func NewGinHandler(svc ports.ArticleService, secretKey string, logger ports.LoggingService) GinHandler {
 	routerHandler := handler{
-		svc:       svc,
-		secretKey: secretKey,
-		logger:    logger,
+		svc:           svc,
+		secretKey:     secretKey,
+		logger:        logger,
+		confirmations: auth.NewConfirmationStore(secretKey),
 	}
 	return routerHandler
 }
@@ -55,7 +71,20 @@ func (h handler) CreateArticle(ctx *gin.Context) {
 
 func (h handler) GetArticleByID(ctx *gin.Context) {
 	article_id := ctx.Param("article_id")
-	response, err := h.svc.GetArticleByID(article_id)
+
+	var response *domain.Article
+	var err error
+	switch ctx.DefaultQuery("format", "markdown") {
+	case "markdown":
+		response, err = h.svc.GetArticleByID(article_id)
+	case "html":
+		response, err = h.svc.GetArticleHTML(article_id)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be markdown or html",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
 			"error": err.Error(),
@@ -65,8 +94,38 @@ func (h handler) GetArticleByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+func (h handler) GetArticleBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+	response, err := h.svc.GetArticleBySlug(slug)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	// Slugs of renamed articles point at the current one
+	if response.Slug != slug {
+		ctx.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(ctx.Request.URL.Path), url.PathEscape(response.Slug)))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (h handler) GetArticles(ctx *gin.Context) {
-	response, err := h.svc.GetArticles()
+	query, err := parseArticleQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var response *[]domain.Article
+	if query == (domain.ArticleQuery{}) {
+		response, err = h.svc.GetArticles()
+	} else {
+		response, err = h.svc.FindArticles(query)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
 			"error": err.Error(),
@@ -100,6 +159,48 @@ func (h handler) GetArticlesByTag(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// Search results default to searchLimit articles, and at most maxSearchLimit
+// may be asked for
+const (
+	searchLimit    = 20
+	maxSearchLimit = 100
+)
+
+func (h handler) SearchArticles(ctx *gin.Context) {
+	text := strings.TrimSpace(ctx.Query("q"))
+	if text == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "missing search text q",
+		})
+		return
+	}
+	limit := searchLimit
+	if value := ctx.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit),
+			})
+			return
+		}
+	}
+	response, err := h.svc.SearchArticles(text, limit)
+	if errors.Is(err, domain.ErrSearchUnsupported) {
+		ctx.JSON(http.StatusNotImplemented, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (h handler) UpdateArticle(ctx *gin.Context) {
 	article_id := ctx.Param("article_id")
 
@@ -134,7 +235,35 @@ func (h handler) DeleteArticle(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Article deleted successfully"})
 }
 
+// DeleteArticleAll needs two calls by the same admin. The first returns a
+// short lived confirmation token, the second must send it back in the
+// X-Confirmation-Token header to actually delete the articles.
 func (h handler) DeleteArticleAll(ctx *gin.Context) {
+	subject := callerID(ctx)
+
+	token := ctx.GetHeader("X-Confirmation-Token")
+	if token == "" {
+		token, expiresAt, err := h.confirmations.Issue(subject)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"message":            "Repeat the request with the X-Confirmation-Token header to delete all articles",
+			"confirmation_token": token,
+			"expires_at":         expiresAt,
+		})
+		return
+	}
+	if !h.confirmations.Consume(token, subject) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "invalid or expired confirmation token",
+		})
+		return
+	}
+
 	err := h.svc.DeleteArticleAll()
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
@@ -145,3 +274,113 @@ func (h handler) DeleteArticleAll(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Article deleted successfully"})
 }
+
+func (h handler) GetDeletedArticles(ctx *gin.Context) {
+	response, err := h.svc.GetDeletedArticles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h handler) RestoreArticle(ctx *gin.Context) {
+	article_id := ctx.Param("article_id")
+	response, err := h.svc.RestoreArticle(article_id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h handler) ExportArticles(ctx *gin.Context) {
+	export := &exportWriter{ctx: ctx}
+	err := h.svc.ExportArticles(export)
+	if err != nil && !export.started {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		// Headers are already sent once streaming starts, so a failure part
+		// way through shows up as a truncated export
+		ctx.Error(err)
+		return
+	}
+	export.start()
+}
+
+// exportWriter sends the export headers ahead of the first article, so that
+// an export failing before it can still be answered with an error.
+type exportWriter struct {
+	ctx     *gin.Context
+	started bool
+}
+
+func (w *exportWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.ctx.Header("Content-Type", "application/x-ndjson")
+	w.ctx.Header("Content-Disposition", `attachment; filename="articles.ndjson"`)
+	w.ctx.Status(http.StatusOK)
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.ctx.Writer.Write(p)
+}
+
+func (h handler) ImportArticles(ctx *gin.Context) {
+	report, err := h.svc.ImportArticles(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"report": report,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// parseArticleQuery reads the article filters from the request query string.
+// Dates are accepted either as RFC 3339 timestamps or as plain YYYY-MM-DD
+// days, in which case "to" is inclusive of the whole day.
+func parseArticleQuery(ctx *gin.Context) (domain.ArticleQuery, error) {
+	query := domain.ArticleQuery{
+		AuthorID: ctx.Query("author_id"),
+		Tag:      ctx.Query("tag"),
+		Status:   ctx.Query("status"),
+		Sort:     ctx.Query("sort"),
+		Summary:  ctx.Query("view") == "summary",
+	}
+
+	var err error
+	if query.From, err = parseQueryDate(ctx.Query("from")); err != nil {
+		return query, fmt.Errorf("invalid from date: %v", err)
+	}
+	if query.To, err = parseQueryDate(ctx.Query("to")); err != nil {
+		return query, fmt.Errorf("invalid to date: %v", err)
+	}
+	if len(ctx.Query("to")) == len("2006-01-02") {
+		query.To = query.To.Add(24*time.Hour - time.Nanosecond)
+	}
+	return query, query.Validate()
+}
+
+func parseQueryDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
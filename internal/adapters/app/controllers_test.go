@@ -1 +1,299 @@
-package app
\ No newline at end of file
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestContext(target string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", target, nil)
+	return ctx
+}
+
+func TestParseArticleQuery(t *testing.T) {
+	t.Run("Test combined filters", func(t *testing.T) {
+		ctx := newTestContext("/articles/v1/?author_id=a1&tag=golang&from=2023-09-01&to=2023-09-30&status=published&sort=-updated_date")
+		query, err := parseArticleQuery(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query.AuthorID != "a1" || query.Tag != "golang" || query.Status != "published" {
+			t.Errorf("Unexpected query %+v", query)
+		}
+		if !query.From.Equal(time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected from 2023-09-01, got %s", query.From)
+		}
+		if query.To.Day() != 30 || query.To.Hour() != 23 {
+			t.Errorf("Expected to to cover 2023-09-30, got %s", query.To)
+		}
+		field, desc := query.SortField()
+		if field != "updated_date" || !desc {
+			t.Errorf("Expected descending updated_date sort, got %s %v", field, desc)
+		}
+	})
+
+	t.Run("Test invalid sort field", func(t *testing.T) {
+		ctx := newTestContext("/articles/v1/?sort=-body")
+		if _, err := parseArticleQuery(ctx); err == nil {
+			t.Error("Expected invalid sort field to be rejected")
+		}
+	})
+
+	t.Run("Test invalid date", func(t *testing.T) {
+		ctx := newTestContext("/articles/v1/?from=yesterday")
+		if _, err := parseArticleQuery(ctx); err == nil {
+			t.Error("Expected invalid date to be rejected")
+		}
+	})
+}
+
+// stubArticleService counts DeleteArticleAll calls, answers searches with
+// searchErr and exports the exported lines followed by exportErr. Methods the tests do not need are left to the embedded nil
+// interface.
+type stubArticleService struct {
+	ports.ArticleService
+	deleteAllCalls int
+	searchLimit    int
+	searchErr      error
+	exported       []string
+	exportErr      error
+}
+
+func (svc *stubArticleService) ExportArticles(w io.Writer) error {
+	for _, line := range svc.exported {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return svc.exportErr
+}
+
+func (svc *stubArticleService) DeleteArticleAll() error {
+	svc.deleteAllCalls++
+	return nil
+}
+
+func (svc *stubArticleService) SearchArticles(text string, limit int) (*[]domain.Article, error) {
+	svc.searchLimit = limit
+	if svc.searchErr != nil {
+		return nil, svc.searchErr
+	}
+	return &[]domain.Article{{ArticleID: "1", Title: text}}, nil
+}
+
+type stubLogger struct{}
+
+func (stubLogger) SendLog(domain.LogMessage)    {}
+func (stubLogger) LogDebug(domain.LogMessage)   {}
+func (stubLogger) LogInfo(domain.LogMessage)    {}
+func (stubLogger) LogWarning(domain.LogMessage) {}
+func (stubLogger) LogError(domain.LogMessage)   {}
+
+func signToken(t *testing.T, secretKey, subject, role string) string {
+	claims := auth.Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestDeleteArticleAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secretKey := "testsecret"
+
+	newRouter := func(enabled bool) (*gin.Engine, *stubArticleService) {
+		svc := &stubArticleService{}
+		handler := NewGinHandler(svc, secretKey, stubLogger{})
+		router := gin.New()
+		router.DELETE("/articles/v1/", requireEnabled(enabled), requireAdmin(secretKey), handler.DeleteArticleAll)
+		return router, svc
+	}
+	request := func(router *gin.Engine, token, confirmation string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/articles/v1/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if confirmation != "" {
+			req.Header.Set("X-Confirmation-Token", confirmation)
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("Test disabled environment", func(t *testing.T) {
+		router, svc := newRouter(false)
+		res := request(router, signToken(t, secretKey, "admin-1", auth.RoleAdmin), "")
+		if res.Code != http.StatusForbidden || svc.deleteAllCalls != 0 {
+			t.Errorf("Expected 403 without deleting, got %d", res.Code)
+		}
+	})
+
+	t.Run("Test admin role required", func(t *testing.T) {
+		router, _ := newRouter(true)
+		if res := request(router, "", ""); res.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without token, got %d", res.Code)
+		}
+		if res := request(router, signToken(t, secretKey, "author-1", ""), ""); res.Code != http.StatusForbidden {
+			t.Errorf("Expected 403 for non admin, got %d", res.Code)
+		}
+		if res := request(router, signToken(t, "othersecret", "admin-1", auth.RoleAdmin), ""); res.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for badly signed token, got %d", res.Code)
+		}
+	})
+
+	t.Run("Test two step confirmation", func(t *testing.T) {
+		router, svc := newRouter(true)
+		adminToken := signToken(t, secretKey, "admin-1", auth.RoleAdmin)
+
+		res := request(router, adminToken, "")
+		if res.Code != http.StatusAccepted || svc.deleteAllCalls != 0 {
+			t.Fatalf("Expected 202 without deleting, got %d", res.Code)
+		}
+		var body struct {
+			ConfirmationToken string `json:"confirmation_token"`
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil || body.ConfirmationToken == "" {
+			t.Fatalf("Expected a confirmation token, got %s", res.Body.String())
+		}
+
+		otherAdmin := signToken(t, secretKey, "admin-2", auth.RoleAdmin)
+		if res := request(router, otherAdmin, body.ConfirmationToken); res.Code != http.StatusForbidden {
+			t.Errorf("Expected token of another admin to be rejected, got %d", res.Code)
+		}
+
+		res = request(router, adminToken, body.ConfirmationToken)
+		if res.Code != http.StatusOK || svc.deleteAllCalls != 1 {
+			t.Errorf("Expected articles deleted on confirmation, got %d", res.Code)
+		}
+		if res := request(router, adminToken, body.ConfirmationToken); res.Code != http.StatusForbidden {
+			t.Errorf("Expected consumed token to be rejected, got %d", res.Code)
+		}
+	})
+
+	t.Run("Test confirmation on another instance", func(t *testing.T) {
+		first, _ := newRouter(true)
+		second, svc := newRouter(true)
+		adminToken := signToken(t, secretKey, "admin-1", auth.RoleAdmin)
+
+		var body struct {
+			ConfirmationToken string `json:"confirmation_token"`
+		}
+		json.Unmarshal(request(first, adminToken, "").Body.Bytes(), &body)
+		if res := request(second, adminToken, body.ConfirmationToken); res.Code != http.StatusOK || svc.deleteAllCalls != 1 {
+			t.Errorf("Expected a token issued by another instance to be accepted, got %d", res.Code)
+		}
+		if res := request(second, adminToken, body.ConfirmationToken+"0"); res.Code != http.StatusForbidden {
+			t.Errorf("Expected a tampered token to be rejected, got %d", res.Code)
+		}
+	})
+}
+
+func TestSearchArticles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	search := func(svc *stubArticleService, target string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/articles/v1/search", NewGinHandler(svc, "", stubLogger{}).SearchArticles)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+		return res
+	}
+
+	svc := &stubArticleService{}
+	if res := search(svc, "/articles/v1/search?q=go"); res.Code != http.StatusOK || svc.searchLimit != searchLimit {
+		t.Errorf("Expected 200 with the default limit, got %d and limit %d", res.Code, svc.searchLimit)
+	}
+	if res := search(svc, "/articles/v1/search?q=go&limit=5"); res.Code != http.StatusOK || svc.searchLimit != 5 {
+		t.Errorf("Expected limit 5, got %d", svc.searchLimit)
+	}
+	for _, target := range []string{"/articles/v1/search", "/articles/v1/search?q=%20", "/articles/v1/search?q=go&limit=0", "/articles/v1/search?q=go&limit=101", "/articles/v1/search?q=go&limit=ten"} {
+		if res := search(svc, target); res.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", target, res.Code)
+		}
+	}
+
+	unsupported := &stubArticleService{searchErr: domain.ErrSearchUnsupported}
+	if res := search(unsupported, "/articles/v1/search?q=go"); res.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without search support, got %d", res.Code)
+	}
+}
+
+func TestExportArticles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	export := func(svc *stubArticleService) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/articles/v1/export", NewGinHandler(svc, "", stubLogger{}).ExportArticles)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("GET", "/articles/v1/export", nil))
+		return res
+	}
+
+	res := export(&stubArticleService{exported: []string{`{"article_id":"1"}`, `{"article_id":"2"}`}})
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/x-ndjson" || strings.Count(res.Body.String(), "\n") != 2 {
+		t.Errorf("Expected 200 with both articles, got %d %q: %s", res.Code, res.Header().Get("Content-Type"), res.Body)
+	}
+	if res := export(&stubArticleService{}); res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/x-ndjson" || res.Body.Len() != 0 {
+		t.Errorf("Expected 200 with an empty export, got %d %q", res.Code, res.Header().Get("Content-Type"))
+	}
+	res = export(&stubArticleService{exportErr: errors.New("connection refused")})
+	if res.Code != http.StatusInternalServerError || !strings.Contains(res.Body.String(), "connection refused") {
+		t.Errorf("Expected 500 when the export fails before any article, got %d: %s", res.Code, res.Body)
+	}
+}
+
+func TestArticleAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secretKey := "testsecret"
+	router := NewRouter(authoredArticleService{}, nil, nil, nil, ratelimit.NewMemoryStore(), stubLogger{}, appConfig.Config{SECRET_KEY: secretKey})
+	request := func(method, target, token string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"title": "Renamed", "author_id": "author-2"}`))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	author := signToken(t, secretKey, "author-1", "")
+	other := signToken(t, secretKey, "author-2", "")
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		if code := request(method, "/articles/v1/1", ""); code != http.StatusUnauthorized {
+			t.Errorf("Expected %s without a token to get 401, got %d", method, code)
+		}
+		if code := request(method, "/articles/v1/1", other); code != http.StatusForbidden {
+			t.Errorf("Expected %s by another author to get 403, got %d", method, code)
+		}
+		if code := request(method, "/articles/v1/missing", author); code != http.StatusNotFound {
+			t.Errorf("Expected %s of a missing article to get 404, got %d", method, code)
+		}
+		if code := request(method, "/articles/v1/1", author); code != http.StatusOK {
+			t.Errorf("Expected %s by the author to get 200, got %d", method, code)
+		}
+	}
+}
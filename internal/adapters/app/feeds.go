@@ -0,0 +1,159 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/feed"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// feedSize is the number of most recently published articles in a feed
+const feedSize = 50
+
+type FeedHandler interface {
+	GetFeed(ctx *gin.Context)
+	GetAuthorFeed(ctx *gin.Context)
+	GetTagFeed(ctx *gin.Context)
+}
+
+type feedHandler struct {
+	svc ports.ArticleService
+	// baseURL is the public address of the service. Links are built from the
+	// request's host when it is empty.
+	baseURL string
+}
+
+func NewFeedHandler(svc ports.ArticleService, baseURL string) FeedHandler {
+	return feedHandler{svc: svc, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (h feedHandler) GetFeed(ctx *gin.Context) {
+	h.serveFeed(ctx, domain.ArticleQuery{}, "Notelify articles", "The latest articles on Notelify")
+}
+
+func (h feedHandler) GetAuthorFeed(ctx *gin.Context) {
+	author_id := ctx.Param("author_id")
+	h.serveFeed(ctx, domain.ArticleQuery{AuthorID: author_id}, "Notelify articles by "+author_id, "The latest articles by "+author_id)
+}
+
+func (h feedHandler) GetTagFeed(ctx *gin.Context) {
+	tag_name := ctx.Param("tag_name")
+	h.serveFeed(ctx, domain.ArticleQuery{Tag: tag_name}, "Notelify articles tagged "+tag_name, "The latest articles tagged "+tag_name)
+}
+
+// serveFeed writes the published articles matching query as Atom when the
+// format query parameter or the Accept header asks for it, and as RSS 2.0
+// otherwise. Clients sending If-None-Match get 304 Not Modified until an
+// article in the feed changes or leaves it. Last-Modified is only
+// informational: an article that is trashed or unpublished leaves the feed
+// without moving it.
+func (h feedHandler) serveFeed(ctx *gin.Context, query domain.ArticleQuery, title, description string) {
+	query.Status = domain.ArticleStatusPublished
+	query.Sort = "-publish_date"
+	query.Summary = true
+	query.Limit = feedSize
+	articles, err := h.svc.FindArticles(query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	baseURL := publicURL(ctx, h.baseURL)
+	self := baseURL + ctx.Request.URL.Path
+	f := feed.FromArticles(title, description, self, *articles, func(article domain.Article) string {
+		return articleLink(baseURL, article.ArticleID, article.Slug)
+	})
+
+	write, contentType := f.RSS, feed.RSSContentType
+	if wantsAtom(ctx) {
+		write, contentType = f.Atom, feed.AtomContentType
+	}
+
+	// The representation depends on Accept, caches must keep them apart
+	ctx.Header("Vary", "Accept")
+	etag := feedETag(contentType, self, *articles)
+	ctx.Header("ETag", etag)
+	if lastModified := f.LastModified().UTC().Truncate(time.Second); !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if matchesETag(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := write()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+// feedETag identifies a feed by its format, address and the ID and update
+// time of every item, so that it changes whenever an item is added, edited
+// or removed.
+func feedETag(contentType, self string, articles []domain.Article) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n", contentType, self)
+	for _, article := range articles {
+		fmt.Fprintf(hash, "%s %s\n", article.ArticleID, article.UpdatedDate.UTC().Format(time.RFC3339Nano))
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+// matchesETag reports whether the If-None-Match header lists etag
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func wantsAtom(ctx *gin.Context) bool {
+	switch ctx.Query("format") {
+	case "atom":
+		return true
+	case "rss":
+		return false
+	}
+	return strings.Contains(ctx.GetHeader("Accept"), "application/atom+xml")
+}
+
+// publicURL is baseURL, or the scheme and host the request was made to when
+// no base URL is configured.
+func publicURL(ctx *gin.Context, baseURL string) string {
+	if baseURL != "" {
+		return baseURL
+	}
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := ctx.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + ctx.Request.Host
+}
+
+// articleLink is the address of an article, preferring its slug
+func articleLink(baseURL, article_id, slug string) string {
+	if slug == "" {
+		return baseURL + "/articles/v1/" + url.PathEscape(article_id)
+	}
+	return baseURL + "/articles/v1/slug/" + url.PathEscape(slug)
+}
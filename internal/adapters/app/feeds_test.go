@@ -0,0 +1,97 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type feedArticleService struct {
+	ports.ArticleService
+	queries  []domain.ArticleQuery
+	articles []domain.Article
+}
+
+func (svc *feedArticleService) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	svc.queries = append(svc.queries, query)
+	articles := append([]domain.Article{}, svc.articles...)
+	return &articles, nil
+}
+
+func TestFeeds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &feedArticleService{articles: []domain.Article{{
+		ArticleID:   "a1",
+		Slug:        "first",
+		Title:       "First",
+		PublishDate: time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedDate: time.Date(2023, 9, 2, 10, 0, 0, 0, time.UTC),
+	}, {
+		ArticleID:   "a2",
+		Slug:        "second",
+		Title:       "Second",
+		PublishDate: time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedDate: time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC),
+	}}}
+	feeds := NewFeedHandler(svc, "https://notelify.example/")
+	router := gin.New()
+	router.GET("/articles/v1/tag/:tag_name/feed.xml", feeds.GetTagFeed)
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/articles/v1/tag/golang/feed.xml", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/rss+xml") {
+		t.Fatalf("Expected an RSS feed, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "<link>https://notelify.example/articles/v1/slug/first</link>") {
+		t.Errorf("Expected items to link to the public URL, got %s", rec.Body.String())
+	}
+	query := svc.queries[0]
+	if query.Tag != "golang" || query.Status != domain.ArticleStatusPublished || !query.Summary || query.Limit != feedSize {
+		t.Errorf("Unexpected query %+v", query)
+	}
+	lastModified := rec.Header().Get("Last-Modified")
+	if lastModified != "Sat, 02 Sep 2023 10:00:00 GMT" {
+		t.Errorf("Unexpected Last-Modified %q", lastModified)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected an ETag")
+	}
+
+	atom := serve("Accept", "application/atom+xml")
+	if !strings.HasPrefix(atom.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Errorf("Expected an Atom feed, got %s", atom.Header().Get("Content-Type"))
+	}
+	if atom.Header().Get("ETag") == etag {
+		t.Errorf("Expected the Atom feed to have its own ETag")
+	}
+	if rec := serve("If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", rec.Code)
+	}
+
+	// Trashing an older article leaves Last-Modified where it was, but the
+	// feed changed
+	svc.articles = svc.articles[:1]
+	rec = serve("If-None-Match", etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("Last-Modified") != lastModified {
+		t.Errorf("Expected 200 once an article left the feed, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected the ETag to change once an article left the feed")
+	}
+}
@@ -6,47 +6,153 @@ import (
 	"time"
 
 	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/gql"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func InitGinRoutes(svc ports.ArticleService, logger ports.LoggingService, conf appConfig.Config) {
+func InitGinRoutes(svc ports.ArticleService, webhookSvc ports.WebhookService, mediaSvc ports.MediaService, seriesSvc ports.SeriesService, limiter ratelimit.Store, logger ports.LoggingService, conf appConfig.Config) {
 	gin.SetMode(gin.DebugMode)
 
+	router := NewRouter(svc, webhookSvc, mediaSvc, seriesSvc, limiter, logger, conf)
+
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("Server running on port 0.0.0.0:%s", conf.SERVER_PORT),
+	}
+	logger.LogError(logEntry)
+
+	log.Printf("Server running on port 0.0.0.0:%s", conf.SERVER_PORT)
+	router.Run(fmt.Sprintf(":%s", conf.SERVER_PORT))
+}
+
+// NewRouter registers every route of the HTTP API. The OpenAPI document
+// served at /openapi.json must describe each of them.
+func NewRouter(svc ports.ArticleService, webhookSvc ports.WebhookService, mediaSvc ports.MediaService, seriesSvc ports.SeriesService, limiter ratelimit.Store, logger ports.LoggingService, conf appConfig.Config) *gin.Engine {
 	router := gin.Default()
+	// ClientIP only believes X-Forwarded-For when set by a trusted proxy
+	if err := router.SetTrustedProxies(conf.TRUSTED_PROXIES); err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  "invalid TRUSTED_PROXIES, no proxy is trusted: " + err.Error(),
+		}
+		logger.LogError(logEntry)
+		router.SetTrustedProxies(nil)
+	}
 	router.Use(ginRequestLogger(logger))
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	if conf.SECURITY_HEADERS {
+		router.Use(securityHeaders(conf.HSTS_MAX_AGE, conf.CONTENT_SECURITY_POLICY))
+	}
+	// Without allowed origins browsers keep to same-origin requests
+	if len(conf.CORS_ALLOW_ORIGINS) > 0 {
+		router.Use(cors.New(cors.Config{
+			AllowOrigins:     conf.CORS_ALLOW_ORIGINS,
+			AllowMethods:     conf.CORS_ALLOW_METHODS,
+			AllowHeaders:     conf.CORS_ALLOW_HEADERS,
+			ExposeHeaders:    corsExposeHeaders,
+			AllowCredentials: conf.CORS_ALLOW_CREDENTIALS,
+		}))
+	}
+	router.Use(rateLimit(limiter,
+		ratelimit.Policy{Limit: conf.RATE_LIMIT_READ, Window: conf.RATE_LIMIT_READ_WINDOW},
+		ratelimit.Policy{Limit: conf.RATE_LIMIT_WRITE, Window: conf.RATE_LIMIT_WRITE_WINDOW},
+		conf.SECRET_KEY, logger))
 
 	handler := NewGinHandler(svc, conf.SECRET_KEY, logger)
+	feeds := NewFeedHandler(svc, conf.PUBLIC_BASE_URL)
+	author := []gin.HandlerFunc{requireToken(conf.SECRET_KEY), requireArticleAuthor(svc)}
 
 	articleRoutes := router.Group("/articles/v1")
 	{
 		articleRoutes.POST("/", handler.CreateArticle)
 		articleRoutes.GET("/:article_id", handler.GetArticleByID)
+		articleRoutes.GET("/slug/:slug", handler.GetArticleBySlug)
 		articleRoutes.GET("/", handler.GetArticles)
+		articleRoutes.GET("/export", requireAdmin(conf.SECRET_KEY), handler.ExportArticles)
+		articleRoutes.POST("/import", requireAdmin(conf.SECRET_KEY), handler.ImportArticles)
 		articleRoutes.GET("/author/:author_id", handler.GetArticlesByAuthor)
 		articleRoutes.GET("/tag/:tag_name", handler.GetArticlesByTag)
-		articleRoutes.PUT("/:article_id", handler.UpdateArticle)
-		articleRoutes.DELETE("/:article_id", handler.DeleteArticle)
-		articleRoutes.DELETE("/", handler.DeleteArticleAll)
+		articleRoutes.GET("/search", handler.SearchArticles)
+		articleRoutes.PUT("/:article_id", append(author, handler.UpdateArticle)...)
+		articleRoutes.DELETE("/:article_id", append(author, handler.DeleteArticle)...)
+		articleRoutes.DELETE("/", requireEnabled(conf.ALLOW_DELETE_ALL), requireAdmin(conf.SECRET_KEY), handler.DeleteArticleAll)
+		articleRoutes.GET("/trash", requireAdmin(conf.SECRET_KEY), handler.GetDeletedArticles)
+		articleRoutes.POST("/:article_id/restore", requireAdmin(conf.SECRET_KEY), handler.RestoreArticle)
+		articleRoutes.GET("/feed.xml", feeds.GetFeed)
+		articleRoutes.GET("/author/:author_id/feed.xml", feeds.GetAuthorFeed)
+		articleRoutes.GET("/tag/:tag_name/feed.xml", feeds.GetTagFeed)
 	}
-	logEntry := domain.LogMessage{
-		LogLevel: "INFO",
-		Service:  "articles",
-		Message:  fmt.Sprintf("Server running on port 0.0.0.0:%s", conf.SERVER_PORT),
+
+	graphqlHandler := gql.NewGraphQLHandler(svc)
+	router.GET("/graphql", graphqlHandler.Handle)
+	router.POST("/graphql", graphqlHandler.Handle)
+
+	// Stores without webhook support leave the routes out
+	if webhookSvc != nil {
+		webhookHandler := NewWebhookHandler(webhookSvc)
+
+		webhookRoutes := router.Group("/articles/v1/webhooks", requireAdmin(conf.SECRET_KEY))
+		{
+			webhookRoutes.POST("/", webhookHandler.CreateSubscription)
+			webhookRoutes.GET("/", webhookHandler.GetSubscriptions)
+			webhookRoutes.GET("/dead-letters", webhookHandler.GetDeadDeliveries)
+			webhookRoutes.POST("/deliveries/:delivery_id/retry", webhookHandler.RetryDelivery)
+			webhookRoutes.GET("/:subscription_id", webhookHandler.GetSubscription)
+			webhookRoutes.PUT("/:subscription_id", webhookHandler.UpdateSubscription)
+			webhookRoutes.DELETE("/:subscription_id", webhookHandler.DeleteSubscription)
+			webhookRoutes.GET("/:subscription_id/deliveries", webhookHandler.GetDeliveries)
+		}
 	}
-	logger.LogError(logEntry)
 
-	log.Printf("Server running on port 0.0.0.0:%s", conf.SERVER_PORT)
-	router.Run(fmt.Sprintf(":%s", conf.SERVER_PORT))
+	// Stores without media support leave the routes out
+	if mediaSvc != nil {
+		mediaHandler := NewMediaHandler(mediaSvc, conf.MEDIA_MAX_SIZE)
+
+		articleRoutes.POST("/:article_id/media", append(author, mediaHandler.UploadMedia)...)
+		articleRoutes.GET("/:article_id/media", mediaHandler.GetArticleMedia)
+		articleRoutes.DELETE("/:article_id/media/:media_id", append(author, mediaHandler.DeleteMedia)...)
+		articleRoutes.PUT("/:article_id/cover", append(author, mediaHandler.SetCoverImage)...)
+		router.GET("/media/*key", mediaHandler.ServeMedia)
+	}
+
+	// Stores without series support leave the routes out
+	if seriesSvc != nil {
+		seriesHandler := NewSeriesHandler(seriesSvc)
+
+		seriesRoutes := router.Group("/articles/v1/series")
+		{
+			seriesRoutes.POST("/", requireToken(conf.SECRET_KEY), seriesHandler.CreateSeries)
+			seriesRoutes.GET("/", seriesHandler.GetSeriesList)
+			seriesRoutes.GET("/:series_id", seriesHandler.GetSeries)
+			seriesRoutes.PUT("/:series_id", requireToken(conf.SECRET_KEY), seriesHandler.UpdateSeries)
+			seriesRoutes.DELETE("/:series_id", requireToken(conf.SECRET_KEY), seriesHandler.DeleteSeries)
+		}
+	}
+
+	sitemaps := NewSitemapHandler(svc, conf.PUBLIC_BASE_URL, logger)
+	router.GET("/sitemap.xml", sitemaps.GetSitemap)
+	router.GET("/sitemap/:part", sitemaps.GetSitemapPart)
+
+	router.GET("/openapi.json", serveOpenAPI)
+	router.GET("/docs", serveDocs)
+	router.GET("/docs/docs.js", serveDocsScript)
+	return router
+}
+
+// corsExposeHeaders are the response headers browser clients may read
+var corsExposeHeaders = []string{
+	"Content-Length",
+	"Location",
+	"Retry-After",
+	"RateLimit-Policy",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
 }
 
 func ginRequestLogger(logger ports.LoggingService) gin.HandlerFunc {
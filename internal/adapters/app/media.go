@@ -0,0 +1,137 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the boundaries and headers around an upload
+const multipartOverhead = 1 << 20
+
+type MediaHandler interface {
+	UploadMedia(ctx *gin.Context)
+	GetArticleMedia(ctx *gin.Context)
+	DeleteMedia(ctx *gin.Context)
+	SetCoverImage(ctx *gin.Context)
+	ServeMedia(ctx *gin.Context)
+}
+
+type mediaHandler struct {
+	svc     ports.MediaService
+	maxSize int64
+}
+
+// NewMediaHandler reads uploads from the multipart field "file", refusing
+// request bodies much larger than maxSize before the service sees them.
+func NewMediaHandler(svc ports.MediaService, maxSize int) MediaHandler {
+	return mediaHandler{svc: svc, maxSize: int64(maxSize)}
+}
+
+func (h mediaHandler) UploadMedia(ctx *gin.Context) {
+	article_id := ctx.Param("article_id")
+	h.upload(ctx, func(filename string, content io.Reader) (interface{}, error) {
+		return h.svc.UploadMedia(article_id, filename, content)
+	})
+}
+
+func (h mediaHandler) SetCoverImage(ctx *gin.Context) {
+	article_id := ctx.Param("article_id")
+	h.upload(ctx, func(filename string, content io.Reader) (interface{}, error) {
+		return h.svc.SetCoverImage(article_id, filename, content)
+	})
+}
+
+// upload hands the file of a multipart request to store, answering with what
+// store returns
+func (h mediaHandler) upload(ctx *gin.Context, store func(filename string, content io.Reader) (interface{}, error)) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.maxSize+multipartOverhead)
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": domain.ErrMediaTooLarge.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	response, err := store(header.Filename, file)
+	if err != nil {
+		ctx.JSON(mediaErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (h mediaHandler) GetArticleMedia(ctx *gin.Context) {
+	article_id := ctx.Param("article_id")
+	response, err := h.svc.GetArticleMedia(article_id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h mediaHandler) DeleteMedia(ctx *gin.Context) {
+	article_id := ctx.Param("article_id")
+	media_id := ctx.Param("media_id")
+	if err := h.svc.DeleteMedia(article_id, media_id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Media deleted successfully",
+	})
+}
+
+func (h mediaHandler) ServeMedia(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	content, contentType, err := h.svc.OpenMedia(key)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, fs.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer content.Close()
+
+	// Keys are never reused, so media can be cached for good
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, content, nil)
+}
+
+func mediaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusNotFound
+	}
+}
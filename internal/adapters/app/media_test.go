@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// uploadMediaService accepts PNG signatures and refuses anything else
+type uploadMediaService struct {
+	ports.MediaService
+}
+
+func (uploadMediaService) UploadMedia(article_id string, filename string, content io.Reader) (*domain.Media, error) {
+	data, _ := io.ReadAll(content)
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		return nil, fmt.Errorf("%w text/plain", domain.ErrMediaType)
+	}
+	return &domain.Media{ArticleID: article_id, Filename: filename, Size: int64(len(data))}, nil
+}
+
+func TestUploadMedia(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/articles/v1/:article_id/media", NewMediaHandler(uploadMediaService{}, 16).UploadMedia)
+
+	upload := func(field string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile(field, "a.png")
+		part.Write(content)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/articles/v1/1/media", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := upload("file", []byte("\x89PNG")); rec.Code != http.StatusCreated {
+		t.Errorf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := upload("file", []byte("text")); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415, got %d", rec.Code)
+	}
+	if rec := upload("image", []byte("\x89PNG")); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a missing file field to be rejected, got %d", rec.Code)
+	}
+	// The body is cut off well before the service would read it
+	if rec := upload("file", make([]byte, 2*multipartOverhead)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413, got %d", rec.Code)
+	}
+}
+
+// authoredArticleService holds article 1 by author-1
+type authoredArticleService struct {
+	ports.ArticleService
+}
+
+func (authoredArticleService) GetArticleByID(article_id string) (*domain.Article, error) {
+	if article_id != "1" {
+		return nil, errors.New("article not found")
+	}
+	return &domain.Article{ArticleID: "1", AuthorID: "author-1"}, nil
+}
+
+func (authoredArticleService) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
+	return &domain.Article{ArticleID: article_id, AuthorID: "author-1", Title: article.Title}, nil
+}
+
+func (authoredArticleService) DeleteArticle(article_id string) error {
+	return nil
+}
+
+func TestUploadMediaAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secretKey := "testsecret"
+	router := gin.New()
+	router.POST("/articles/v1/:article_id/media", requireToken(secretKey), requireArticleAuthor(authoredArticleService{}), NewMediaHandler(uploadMediaService{}, 16).UploadMedia)
+
+	upload := func(article_id, token string) int {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "a.png")
+		part.Write([]byte("\x89PNG"))
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/articles/v1/"+article_id+"/media", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	author := signToken(t, secretKey, "author-1", "")
+	tests := []struct {
+		name       string
+		article_id string
+		token      string
+		code       int
+	}{
+		{"no token", "1", "", http.StatusUnauthorized},
+		{"other author", "1", signToken(t, secretKey, "author-2", ""), http.StatusForbidden},
+		{"missing article", "2", author, http.StatusNotFound},
+		{"author", "1", author, http.StatusCreated},
+	}
+	for _, test := range tests {
+		if code := upload(test.article_id, test.token); code != test.code {
+			t.Errorf("%s: expected %d, got %d", test.name, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// claimsKey is the gin context key holding the caller's verified token claims
+const claimsKey = "claims"
+
+// requireToken only lets requests carrying a valid token through, whatever
+// its role.
+func requireToken(secretKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := verifyToken(ctx, secretKey); !ok {
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// requireAdmin only lets requests carrying a valid token with the admin role
+// through.
+func requireAdmin(secretKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := verifyToken(ctx, secretKey)
+		if !ok {
+			return
+		}
+		if !claims.IsAdmin() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "admin role required",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// verifyToken stores the claims of the request's bearer token under
+// claimsKey. Requests without a valid token are aborted.
+func verifyToken(ctx *gin.Context, secretKey string) (*auth.Claims, bool) {
+	token, err := auth.BearerToken(ctx.GetHeader("Authorization"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	claims, err := auth.ParseToken(secretKey, token)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	ctx.Set(claimsKey, claims)
+	return claims, true
+}
+
+// requireArticleAuthor only lets the author of the article named by the
+// article_id parameter through. It follows requireToken.
+func requireArticleAuthor(svc ports.ArticleService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		article, err := svc.GetArticleByID(ctx.Param("article_id"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if article.AuthorID != callerID(ctx) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "article can only be changed by its author",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// callerID returns the author ID of the caller verified by requireToken or
+// requireAdmin.
+func callerID(ctx *gin.Context) string {
+	if claims, ok := ctx.Get(claimsKey); ok {
+		return claims.(*auth.Claims).Subject
+	}
+	return ""
+}
+
+// requireEnabled rejects every request when enabled is false, for routes
+// that are switched off by configuration.
+func requireEnabled(enabled bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !enabled {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "operation disabled in this environment",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// securityHeaders sets HSTS and X-Content-Type-Options on every response and
+// the content security policy on HTML responses. The content type is only
+// known once the handler writes, so the policy is added then.
+func securityHeaders(hstsMaxAge time.Duration, contentSecurityPolicy string) gin.HandlerFunc {
+	hsts := fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds()))
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		if hstsMaxAge > 0 {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		header.Set("X-Content-Type-Options", "nosniff")
+		ctx.Writer = &cspWriter{ResponseWriter: ctx.Writer, policy: contentSecurityPolicy}
+		ctx.Next()
+	}
+}
+
+// cspWriter adds a Content-Security-Policy header to HTML responses right
+// before the headers are sent.
+type cspWriter struct {
+	gin.ResponseWriter
+	policy string
+}
+
+func (w *cspWriter) setPolicy() {
+	if w.Written() || w.policy == "" {
+		return
+	}
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		w.Header().Set("Content-Security-Policy", w.policy)
+	}
+}
+
+func (w *cspWriter) WriteHeaderNow() {
+	w.setPolicy()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cspWriter) Write(data []byte) (int, error) {
+	w.setPolicy()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cspWriter) WriteString(s string) (int, error) {
+	w.setPolicy()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(&stubArticleService{}, nil, nil, nil, ratelimit.NewMemoryStore(), stubLogger{}, appConfig.Config{
+		SECURITY_HEADERS:        true,
+		HSTS_MAX_AGE:            24 * time.Hour,
+		CONTENT_SECURITY_POLICY: "default-src 'self'",
+	})
+	get := func(path string) http.Header {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Header()
+	}
+
+	html := get("/docs")
+	if html.Get("Strict-Transport-Security") != "max-age=86400; includeSubDomains" || html.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Unexpected headers %v", html)
+	}
+	if html.Get("Content-Security-Policy") != "default-src 'self'" {
+		t.Errorf("Expected a content security policy on HTML, got %v", html)
+	}
+	if json := get("/openapi.json"); json.Get("Content-Security-Policy") != "" || json.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected no content security policy on JSON, got %v", json)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(&stubArticleService{}, nil, nil, nil, ratelimit.NewMemoryStore(), stubLogger{}, appConfig.Config{
+		CORS_ALLOW_ORIGINS:     []string{"https://notelify.example"},
+		CORS_ALLOW_METHODS:     []string{"GET", "PATCH", "OPTIONS"},
+		CORS_ALLOW_HEADERS:     []string{"Authorization"},
+		CORS_ALLOW_CREDENTIALS: true,
+	})
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/articles/v1/", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "PATCH")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("https://notelify.example")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://notelify.example" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("Expected the configured origin to be allowed, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := preflight("https://evil.example"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected other origins to be refused, got %v", rec.Header())
+	}
+}
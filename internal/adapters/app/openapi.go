@@ -0,0 +1,848 @@
+package app
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// docsPage loads Swagger UI from unpkg at an exact swagger-ui-dist
+	// release, which CONTENT_SECURITY_POLICY allows by path. The tags carry
+	// no integrity hashes yet: they must be computed from the released files
+	// when the version is changed, and the files were not at hand when it was
+	// pinned.
+	//
+	//go:embed docs.html
+	docsPage []byte
+	//go:embed docs.js
+	docsScript []byte
+)
+
+type paramDoc struct {
+	Name        string
+	Description string
+	Enum        []string
+}
+
+type responseDoc struct {
+	Description string
+	// Schema names a component, "[]Name" for a list of them, or is empty
+	// for responses without a body
+	Schema      string
+	ContentType string
+}
+
+type operationDoc struct {
+	OperationID string
+	Summary     string
+	Tag         string
+	Query       []paramDoc
+	Headers     []paramDoc
+	Body        string
+	BodyType    string
+	Responses   map[int]responseDoc
+	// Admin marks operations that need a bearer token with the admin role
+	Admin bool
+	// Author marks operations that need the bearer token of the author of
+	// what they change
+	Author bool
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type confirmationResponse struct {
+	Message           string    `json:"message"`
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type mediaUpload struct {
+	File []byte `json:"file"`
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// schemaTypes are the types published under components/schemas. Fields of
+// these types refer to each other by name.
+var schemaTypes = map[string]reflect.Type{
+	"Article":              reflect.TypeOf(domain.Article{}),
+	"Author":               reflect.TypeOf(domain.Author{}),
+	"ImportReport":         reflect.TypeOf(domain.ImportReport{}),
+	"ImportError":          reflect.TypeOf(domain.ImportError{}),
+	"WebhookSubscription":  reflect.TypeOf(domain.WebhookSubscription{}),
+	"WebhookDelivery":      reflect.TypeOf(domain.WebhookDelivery{}),
+	"WebhookAttempt":       reflect.TypeOf(domain.WebhookAttempt{}),
+	"Event":                reflect.TypeOf(domain.Event{}),
+	"Media":                reflect.TypeOf(domain.Media{}),
+	"ImageVariant":         reflect.TypeOf(domain.ImageVariant{}),
+	"Series":               reflect.TypeOf(domain.Series{}),
+	"SeriesNavigation":     reflect.TypeOf(domain.SeriesNavigation{}),
+	"ArticleLink":          reflect.TypeOf(domain.ArticleLink{}),
+	"MediaUpload":          reflect.TypeOf(mediaUpload{}),
+	"Error":                reflect.TypeOf(errorResponse{}),
+	"Message":              reflect.TypeOf(messageResponse{}),
+	"ConfirmationRequired": reflect.TypeOf(confirmationResponse{}),
+	"GraphQLRequest":       reflect.TypeOf(graphQLRequest{}),
+}
+
+var (
+	articleIDErrors = map[int]responseDoc{
+		http.StatusNotFound: {Description: "Article not found", Schema: "Error"},
+	}
+	articleList = responseDoc{Description: "Articles", Schema: "[]Article"}
+)
+
+func mediaUploadResponses(description string, schema string) map[int]responseDoc {
+	return map[int]responseDoc{
+		http.StatusCreated:               {Description: description, Schema: schema},
+		http.StatusBadRequest:            {Description: "No file in the request", Schema: "Error"},
+		http.StatusUnauthorized:          {Description: "Missing or invalid token", Schema: "Error"},
+		http.StatusForbidden:             {Description: "Not the author of the article", Schema: "Error"},
+		http.StatusNotFound:              {Description: "Article not found", Schema: "Error"},
+		http.StatusRequestEntityTooLarge: {Description: "File larger than MEDIA_MAX_SIZE, or image over MEDIA_MAX_PIXELS", Schema: "Error"},
+		http.StatusUnsupportedMediaType:  {Description: "File not of MEDIA_ALLOWED_TYPES", Schema: "Error"},
+	}
+}
+
+// routeDocs documents every route registered by NewRouter, keyed by method
+// and gin path.
+var routeDocs = map[string]operationDoc{
+	"POST /articles/v1/": {
+		OperationID: "createArticle",
+		Summary:     "Create an article",
+		Tag:         "articles",
+		Body:        "Article",
+		Responses: map[int]responseDoc{
+			http.StatusCreated:             {Description: "Created article", Schema: "Article"},
+			http.StatusBadRequest:          {Description: "Invalid article", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Article could not be stored", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/": {
+		OperationID: "listArticles",
+		Summary:     "List articles, optionally filtered and sorted",
+		Tag:         "articles",
+		Query: []paramDoc{
+			{Name: "author_id", Description: "Only articles by this author"},
+			{Name: "tag", Description: "Only articles with this tag, case-insensitive"},
+			{Name: "from", Description: "Published at or after, RFC 3339 or YYYY-MM-DD"},
+			{Name: "to", Description: "Published at or before, RFC 3339 or YYYY-MM-DD for the whole day"},
+			{Name: "status", Description: "Publication status", Enum: []string{domain.ArticleStatusPublished, domain.ArticleStatusScheduled}},
+			{Name: "sort", Description: "Sort field, prefixed with - for descending order", Enum: []string{"publish_date", "-publish_date", "updated_date", "-updated_date", "title", "-title"}},
+			{Name: "view", Description: "summary leaves out article bodies", Enum: []string{"summary"}},
+		},
+		Responses: map[int]responseDoc{
+			http.StatusOK:         articleList,
+			http.StatusBadRequest: {Description: "Invalid filter", Schema: "Error"},
+			http.StatusNotFound:   {Description: "Articles could not be loaded", Schema: "Error"},
+		},
+	},
+	"DELETE /articles/v1/": {
+		OperationID: "deleteAllArticles",
+		Summary:     "Move every article to the trash",
+		Tag:         "articles",
+		Admin:       true,
+		Headers: []paramDoc{
+			{Name: "X-Confirmation-Token", Description: "Token returned by a first call without it"},
+		},
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Articles deleted", Schema: "Message"},
+			http.StatusAccepted:            {Description: "Repeat the call with the confirmation token", Schema: "ConfirmationRequired"},
+			http.StatusUnauthorized:        {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:           {Description: "Not an admin, disabled, or invalid confirmation token", Schema: "Error"},
+			http.StatusNotFound:            {Description: "No articles to delete", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Confirmation token could not be issued", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/:article_id": {
+		OperationID: "getArticle",
+		Summary:     "Get an article, with the previous and next articles of its series",
+		Tag:         "articles",
+		Query: []paramDoc{
+			{Name: "format", Description: "html adds the rendered body as body_html", Enum: []string{"markdown", "html"}},
+		},
+		Responses: merge(articleIDErrors, map[int]responseDoc{
+			http.StatusOK:         {Description: "Article", Schema: "Article"},
+			http.StatusBadRequest: {Description: "Unknown format", Schema: "Error"},
+		}),
+	},
+	"PUT /articles/v1/:article_id": {
+		OperationID: "updateArticle",
+		Summary:     "Replace an article, keeping its author",
+		Tag:         "articles",
+		Author:      true,
+		Body:        "Article",
+		Responses: merge(articleIDErrors, map[int]responseDoc{
+			http.StatusOK:           {Description: "Updated article", Schema: "Article"},
+			http.StatusBadRequest:   {Description: "Invalid article", Schema: "Error"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not the author of the article", Schema: "Error"},
+		}),
+	},
+	"DELETE /articles/v1/:article_id": {
+		OperationID: "deleteArticle",
+		Summary:     "Move an article to the trash",
+		Tag:         "articles",
+		Author:      true,
+		Responses: merge(articleIDErrors, map[int]responseDoc{
+			http.StatusOK:           {Description: "Article deleted", Schema: "Message"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not the author of the article", Schema: "Error"},
+		}),
+	},
+	"POST /articles/v1/:article_id/restore": {
+		OperationID: "restoreArticle",
+		Summary:     "Restore an article from the trash",
+		Tag:         "articles",
+		Admin:       true,
+		Responses: merge(articleIDErrors, map[int]responseDoc{
+			http.StatusOK:           {Description: "Restored article", Schema: "Article"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not an admin", Schema: "Error"},
+		}),
+	},
+	"GET /articles/v1/slug/:slug": {
+		OperationID: "getArticleBySlug",
+		Summary:     "Get an article by slug",
+		Tag:         "articles",
+		Responses: map[int]responseDoc{
+			http.StatusOK:               {Description: "Article", Schema: "Article"},
+			http.StatusMovedPermanently: {Description: "The slug was renamed, Location holds the current one"},
+			http.StatusNotFound:         {Description: "Article not found", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/author/:author_id": {
+		OperationID: "listArticlesByAuthor",
+		Summary:     "List the articles of an author",
+		Tag:         "articles",
+		Responses: map[int]responseDoc{
+			http.StatusOK:       articleList,
+			http.StatusNotFound: {Description: "Articles could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/tag/:tag_name": {
+		OperationID: "listArticlesByTag",
+		Summary:     "List the articles with a tag",
+		Tag:         "articles",
+		Responses: map[int]responseDoc{
+			http.StatusOK:       articleList,
+			http.StatusNotFound: {Description: "Articles could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/search": {
+		OperationID: "searchArticles",
+		Summary:     "Search the text and tags of the articles",
+		Tag:         "articles",
+		Query: []paramDoc{
+			{Name: "q", Description: "Words every result contains"},
+			{Name: "limit", Description: "Most articles returned, 1 to 100, 20 by default"},
+		},
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Articles, best matches first", Schema: "[]Article"},
+			http.StatusBadRequest:          {Description: "Missing search text or invalid limit", Schema: "Error"},
+			http.StatusNotImplemented:      {Description: "The article store offers no search", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Articles could not be searched", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/trash": {
+		OperationID: "listDeletedArticles",
+		Summary:     "List the articles in the trash",
+		Tag:         "articles",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  articleList,
+			http.StatusUnauthorized:        {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:           {Description: "Not an admin", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Articles could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/export": {
+		OperationID: "exportArticles",
+		Summary:     "Export every article as newline delimited JSON",
+		Tag:         "transfer",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "One article per line", Schema: "Article", ContentType: "application/x-ndjson"},
+			http.StatusUnauthorized:        {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:           {Description: "Not an admin", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Articles could not be read", Schema: "Error"},
+		},
+	},
+	"POST /articles/v1/import": {
+		OperationID: "importArticles",
+		Summary:     "Upsert articles from newline delimited JSON",
+		Tag:         "transfer",
+		Admin:       true,
+		Body:        "Article",
+		BodyType:    "application/x-ndjson",
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Import report", Schema: "ImportReport"},
+			http.StatusUnauthorized:        {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:           {Description: "Not an admin", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Import aborted, with the report so far", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/feed.xml": {
+		OperationID: "getFeed",
+		Summary:     "Feed of the latest published articles",
+		Tag:         "feeds",
+		Query:       feedQuery,
+		Headers:     feedHeaders,
+		Responses:   feedResponses,
+	},
+	"GET /articles/v1/author/:author_id/feed.xml": {
+		OperationID: "getAuthorFeed",
+		Summary:     "Feed of the latest published articles of an author",
+		Tag:         "feeds",
+		Query:       feedQuery,
+		Headers:     feedHeaders,
+		Responses:   feedResponses,
+	},
+	"GET /articles/v1/tag/:tag_name/feed.xml": {
+		OperationID: "getTagFeed",
+		Summary:     "Feed of the latest published articles with a tag",
+		Tag:         "feeds",
+		Query:       feedQuery,
+		Headers:     feedHeaders,
+		Responses:   feedResponses,
+	},
+	"POST /articles/v1/:article_id/media": {
+		OperationID: "uploadMedia",
+		Summary:     "Upload an image for an article as the multipart field file",
+		Tag:         "media",
+		Author:      true,
+		Body:        "MediaUpload",
+		BodyType:    "multipart/form-data",
+		Responses:   mediaUploadResponses("Uploaded media", "Media"),
+	},
+	"GET /articles/v1/:article_id/media": {
+		OperationID: "listArticleMedia",
+		Summary:     "List the media of an article, oldest first",
+		Tag:         "media",
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Media", Schema: "[]Media"},
+			http.StatusInternalServerError: {Description: "Media could not be loaded", Schema: "Error"},
+		},
+	},
+	"DELETE /articles/v1/:article_id/media/:media_id": {
+		OperationID: "deleteMedia",
+		Summary:     "Delete media of an article, clearing the cover image it was used for",
+		Tag:         "media",
+		Author:      true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:           {Description: "Media deleted", Schema: "Message"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not the author of the article", Schema: "Error"},
+			http.StatusNotFound:     {Description: "Article or media not found", Schema: "Error"},
+		},
+	},
+	"PUT /articles/v1/:article_id/cover": {
+		OperationID: "setCoverImage",
+		Summary:     "Upload an image as the multipart field file and make it the cover image of an article",
+		Tag:         "media",
+		Author:      true,
+		Body:        "MediaUpload",
+		BodyType:    "multipart/form-data",
+		Responses:   mediaUploadResponses("Article with the new cover image", "Article"),
+	},
+	"GET /media/*key": {
+		OperationID: "getMedia",
+		Summary:     "Content of uploaded media, at the URL recorded with it",
+		Tag:         "media",
+		Responses: map[int]responseDoc{
+			http.StatusOK:       {Description: "Media content", ContentType: "application/octet-stream"},
+			http.StatusNotFound: {Description: "Media not found", Schema: "Error"},
+		},
+	},
+	"GET /sitemap.xml": {
+		OperationID: "getSitemap",
+		Summary:     "Sitemap of every published article, or an index of sitemap parts above 50000 articles",
+		Tag:         "feeds",
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Sitemap or sitemap index", ContentType: "application/xml"},
+			http.StatusInternalServerError: {Description: "Articles could not be counted", Schema: "Error"},
+		},
+	},
+	"GET /sitemap/:part": {
+		OperationID: "getSitemapPart",
+		Summary:     "One part of a split sitemap, such as 1.xml",
+		Tag:         "feeds",
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Sitemap", ContentType: "application/xml"},
+			http.StatusNotFound:            {Description: "No such part", Schema: "Error"},
+			http.StatusInternalServerError: {Description: "Articles could not be counted", Schema: "Error"},
+		},
+	},
+	"GET /graphql": {
+		OperationID: "graphqlQuery",
+		Summary:     "Run a GraphQL query passed as query parameters",
+		Tag:         "graphql",
+		Query: []paramDoc{
+			{Name: "query", Description: "GraphQL query"},
+			{Name: "operationName", Description: "Operation to run"},
+			{Name: "variables", Description: "JSON encoded variables"},
+		},
+		Responses: graphQLResponses,
+	},
+	"POST /graphql": {
+		OperationID: "graphqlPost",
+		Summary:     "Run a GraphQL query",
+		Tag:         "graphql",
+		Body:        "GraphQLRequest",
+		Responses:   graphQLResponses,
+	},
+	"POST /articles/v1/series/": {
+		OperationID: "createSeries",
+		Summary:     "Create a series of articles by its author, in reading order",
+		Tag:         "series",
+		Author:      true,
+		Body:        "Series",
+		Responses: map[int]responseDoc{
+			http.StatusCreated:      {Description: "Created series", Schema: "Series"},
+			http.StatusBadRequest:   {Description: "Invalid series, or an article belongs to another series", Schema: "Error"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "author_id is not the caller", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/series/": {
+		OperationID: "listSeries",
+		Summary:     "List series, oldest first",
+		Tag:         "series",
+		Query: []paramDoc{
+			{Name: "author_id", Description: "Only the series of this author"},
+		},
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Series", Schema: "[]Series"},
+			http.StatusInternalServerError: {Description: "Series could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/series/:series_id": {
+		OperationID: "getSeries",
+		Summary:     "Get a series",
+		Tag:         "series",
+		Responses: map[int]responseDoc{
+			http.StatusOK:       {Description: "Series", Schema: "Series"},
+			http.StatusNotFound: {Description: "Series not found", Schema: "Error"},
+		},
+	},
+	"PUT /articles/v1/series/:series_id": {
+		OperationID: "updateSeries",
+		Summary:     "Replace the title, description and articles of a series",
+		Tag:         "series",
+		Author:      true,
+		Body:        "Series",
+		Responses: map[int]responseDoc{
+			http.StatusOK:           {Description: "Updated series", Schema: "Series"},
+			http.StatusBadRequest:   {Description: "Invalid series", Schema: "Error"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not the author of the series", Schema: "Error"},
+			http.StatusNotFound:     {Description: "Series not found", Schema: "Error"},
+		},
+	},
+	"DELETE /articles/v1/series/:series_id": {
+		OperationID: "deleteSeries",
+		Summary:     "Delete a series, keeping its articles",
+		Tag:         "series",
+		Author:      true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:           {Description: "Series deleted", Schema: "Message"},
+			http.StatusUnauthorized: {Description: "Missing or invalid token", Schema: "Error"},
+			http.StatusForbidden:    {Description: "Not the author of the series", Schema: "Error"},
+			http.StatusNotFound:     {Description: "Series not found", Schema: "Error"},
+		},
+	},
+	"POST /articles/v1/webhooks/": {
+		OperationID: "createWebhookSubscription",
+		Summary:     "Subscribe a URL to article events, the response is the only one holding the secret",
+		Tag:         "webhooks",
+		Admin:       true,
+		Body:        "WebhookSubscription",
+		Responses: map[int]responseDoc{
+			http.StatusCreated:    {Description: "Created subscription", Schema: "WebhookSubscription"},
+			http.StatusBadRequest: {Description: "Invalid subscription", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/webhooks/": {
+		OperationID: "listWebhookSubscriptions",
+		Summary:     "List webhook subscriptions",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Subscriptions", Schema: "[]WebhookSubscription"},
+			http.StatusInternalServerError: {Description: "Subscriptions could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/webhooks/:subscription_id": {
+		OperationID: "getWebhookSubscription",
+		Summary:     "Get a webhook subscription",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:       {Description: "Subscription", Schema: "WebhookSubscription"},
+			http.StatusNotFound: {Description: "Subscription not found", Schema: "Error"},
+		},
+	},
+	"PUT /articles/v1/webhooks/:subscription_id": {
+		OperationID: "updateWebhookSubscription",
+		Summary:     "Replace a webhook subscription, keeping the secret unless a new one is given",
+		Tag:         "webhooks",
+		Admin:       true,
+		Body:        "WebhookSubscription",
+		Responses: map[int]responseDoc{
+			http.StatusOK:         {Description: "Updated subscription", Schema: "WebhookSubscription"},
+			http.StatusBadRequest: {Description: "Invalid subscription", Schema: "Error"},
+		},
+	},
+	"DELETE /articles/v1/webhooks/:subscription_id": {
+		OperationID: "deleteWebhookSubscription",
+		Summary:     "Delete a webhook subscription and its deliveries",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:       {Description: "Subscription deleted", Schema: "Message"},
+			http.StatusNotFound: {Description: "Subscription not found", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/webhooks/:subscription_id/deliveries": {
+		OperationID: "listWebhookDeliveries",
+		Summary:     "List the deliveries of a subscription with their attempt log",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Deliveries, newest first", Schema: "[]WebhookDelivery"},
+			http.StatusInternalServerError: {Description: "Deliveries could not be loaded", Schema: "Error"},
+		},
+	},
+	"GET /articles/v1/webhooks/dead-letters": {
+		OperationID: "listDeadWebhookDeliveries",
+		Summary:     "List deliveries that ran out of attempts",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusOK:                  {Description: "Dead deliveries", Schema: "[]WebhookDelivery"},
+			http.StatusInternalServerError: {Description: "Deliveries could not be loaded", Schema: "Error"},
+		},
+	},
+	"POST /articles/v1/webhooks/deliveries/:delivery_id/retry": {
+		OperationID: "retryWebhookDelivery",
+		Summary:     "Queue a dead delivery for another round of attempts",
+		Tag:         "webhooks",
+		Admin:       true,
+		Responses: map[int]responseDoc{
+			http.StatusAccepted:   {Description: "Delivery queued", Schema: "WebhookDelivery"},
+			http.StatusBadRequest: {Description: "Delivery not found or not dead", Schema: "Error"},
+		},
+	},
+	"GET /openapi.json": {
+		OperationID: "getOpenAPI",
+		Summary:     "This document",
+		Tag:         "docs",
+		Responses: map[int]responseDoc{
+			http.StatusOK: {Description: "OpenAPI document"},
+		},
+	},
+	"GET /docs/docs.js": {
+		OperationID: "getDocsScript",
+		Summary:     "Script starting the documentation page",
+		Tag:         "docs",
+		Responses: map[int]responseDoc{
+			http.StatusOK: {Description: "Script", ContentType: "text/javascript"},
+		},
+	},
+	"GET /docs": {
+		OperationID: "getDocs",
+		Summary:     "Interactive API documentation",
+		Tag:         "docs",
+		Responses: map[int]responseDoc{
+			http.StatusOK: {Description: "Documentation page", ContentType: "text/html"},
+		},
+	},
+}
+
+var (
+	feedQuery = []paramDoc{
+		{Name: "format", Description: "Feed format, negotiated from Accept when left out", Enum: []string{"rss", "atom"}},
+	}
+	feedHeaders = []paramDoc{
+		{Name: "If-None-Match", Description: "ETag of a previous response"},
+	}
+	feedResponses = map[int]responseDoc{
+		http.StatusOK:                  {Description: "RSS 2.0 or Atom feed", ContentType: "application/rss+xml"},
+		http.StatusNotModified:         {Description: "No article was added to, changed in or removed from the feed since If-None-Match"},
+		http.StatusInternalServerError: {Description: "Articles could not be loaded", Schema: "Error"},
+	}
+)
+
+var graphQLResponses = map[int]responseDoc{
+	http.StatusOK:         {Description: "GraphQL result, with errors raised while resolving"},
+	http.StatusBadRequest: {Description: "Query could not be parsed, is invalid or exceeds the complexity limits"},
+}
+
+func merge(responses ...map[int]responseDoc) map[int]responseDoc {
+	merged := map[int]responseDoc{}
+	for _, r := range responses {
+		for code, doc := range r {
+			merged[code] = doc
+		}
+	}
+	return merged
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  map[string]interface{}
+)
+
+func serveOpenAPI(ctx *gin.Context) {
+	openAPIOnce.Do(func() {
+		openAPIDoc = buildOpenAPI()
+	})
+	ctx.JSON(http.StatusOK, openAPIDoc)
+}
+
+func serveDocs(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// serveDocsScript starts the docs UI. It is kept out of the page so the
+// content security policy needs no inline scripts.
+func serveDocsScript(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/javascript; charset=utf-8", docsScript)
+}
+
+// pathParam matches gin's :param and *wildcard segments
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z_]+)`)
+
+// buildOpenAPI assembles the OpenAPI 3.1 document from routeDocs and the
+// schemas of schemaTypes.
+func buildOpenAPI() map[string]interface{} {
+	paths := map[string]interface{}{}
+	for route, doc := range routeDocs {
+		method, ginPath, _ := strings.Cut(route, " ")
+		path := openAPIPath(ginPath)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(method)] = buildOperation(ginPath, doc)
+	}
+
+	schemas := map[string]interface{}{}
+	for name, t := range schemaTypes {
+		schemas[name] = structSchema(t)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "Notelify articles service",
+			"version":     "1.0.0",
+			"description": "Articles of the notelify application and the webhooks reporting changes to them.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath turns gin's :param and *wildcard segments into OpenAPI {param} templates.
+func openAPIPath(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+func buildOperation(ginPath string, doc operationDoc) map[string]interface{} {
+	parameters := []interface{}{}
+	for _, match := range pathParam.FindAllStringSubmatch(ginPath, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, param := range doc.Query {
+		parameters = append(parameters, buildParameter(param, "query"))
+	}
+	for _, param := range doc.Headers {
+		parameters = append(parameters, buildParameter(param, "header"))
+	}
+
+	// Every route is rate limited
+	responses := map[string]interface{}{
+		strconv.Itoa(http.StatusTooManyRequests): map[string]interface{}{
+			"description": "Rate limit exceeded, retry after Retry-After seconds",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef("Error")},
+			},
+		},
+	}
+	codes := make([]int, 0, len(doc.Responses))
+	for code := range doc.Responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		res := doc.Responses[code]
+		response := map[string]interface{}{"description": res.Description}
+		if res.Schema != "" || res.ContentType != "" {
+			contentType := res.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			schema := map[string]interface{}{"type": "string"}
+			if res.Schema != "" {
+				schema = schemaRef(res.Schema)
+			}
+			response["content"] = map[string]interface{}{
+				contentType: map[string]interface{}{"schema": schema},
+			}
+		}
+		responses[strconv.Itoa(code)] = response
+	}
+
+	operation := map[string]interface{}{
+		"operationId": doc.OperationID,
+		"summary":     doc.Summary,
+		"tags":        []string{doc.Tag},
+		"parameters":  parameters,
+		"responses":   responses,
+	}
+	if doc.Body != "" {
+		bodyType := doc.BodyType
+		if bodyType == "" {
+			bodyType = "application/json"
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				bodyType: map[string]interface{}{"schema": schemaRef(doc.Body)},
+			},
+		}
+	}
+	if doc.Admin || doc.Author {
+		operation["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+	}
+	return operation
+}
+
+func buildParameter(param paramDoc, in string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "string"}
+	if len(param.Enum) > 0 {
+		schema["enum"] = param.Enum
+	}
+	return map[string]interface{}{
+		"name":        param.Name,
+		"in":          in,
+		"description": param.Description,
+		"schema":      schema,
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	if item, ok := strings.CutPrefix(name, "[]"); ok {
+		return map[string]interface{}{"type": "array", "items": schemaRef(item)}
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// structSchema describes a struct by its json tags, referring to other
+// component types by name.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	addStructFields(t, properties)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func addStructFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addStructFields(field.Type, properties)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type)
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for name, component := range schemaTypes {
+		if t == component {
+			return schemaRef(name)
+		}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t == reflect.TypeOf([]byte{}) {
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := typeSchema(t.Elem())
+		if kind, ok := schema["type"].(string); ok {
+			schema["type"] = []string{kind, "null"}
+			return schema
+		}
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	// interface{} accepts any JSON value
+	return map[string]interface{}{}
+}
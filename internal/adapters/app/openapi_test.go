@@ -0,0 +1,95 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// stubWebhookService only exists so that the webhook routes are registered
+type stubWebhookService struct {
+	ports.WebhookService
+}
+
+// stubMediaService only exists so that the media routes are registered
+type stubMediaService struct {
+	ports.MediaService
+}
+
+// stubSeriesService only exists so that the series routes are registered
+type stubSeriesService struct {
+	ports.SeriesService
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(&stubArticleService{}, &stubWebhookService{}, &stubMediaService{}, &stubSeriesService{}, ratelimit.NewMemoryStore(), stubLogger{}, appConfig.Config{SECRET_KEY: "secret"})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("Expected OpenAPI 3.1.0, got %q", doc.OpenAPI)
+	}
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+		if _, ok := doc.Paths[openAPIPath(route.Path)][strings.ToLower(route.Method)]; !ok {
+			t.Errorf("Route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+		}
+	}
+	for route := range routeDocs {
+		if !registered[route] {
+			t.Errorf("Documented route %s is not registered", route)
+		}
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := buildOpenAPI()
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	article := schemas["Article"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	author, _ := json.Marshal(article["author"])
+	if string(author) != `{"$ref":"#/components/schemas/Author"}` {
+		t.Errorf("Expected author to refer to the Author schema, got %s", author)
+	}
+	publishDate, _ := json.Marshal(article["publish_date"])
+	if !strings.Contains(string(publishDate), `"date-time"`) {
+		t.Errorf("Expected publish_date to be a date-time, got %s", publishDate)
+	}
+	if _, ok := schemas["Author"].(map[string]interface{})["properties"].(map[string]interface{})["handle"]; !ok {
+		t.Error("Expected the Author schema to describe handle")
+	}
+}
+
+func TestDocsPagePinsSwaggerUI(t *testing.T) {
+	assets := regexp.MustCompile(`(?:src|href)="(https://[^"]+)"`).FindAllSubmatch(docsPage, -1)
+	if len(assets) == 0 {
+		t.Fatal("Expected the docs page to load Swagger UI")
+	}
+	pinned := regexp.MustCompile(`^https://unpkg\.com/swagger-ui-dist@\d+\.\d+\.\d+/`)
+	for _, asset := range assets {
+		if !pinned.Match(asset[1]) {
+			t.Errorf("Expected %s to name an exact swagger-ui-dist release", asset[1])
+		}
+	}
+}
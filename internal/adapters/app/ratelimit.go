@@ -0,0 +1,73 @@
+package app
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimit gives every client a token bucket for reads and one for writes.
+// Clients with a valid token are keyed by author, everyone else by IP
+// address. Requests are let through when the store fails, so an outage of
+// the store does not take the API down with it.
+func rateLimit(store ratelimit.Store, read, write ratelimit.Policy, secretKey string, logger ports.LoggingService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		policy, kind := write, "write"
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			policy, kind = read, "read"
+		}
+		if !policy.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		result, err := store.Take(ctx.Request.Context(), kind+":"+clientKey(ctx, secretKey), policy)
+		if err != nil {
+			logEntry := domain.LogMessage{
+				LogLevel: "WARNING",
+				Service:  "articles",
+				Message:  "rate limit store failed: " + err.Error(),
+			}
+			logger.LogWarning(logEntry)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Policy", policy.String())
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			ctx.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit exceeded",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// clientKey identifies the caller by the subject of a valid bearer token, or
+// by IP address. Invalid tokens are left for the routes requiring one to
+// reject.
+func clientKey(ctx *gin.Context, secretKey string) string {
+	if token, err := auth.BearerToken(ctx.GetHeader("Authorization")); err == nil {
+		if claims, err := auth.ParseToken(secretKey, token); err == nil && claims.Subject != "" {
+			return "author:" + claims.Subject
+		}
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
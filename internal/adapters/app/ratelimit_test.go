@@ -0,0 +1,85 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	read := ratelimit.Policy{Limit: 5, Window: time.Minute}
+	write := ratelimit.Policy{Limit: 2, Window: time.Minute}
+	router := gin.New()
+	router.Use(rateLimit(ratelimit.NewMemoryStore(), read, write, "secret", stubLogger{}))
+	router.GET("/articles/v1/", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.POST("/articles/v1/", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+
+	serve := func(method, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/articles/v1/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(http.MethodPost, ""); rec.Code != http.StatusCreated {
+			t.Fatalf("Expected write %d to be allowed, got %d", i+1, rec.Code)
+		}
+	}
+	rec := serve(http.MethodPost, "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" || rec.Header().Get("RateLimit-Remaining") != "0" || rec.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("Unexpected headers %v", rec.Header())
+	}
+
+	// Reads have their own bucket
+	rec = serve(http.MethodGet, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "5" || rec.Header().Get("RateLimit-Remaining") != "4" {
+		t.Errorf("Expected read to be allowed under the read policy, got %d %v", rec.Code, rec.Header())
+	}
+
+	// Authenticated authors are limited apart from their IP address
+	token := signToken(t, "secret", "author-1", "")
+	if rec := serve(http.MethodPost, token); rec.Code != http.StatusCreated {
+		t.Errorf("Expected an authenticated author to be allowed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// httptest requests come from 192.0.2.1
+	statuses := func(trusted []string) []int {
+		router := NewRouter(&stubArticleService{}, nil, nil, nil, ratelimit.NewMemoryStore(), stubLogger{}, appConfig.Config{
+			RATE_LIMIT_READ:        1,
+			RATE_LIMIT_READ_WINDOW: time.Minute,
+			TRUSTED_PROXIES:        trusted,
+		})
+		codes := []int{}
+		for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+			req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+			req.Header.Set("X-Forwarded-For", client)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			codes = append(codes, rec.Code)
+		}
+		return codes
+	}
+
+	if codes := statuses(nil); codes[1] != http.StatusTooManyRequests {
+		t.Errorf("Expected X-Forwarded-For to be ignored without trusted proxies, got %v", codes)
+	}
+	if codes := statuses([]string{"192.0.2.0/24"}); codes[0] != http.StatusOK || codes[1] != http.StatusOK {
+		t.Errorf("Expected clients behind a trusted proxy to be limited apart, got %v", codes)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SeriesHandler interface {
+	CreateSeries(ctx *gin.Context)
+	GetSeries(ctx *gin.Context)
+	GetSeriesList(ctx *gin.Context)
+	UpdateSeries(ctx *gin.Context)
+	DeleteSeries(ctx *gin.Context)
+}
+
+type seriesHandler struct {
+	svc ports.SeriesService
+}
+
+func NewSeriesHandler(svc ports.SeriesService) SeriesHandler {
+	return seriesHandler{svc: svc}
+}
+
+func (h seriesHandler) CreateSeries(ctx *gin.Context) {
+	var res *domain.Series
+	if err := ctx.ShouldBindJSON(&res); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if res.AuthorID != "" && res.AuthorID != callerID(ctx) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "series can only be created by their author",
+		})
+		return
+	}
+	res.AuthorID = callerID(ctx)
+
+	response, err := h.svc.CreateSeries(res)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (h seriesHandler) GetSeries(ctx *gin.Context) {
+	series_id := ctx.Param("series_id")
+	response, err := h.svc.GetSeries(series_id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h seriesHandler) GetSeriesList(ctx *gin.Context) {
+	response, err := h.svc.GetSeriesList(ctx.Query("author_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h seriesHandler) UpdateSeries(ctx *gin.Context) {
+	series_id := ctx.Param("series_id")
+	if !h.authorize(ctx, series_id) {
+		return
+	}
+	var res *domain.Series
+	if err := ctx.ShouldBindJSON(&res); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.svc.UpdateSeries(series_id, res)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h seriesHandler) DeleteSeries(ctx *gin.Context) {
+	series_id := ctx.Param("series_id")
+	if !h.authorize(ctx, series_id) {
+		return
+	}
+	if err := h.svc.DeleteSeries(series_id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Series deleted successfully",
+	})
+}
+
+// authorize answers for the series and returns false unless the caller is its
+// author
+func (h seriesHandler) authorize(ctx *gin.Context, series_id string) bool {
+	series, err := h.svc.GetSeries(series_id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return false
+	}
+	if series.AuthorID != callerID(ctx) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "series can only be changed by their author",
+		})
+		return false
+	}
+	return true
+}
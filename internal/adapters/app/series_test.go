@@ -0,0 +1,82 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// authorSeriesService holds series s1 by author-1 and records the changes made
+type authorSeriesService struct {
+	ports.SeriesService
+	created []domain.Series
+	deleted []string
+}
+
+func (svc *authorSeriesService) CreateSeries(series *domain.Series) (*domain.Series, error) {
+	svc.created = append(svc.created, *series)
+	return series, nil
+}
+
+func (svc *authorSeriesService) GetSeries(series_id string) (*domain.Series, error) {
+	if series_id != "s1" {
+		return nil, errors.New("series not found")
+	}
+	return &domain.Series{SeriesID: "s1", AuthorID: "author-1", Title: "Tutorial"}, nil
+}
+
+func (svc *authorSeriesService) DeleteSeries(series_id string) error {
+	svc.deleted = append(svc.deleted, series_id)
+	return nil
+}
+
+func TestSeriesAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secretKey := "testsecret"
+	svc := &authorSeriesService{}
+	handler := NewSeriesHandler(svc)
+	router := gin.New()
+	router.POST("/articles/v1/series/", requireToken(secretKey), handler.CreateSeries)
+	router.DELETE("/articles/v1/series/:series_id", requireToken(secretKey), handler.DeleteSeries)
+
+	request := func(method, target, token, body string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	author := signToken(t, secretKey, "author-1", "")
+	other := signToken(t, secretKey, "author-2", "")
+
+	if code := request(http.MethodPost, "/articles/v1/series/", "", `{"title": "Tutorial"}`); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", code)
+	}
+	if code := request(http.MethodPost, "/articles/v1/series/", other, `{"title": "Tutorial", "author_id": "author-1"}`); code != http.StatusForbidden {
+		t.Errorf("Expected 403 creating a series of another author, got %d", code)
+	}
+	if code := request(http.MethodPost, "/articles/v1/series/", author, `{"title": "Tutorial"}`); code != http.StatusCreated {
+		t.Errorf("Expected 201, got %d", code)
+	}
+	if len(svc.created) != 1 || svc.created[0].AuthorID != "author-1" {
+		t.Errorf("Expected the series created for the caller, got %+v", svc.created)
+	}
+
+	if code := request(http.MethodDelete, "/articles/v1/series/s1", other, ""); code != http.StatusForbidden {
+		t.Errorf("Expected 403 deleting another author's series, got %d", code)
+	}
+	if code := request(http.MethodDelete, "/articles/v1/series/missing", author, ""); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing series, got %d", code)
+	}
+	if code := request(http.MethodDelete, "/articles/v1/series/s1", author, ""); code != http.StatusOK || len(svc.deleted) != 1 {
+		t.Errorf("Expected the author to delete the series, got %d", code)
+	}
+}
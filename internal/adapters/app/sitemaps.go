@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/sitemap"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapHandler interface {
+	GetSitemap(ctx *gin.Context)
+	GetSitemapPart(ctx *gin.Context)
+}
+
+type sitemapHandler struct {
+	svc     ports.ArticleService
+	baseURL string
+	logger  ports.LoggingService
+	// size is the number of URLs per sitemap file
+	size int
+}
+
+func NewSitemapHandler(svc ports.ArticleService, baseURL string, logger ports.LoggingService) SitemapHandler {
+	return sitemapHandler{svc: svc, baseURL: strings.TrimSuffix(baseURL, "/"), logger: logger, size: sitemap.MaxURLs}
+}
+
+// GetSitemap lists every published article, or once there are more than fit
+// in one file, an index of the sitemap parts at /sitemap/<n>.xml.
+func (h sitemapHandler) GetSitemap(ctx *gin.Context) {
+	count, err := h.svc.CountPublishedArticles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if count <= h.size {
+		h.writeURLSet(ctx, 0)
+		return
+	}
+
+	baseURL := publicURL(ctx, h.baseURL)
+	parts := (count + h.size - 1) / h.size
+	locs := make([]string, parts)
+	for i := range locs {
+		locs[i] = fmt.Sprintf("%s/sitemap/%d.xml", baseURL, i+1)
+	}
+	ctx.Header("Content-Type", sitemap.ContentType)
+	ctx.Status(http.StatusOK)
+	if err := sitemap.WriteIndex(ctx.Writer, locs); err != nil {
+		h.logError(err)
+	}
+}
+
+// GetSitemapPart serves one part of a split sitemap, numbered from 1.
+func (h sitemapHandler) GetSitemapPart(ctx *gin.Context) {
+	part, err := strconv.Atoi(strings.TrimSuffix(ctx.Param("part"), ".xml"))
+	if err != nil || part < 1 {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "sitemap not found",
+		})
+		return
+	}
+	count, err := h.svc.CountPublishedArticles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if (part-1)*h.size >= count {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "sitemap not found",
+		})
+		return
+	}
+	h.writeURLSet(ctx, (part-1)*h.size)
+}
+
+func (h sitemapHandler) writeURLSet(ctx *gin.Context, offset int) {
+	baseURL := publicURL(ctx, h.baseURL)
+	ctx.Header("Content-Type", sitemap.ContentType)
+	ctx.Status(http.StatusOK)
+
+	// Headers are already sent once streaming starts, so a failure part way
+	// through can only be logged and shows up as a truncated sitemap.
+	urls, err := sitemap.NewURLSetWriter(ctx.Writer)
+	if err != nil {
+		h.logError(err)
+		return
+	}
+	err = h.svc.StreamSitemapEntries(offset, h.size, func(entry domain.SitemapEntry) error {
+		return urls.Add(articleLink(baseURL, entry.ArticleID, entry.Slug), entry.UpdatedDate)
+	})
+	if err == nil {
+		err = urls.Close()
+	}
+	if err != nil {
+		h.logError(err)
+	}
+}
+
+func (h sitemapHandler) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  "writing sitemap failed: " + err.Error(),
+	}
+	h.logger.LogError(logEntry)
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type sitemapArticleService struct {
+	ports.ArticleService
+	entries []domain.SitemapEntry
+	err     error
+}
+
+func (svc *sitemapArticleService) CountPublishedArticles() (int, error) {
+	return len(svc.entries), nil
+}
+
+func (svc *sitemapArticleService) StreamSitemapEntries(offset, limit int, fn func(entry domain.SitemapEntry) error) error {
+	if svc.err != nil {
+		return svc.err
+	}
+	for i := offset; i < offset+limit && i < len(svc.entries); i++ {
+		if err := fn(svc.entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errorLogger records the messages of the errors it is given
+type errorLogger struct {
+	stubLogger
+	errors []string
+}
+
+func (l *errorLogger) LogError(message domain.LogMessage) {
+	l.errors = append(l.errors, message.Message)
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+func TestSitemap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &sitemapArticleService{}
+	for i := 1; i <= 5; i++ {
+		svc.entries = append(svc.entries, domain.SitemapEntry{ArticleID: fmt.Sprintf("a%d", i), Slug: fmt.Sprintf("article-%d", i)})
+	}
+	size := 2
+	get := func(path string) (int, sitemapDoc) {
+		sitemaps := sitemapHandler{svc: svc, baseURL: "https://notelify.example", logger: stubLogger{}, size: size}
+		router := gin.New()
+		router.GET("/sitemap.xml", sitemaps.GetSitemap)
+		router.GET("/sitemap/:part", sitemaps.GetSitemapPart)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var doc sitemapDoc
+		xml.Unmarshal(rec.Body.Bytes(), &doc)
+		return rec.Code, doc
+	}
+
+	_, index := get("/sitemap.xml")
+	if index.XMLName.Local != "sitemapindex" || len(index.Sitemaps) != 3 || index.Sitemaps[2].Loc != "https://notelify.example/sitemap/3.xml" {
+		t.Fatalf("Unexpected sitemap index %+v", index)
+	}
+
+	_, part := get("/sitemap/3.xml")
+	if part.XMLName.Local != "urlset" || len(part.URLs) != 1 || part.URLs[0].Loc != "https://notelify.example/articles/v1/slug/article-5" {
+		t.Errorf("Unexpected last part %+v", part)
+	}
+	if code, _ := get("/sitemap/4.xml"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 past the last part, got %d", code)
+	}
+
+	// A sitemap small enough for one file lists the articles directly
+	size = 10
+	_, urls := get("/sitemap.xml")
+	if urls.XMLName.Local != "urlset" || len(urls.URLs) != 5 {
+		t.Errorf("Unexpected sitemap %+v", urls)
+	}
+}
+
+func TestSitemapLogsFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &sitemapArticleService{
+		entries: []domain.SitemapEntry{{ArticleID: "a1"}},
+		err:     errors.New("connection reset"),
+	}
+	logger := &errorLogger{}
+	router := gin.New()
+	router.GET("/sitemap.xml", NewSitemapHandler(svc, "https://notelify.example", logger).GetSitemap)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+
+	if len(logger.errors) != 1 || !strings.Contains(logger.errors[0], "connection reset") {
+		t.Errorf("Expected the failure to be logged, got %v", logger.errors)
+	}
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler interface {
+	CreateSubscription(ctx *gin.Context)
+	GetSubscription(ctx *gin.Context)
+	GetSubscriptions(ctx *gin.Context)
+	UpdateSubscription(ctx *gin.Context)
+	DeleteSubscription(ctx *gin.Context)
+	GetDeliveries(ctx *gin.Context)
+	GetDeadDeliveries(ctx *gin.Context)
+	RetryDelivery(ctx *gin.Context)
+}
+
+type webhookHandler struct {
+	svc ports.WebhookService
+}
+
+func NewWebhookHandler(svc ports.WebhookService) WebhookHandler {
+	return webhookHandler{svc: svc}
+}
+
+func (h webhookHandler) CreateSubscription(ctx *gin.Context) {
+	var res *domain.WebhookSubscription
+	if err := ctx.ShouldBindJSON(&res); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.svc.CreateSubscription(res)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (h webhookHandler) GetSubscription(ctx *gin.Context) {
+	subscription_id := ctx.Param("subscription_id")
+	response, err := h.svc.GetSubscription(subscription_id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h webhookHandler) GetSubscriptions(ctx *gin.Context) {
+	response, err := h.svc.GetSubscriptions()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h webhookHandler) UpdateSubscription(ctx *gin.Context) {
+	subscription_id := ctx.Param("subscription_id")
+	var res *domain.WebhookSubscription
+	if err := ctx.ShouldBindJSON(&res); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.svc.UpdateSubscription(subscription_id, res)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h webhookHandler) DeleteSubscription(ctx *gin.Context) {
+	subscription_id := ctx.Param("subscription_id")
+	if err := h.svc.DeleteSubscription(subscription_id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+func (h webhookHandler) GetDeliveries(ctx *gin.Context) {
+	subscription_id := ctx.Param("subscription_id")
+	response, err := h.svc.GetDeliveries(subscription_id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h webhookHandler) GetDeadDeliveries(ctx *gin.Context) {
+	response, err := h.svc.GetDeadDeliveries()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h webhookHandler) RetryDelivery(ctx *gin.Context) {
+	delivery_id := ctx.Param("delivery_id")
+	response, err := h.svc.RetryDelivery(delivery_id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, response)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const RoleAdmin = "admin"
+
+// Claims are the claims notelify services put in their HS256 access tokens.
+// The subject is the author ID of the caller.
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// ParseToken verifies an access token signed with secretKey and returns its
+// claims. Expired tokens and tokens signed with any other algorithm are
+// rejected.
+func ParseToken(secretKey, token string) (*Claims, error) {
+	if secretKey == "" {
+		return nil, errors.New("token verification is not configured")
+	}
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header value.
+func BearerToken(header string) (string, error) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", errors.New("missing bearer token")
+	}
+	return token, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// confirmationTTL is how long a confirmation token stays valid
+const confirmationTTL = 2 * time.Minute
+
+// ConfirmationStore hands out tokens for two step confirmation of
+// destructive operations. A token is bound to the subject it was issued to
+// and carries its expiry, signed with the secret key, so that any instance
+// sharing the key accepts it, also after a restart.
+//
+// Consumed tokens are only remembered by the instance that consumed them: a
+// token is single use per instance, and may be replayed on another instance
+// by the same subject until it expires.
+type ConfirmationStore struct {
+	secretKey []byte
+	mu        sync.Mutex
+	consumed  map[string]time.Time
+	now       func() time.Time
+}
+
+func NewConfirmationStore(secretKey string) *ConfirmationStore {
+	return &ConfirmationStore{
+		secretKey: []byte(secretKey),
+		consumed:  map[string]time.Time{},
+		now:       time.Now,
+	}
+}
+
+// Issue returns a new token for subject and the time it expires at.
+func (s *ConfirmationStore) Issue(subject string) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	nonce := hex.EncodeToString(buf)
+	expiresAt := s.now().Add(confirmationTTL).Truncate(time.Second)
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return nonce + "." + expiry + "." + s.sign(nonce, expiry, subject), expiresAt, nil
+}
+
+// Consume reports whether token is valid for subject. A token can only be
+// consumed once on this instance.
+func (s *ConfirmationStore) Consume(token, subject string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[0], parts[1], subject))) {
+		return false
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	expiresAt := time.Unix(expiry, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for nonce, at := range s.consumed {
+		if now.After(at) {
+			delete(s.consumed, nonce)
+		}
+	}
+	if now.After(expiresAt) {
+		return false
+	}
+	if _, ok := s.consumed[parts[0]]; ok {
+		return false
+	}
+	s.consumed[parts[0]] = expiresAt
+	return true
+}
+
+// sign returns the HMAC of the token fields and the subject it is issued to
+func (s *ConfirmationStore) sign(nonce, expiry, subject string) string {
+	mac := hmac.New(sha256.New, s.secretKey)
+	fmt.Fprintf(mac, "confirmation\n%s\n%s\n%s", nonce, expiry, subject)
+	return hex.EncodeToString(mac.Sum(nil))
+}
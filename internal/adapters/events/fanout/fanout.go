@@ -0,0 +1,26 @@
+package fanout
+
+import (
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// fanoutPublisher hands every event to each of its publishers in turn. It
+// fails as soon as one of them does, so the relay retries the event and the
+// publishers that already accepted it see it again.
+type fanoutPublisher struct {
+	publishers []ports.EventPublisher
+}
+
+func NewFanoutPublisher(publishers ...ports.EventPublisher) *fanoutPublisher {
+	return &fanoutPublisher{publishers: publishers}
+}
+
+func (p *fanoutPublisher) Publish(event domain.Event) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
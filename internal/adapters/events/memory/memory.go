@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// memoryPublisher keeps published events in memory. It is meant for tests,
+// which can also make it fail to exercise redelivery.
+type memoryPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+	err    error
+}
+
+func NewMemoryPublisher() *memoryPublisher {
+	return &memoryPublisher{}
+}
+
+func (p *memoryPublisher) Publish(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// SetError makes every following Publish call fail with err, until it is
+// called again with nil.
+func (p *memoryPublisher) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Events returns the events published so far, oldest first
+func (p *memoryPublisher) Events() []domain.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]domain.Event{}, p.events...)
+}
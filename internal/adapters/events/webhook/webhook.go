@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// webhookPublisher POSTs every event as JSON to a single URL. Any response
+// other than 2xx counts as a failed delivery.
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, timeout time.Duration) *webhookPublisher {
+	return &webhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *webhookPublisher) Publish(event domain.Event) error {
+	payloadBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.EventID)
+	req.Header.Set("X-Event-Type", event.EventType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
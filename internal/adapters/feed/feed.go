@@ -0,0 +1,210 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+)
+
+// Feed is a list of articles ready to be written as RSS 2.0 or Atom.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the URL the feed itself is served at
+	Link  string
+	Items []Item
+}
+
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+}
+
+// FromArticles builds a feed with one item per article. articleURL gives
+// the address readers follow from an item.
+func FromArticles(title, description, link string, articles []domain.Article, articleURL func(domain.Article) string) Feed {
+	feed := Feed{Title: title, Description: description, Link: link, Items: []Item{}}
+	for _, article := range articles {
+		feed.Items = append(feed.Items, Item{
+			ID:        "urn:notelify:article:" + article.ArticleID,
+			Title:     article.Title,
+			Link:      articleURL(article),
+			Summary:   article.Introduction,
+			Author:    authorName(article),
+			Published: article.PublishDate,
+			Updated:   article.UpdatedDate,
+		})
+	}
+	return feed
+}
+
+// LastModified is the latest change to any item. Publication counts as a
+// change, so a scheduled article going live moves it forward.
+func (f Feed) LastModified() time.Time {
+	var last time.Time
+	for _, item := range f.Items {
+		if item.Updated.After(last) {
+			last = item.Updated
+		}
+		if item.Published.After(last) {
+			last = item.Published
+		}
+	}
+	return last
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description"`
+	Creator     string  `xml:"dc:creator,omitempty"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS writes the feed as RSS 2.0. Authors go into dc:creator, since the RSS
+// author element expects an email address.
+func (f Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			SelfLink:    atomLink{Href: f.Link, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if last := f.LastModified(); !last.IsZero() {
+		doc.Channel.LastBuildDate = last.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Summary,
+			Creator:     item.Author,
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return marshal(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Link      atomLink   `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Author    atomAuthor `xml:"author"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// Atom writes the feed as Atom 1.0.
+func (f Feed) Atom() ([]byte, error) {
+	updated := f.LastModified()
+	if updated.IsZero() {
+		// An empty feed still needs an updated date
+		updated = time.Now()
+	}
+	doc := atomFeed{
+		NS:      "http://www.w3.org/2005/Atom",
+		ID:      f.Link,
+		Title:   f.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: f.Link, Rel: "self", Type: "application/atom+xml"},
+	}
+	for _, item := range f.Items {
+		updated := item.Updated
+		if updated.IsZero() {
+			updated = item.Published
+		}
+		doc.Entries = append(doc.Entries, atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Href: item.Link, Rel: "alternate"},
+			Summary:   item.Summary,
+			Author:    atomAuthor{Name: item.Author},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   updated.UTC().Format(time.RFC3339),
+		})
+	}
+	return marshal(doc)
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// authorName prefers the author's full name, then their handle. Atom needs
+// a name on every entry, so the author ID is the last resort.
+func authorName(article domain.Article) string {
+	name := strings.TrimSpace(article.Author.Firstname + " " + article.Author.Lastname)
+	if name != "" {
+		return name
+	}
+	if article.Author.Handle != "" {
+		return article.Author.Handle
+	}
+	return article.AuthorID
+}
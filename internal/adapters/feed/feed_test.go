@@ -0,0 +1,73 @@
+package feed
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+func testFeed() Feed {
+	published := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	articles := []domain.Article{
+		{
+			ArticleID:    "a1",
+			Slug:         "first",
+			Title:        "First & foremost",
+			Introduction: "An <introduction>",
+			PublishDate:  published,
+			UpdatedDate:  published.Add(time.Hour),
+			Author:       domain.Author{Firstname: "Ada", Lastname: "Lovelace"},
+		},
+		{
+			ArticleID:   "a2",
+			Title:       "Second",
+			PublishDate: published.Add(48 * time.Hour),
+			UpdatedDate: published,
+			AuthorID:    "author-2",
+		},
+	}
+	return FromArticles("Articles", "Latest", "https://example.com/feed.xml", articles, func(article domain.Article) string {
+		return "https://example.com/" + article.ArticleID
+	})
+}
+
+func TestRSS(t *testing.T) {
+	out, err := testFeed().RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc rss
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Invalid RSS %s: %v", out, err)
+	}
+	items := doc.Channel.Items
+	if len(items) != 2 || items[0].Title != "First & foremost" || items[0].Description != "An <introduction>" {
+		t.Fatalf("Unexpected items %+v", items)
+	}
+	if items[0].PubDate != "Fri, 01 Sep 2023 10:00:00 +0000" {
+		t.Errorf("Unexpected pubDate %s", items[0].PubDate)
+	}
+	if items[1].Link != "https://example.com/a2" || items[1].GUID.Value != "urn:notelify:article:a2" {
+		t.Errorf("Unexpected item %+v", items[1])
+	}
+}
+
+func TestAtom(t *testing.T) {
+	out, err := testFeed().Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Invalid Atom %s: %v", out, err)
+	}
+	if len(doc.Entries) != 2 || doc.Entries[0].Author.Name != "Ada Lovelace" || doc.Entries[1].Author.Name != "author-2" {
+		t.Fatalf("Unexpected entries %+v", doc.Entries)
+	}
+	// The second article was published after the first one was last updated
+	if doc.Updated != "2023-09-03T10:00:00Z" {
+		t.Errorf("Expected the feed to be updated at the latest publication, got %s", doc.Updated)
+	}
+}
@@ -0,0 +1,148 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// maxComplexity bounds the estimated number of fields a query resolves
+	maxComplexity = 1000
+	// maxDepth bounds how deeply selections may nest
+	maxDepth = 10
+	// defaultPageSize and maxPageSize apply to the first argument of lists
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// complexity estimates the cost of an operation before it runs. Every field
+// costs one, and the selections under a paginated field count once per
+// requested item.
+type complexity struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkComplexity rejects operations that exceed maxComplexity or maxDepth.
+func checkComplexity(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	c := complexity{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+	}
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		cost, err := c.selectionSet(operation.SelectionSet, 1, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		if cost > maxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the limit of %d", cost, maxComplexity)
+		}
+	}
+	return nil
+}
+
+func (c complexity) selectionSet(set *ast.SelectionSet, depth int, visited map[string]bool) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+	if depth > maxDepth {
+		return 0, fmt.Errorf("query depth exceeds the limit of %d", maxDepth)
+	}
+
+	total := 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			children, err := c.selectionSet(selection.SelectionSet, depth+1, visited)
+			if err != nil {
+				return 0, err
+			}
+			total += 1 + c.multiplier(selection)*children
+		case *ast.InlineFragment:
+			cost, err := c.selectionSet(selection.SelectionSet, depth, visited)
+			if err != nil {
+				return 0, err
+			}
+			total += cost
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || visited[name] {
+				// Unknown and cyclic fragments are reported by validation
+				continue
+			}
+			visited[name] = true
+			cost, err := c.selectionSet(fragment.SelectionSet, depth, visited)
+			delete(visited, name)
+			if err != nil {
+				return 0, err
+			}
+			total += cost
+		}
+	}
+	return total, nil
+}
+
+// multiplier is the number of items a field returns, taken from its first
+// argument when it has one.
+func (c complexity) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		return pageSize(c.value(argument.Value))
+	}
+	if field.Name.Value == "articles" {
+		return defaultPageSize
+	}
+	return 1
+}
+
+func (c complexity) value(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		if err != nil {
+			return nil
+		}
+		return n
+	case *ast.Variable:
+		return c.variables[value.Name.Value]
+	}
+	return nil
+}
+
+// pageSize turns a first argument into the number of items to return.
+func pageSize(first interface{}) int {
+	var n int
+	switch first := first.(type) {
+	case int:
+		n = first
+	case float64:
+		// JSON decoded variables
+		n = int(first)
+	default:
+		return defaultPageSize
+	}
+	if n < 1 {
+		return defaultPageSize
+	}
+	if n > maxPageSize {
+		return maxPageSize
+	}
+	return n
+}
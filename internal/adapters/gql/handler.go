@@ -0,0 +1,89 @@
+package gql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type graphqlHandler struct {
+	svc    ports.ArticleService
+	schema graphql.Schema
+}
+
+// NewGraphQLHandler serves read queries over articles and their authors at a
+// single endpoint, resolved through svc.
+func NewGraphQLHandler(svc ports.ArticleService) *graphqlHandler {
+	schema, err := newSchema(svc)
+	if err != nil {
+		// The schema is static, an error here is a programming mistake
+		panic(err)
+	}
+	return &graphqlHandler{svc: svc, schema: schema}
+}
+
+// Handle accepts queries as a JSON POST body or as GET query parameters.
+func (h *graphqlHandler) Handle(ctx *gin.Context) {
+	var req graphqlRequest
+	if ctx.Request.Method == http.MethodGet {
+		req.Query = ctx.Query("query")
+		req.OperationName = ctx.Query("operationName")
+		if variables := ctx.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{
+					"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())},
+				})
+				return
+			}
+		}
+	} else if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())},
+		})
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errors": []gqlerrors.FormattedError{gqlerrors.FormatError(err)},
+		})
+		return
+	}
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errors": validation.Errors,
+		})
+		return
+	}
+	if err := checkComplexity(doc, req.OperationName, req.Variables); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())},
+		})
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withAuthorLoader(ctx.Request.Context(), h.svc),
+	})
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,184 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type stubArticleService struct {
+	ports.ArticleService
+	articles    []domain.Article
+	queries     []domain.ArticleQuery
+	authorCalls [][]string
+}
+
+func (s *stubArticleService) GetArticleByID(article_id string) (*domain.Article, error) {
+	for _, article := range s.articles {
+		if article.ArticleID == article_id {
+			return &article, nil
+		}
+	}
+	return nil, errors.New("sql: no rows in result set")
+}
+
+func (s *stubArticleService) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	s.queries = append(s.queries, query)
+	articles := []domain.Article{}
+	seeking := query.After.ArticleID != ""
+	for _, article := range s.articles {
+		if seeking {
+			seeking = article.ArticleID != query.After.ArticleID
+			continue
+		}
+		if query.AuthorID == "" || article.AuthorID == query.AuthorID {
+			articles = append(articles, article)
+		}
+	}
+	if query.Limit > 0 && len(articles) > query.Limit {
+		articles = articles[:query.Limit]
+	}
+	return &articles, nil
+}
+
+func (s *stubArticleService) GetAuthors(author_ids []string) (map[string]domain.Author, error) {
+	s.authorCalls = append(s.authorCalls, author_ids)
+	authors := map[string]domain.Author{}
+	for _, id := range author_ids {
+		authors[id] = domain.Author{AuthorID: id, Handle: "@" + id}
+	}
+	return authors, nil
+}
+
+func newStubService() *stubArticleService {
+	svc := &stubArticleService{}
+	for i := 1; i <= 5; i++ {
+		svc.articles = append(svc.articles, domain.Article{
+			ArticleID: fmt.Sprintf("a%d", i),
+			Title:     fmt.Sprintf("Article %d", i),
+			Body:      "body",
+			AuthorID:  fmt.Sprintf("author-%d", i%2),
+		})
+	}
+	return svc
+}
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func doQuery(t *testing.T, svc ports.ArticleService, query string, variables map[string]interface{}) (int, graphqlResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/graphql", NewGraphQLHandler(svc).Handle)
+
+	body, _ := json.Marshal(graphqlRequest{Query: query, Variables: variables})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var res graphqlResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Invalid response %s: %v", rec.Body.String(), err)
+	}
+	return rec.Code, res
+}
+
+func TestArticlesBatchesAuthors(t *testing.T) {
+	svc := newStubService()
+	code, res := doQuery(t, svc, `{
+		articles(first: 3) {
+			edges { node { article_id title author { author_id handle } } }
+			page_info { has_next_page end_cursor }
+		}
+	}`, nil)
+	if code != http.StatusOK || len(res.Errors) > 0 {
+		t.Fatalf("Unexpected response %d %+v", code, res.Errors)
+	}
+
+	var raw struct {
+		Edges []struct {
+			Node struct {
+				ArticleID string        `json:"article_id"`
+				Author    domain.Author `json:"author"`
+			} `json:"node"`
+		} `json:"edges"`
+		PageInfo pageInfo `json:"page_info"`
+	}
+	json.Unmarshal(res.Data["articles"], &raw)
+	if len(raw.Edges) != 3 || raw.Edges[0].Node.Author.Handle != "@author-1" || !raw.PageInfo.HasNextPage {
+		t.Fatalf("Unexpected articles %+v", raw)
+	}
+
+	if len(svc.authorCalls) != 1 || len(svc.authorCalls[0]) != 2 {
+		t.Errorf("Expected one batched author lookup for two authors, got %v", svc.authorCalls)
+	}
+	if !svc.queries[0].Summary {
+		t.Errorf("Expected bodies to be left out when not selected")
+	}
+	if svc.queries[0].Limit != 4 {
+		t.Errorf("Expected the page to be limited in the query, got limit %d", svc.queries[0].Limit)
+	}
+
+	// The next page starts after the end cursor
+	_, res = doQuery(t, svc, `query($after: String) {
+		articles(first: 3, after: $after) { edges { node { article_id body } } page_info { has_next_page } }
+	}`, map[string]interface{}{"after": raw.PageInfo.EndCursor})
+	json.Unmarshal(res.Data["articles"], &raw)
+	if len(raw.Edges) != 2 || raw.Edges[0].Node.ArticleID != "a4" || raw.PageInfo.HasNextPage {
+		t.Errorf("Unexpected second page %+v", raw)
+	}
+	if svc.queries[1].Summary {
+		t.Errorf("Expected bodies to be loaded when selected")
+	}
+	if svc.queries[1].After.ArticleID != "a3" {
+		t.Errorf("Expected the query to seek past a3, got %+v", svc.queries[1].After)
+	}
+}
+
+func TestArticleQuery(t *testing.T) {
+	svc := newStubService()
+	_, res := doQuery(t, svc, `{ article(id: "a2") { title author { author_id } } }`, nil)
+	if len(res.Errors) > 0 || !strings.Contains(string(res.Data["article"]), `"author-0"`) {
+		t.Errorf("Unexpected response %s %+v", res.Data["article"], res.Errors)
+	}
+
+	_, res = doQuery(t, svc, `{ article(id: "missing") { title } }`, nil)
+	if len(res.Errors) != 1 || string(res.Data["article"]) != "null" {
+		t.Errorf("Expected an error for a missing article, got %+v", res)
+	}
+}
+
+func TestQueryLimits(t *testing.T) {
+	svc := newStubService()
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{"within limits", `{ articles(first: 10) { edges { node { title author { handle } } } } }`, http.StatusOK},
+		{"too complex", `{
+			a: articles(first: 100) { edges { node { title subtitle body tags author { handle about } } } }
+			b: articles(first: 100) { edges { node { title subtitle body tags author { handle about } } } }
+		}`, http.StatusBadRequest},
+		{"unknown field", `{ articles { edges { node { password } } } }`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		code, res := doQuery(t, svc, test.query, nil)
+		if code != test.code {
+			t.Errorf("%s: expected %d, got %d %+v", test.name, test.code, code, res.Errors)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gql
+
+import (
+	"sync"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// authorLoader batches the author lookups of one request. Resolvers queue
+// the IDs they need and receive a thunk; the executor only calls the thunks
+// once every resolver of the current level ran, so the first thunk loads all
+// queued authors with a single GetAuthors call.
+type authorLoader struct {
+	svc     ports.ArticleService
+	mu      sync.Mutex
+	pending map[string]bool
+	authors map[string]domain.Author
+	err     error
+}
+
+func newAuthorLoader(svc ports.ArticleService) *authorLoader {
+	return &authorLoader{
+		svc:     svc,
+		pending: map[string]bool{},
+		authors: map[string]domain.Author{},
+	}
+}
+
+// queue registers author_id for the next batch and returns a thunk that
+// yields the author, or nil when no article of theirs is left.
+func (l *authorLoader) queue(author_id string) func() (*domain.Author, error) {
+	l.mu.Lock()
+	if _, ok := l.authors[author_id]; !ok {
+		l.pending[author_id] = true
+	}
+	l.mu.Unlock()
+
+	return func() (*domain.Author, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.pending[author_id] {
+			l.flush()
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+		author, ok := l.authors[author_id]
+		if !ok {
+			return nil, nil
+		}
+		return &author, nil
+	}
+}
+
+// flush loads every pending author. It must be called with mu held.
+func (l *authorLoader) flush() {
+	ids := make([]string, 0, len(l.pending))
+	for id := range l.pending {
+		ids = append(ids, id)
+	}
+	l.pending = map[string]bool{}
+
+	authors, err := l.svc.GetAuthors(ids)
+	if err != nil {
+		l.err = err
+		return
+	}
+	for id, author := range authors {
+		l.authors[id] = author
+	}
+}
@@ -0,0 +1,315 @@
+package gql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+type loaderKey struct{}
+
+// articleEdge, articleConnection and pageInfo back the cursor pagination of
+// the articles field. Field names follow the json tags, as the default
+// resolver does.
+type articleEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   domain.Article `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"has_next_page"`
+	EndCursor   string `json:"end_cursor"`
+}
+
+type articleConnection struct {
+	Edges    []articleEdge `json:"edges"`
+	PageInfo pageInfo      `json:"page_info"`
+}
+
+func newSchema(svc ports.ArticleService) (graphql.Schema, error) {
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.Fields{
+			"author_id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"firstname":          &graphql.Field{Type: graphql.String},
+			"lastname":           &graphql.Field{Type: graphql.String},
+			"handle":             &graphql.Field{Type: graphql.String},
+			"about":              &graphql.Field{Type: graphql.String},
+			"profile_image":      &graphql.Field{Type: graphql.String},
+			"social_media_links": &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"following":          &graphql.Field{Type: graphql.Int},
+			"followers":          &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	imageVariantType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ImageVariant",
+		Fields: graphql.Fields{
+			"name":   &graphql.Field{Type: graphql.String},
+			"format": &graphql.Field{Type: graphql.String},
+			"url":    &graphql.Field{Type: graphql.String},
+			"width":  &graphql.Field{Type: graphql.Int},
+			"height": &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	articleLinkType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ArticleLink",
+		Fields: graphql.Fields{
+			"article_id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"slug":       &graphql.Field{Type: graphql.String},
+			"title":      &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	seriesNavigationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SeriesNavigation",
+		Fields: graphql.Fields{
+			"series_id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":     &graphql.Field{Type: graphql.String},
+			"position":  &graphql.Field{Type: graphql.Int},
+			"total":     &graphql.Field{Type: graphql.Int},
+			"previous":  &graphql.Field{Type: articleLinkType},
+			"next":      &graphql.Field{Type: articleLinkType},
+		},
+	})
+
+	articleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Article",
+		Fields: graphql.Fields{
+			"article_id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"slug":            &graphql.Field{Type: graphql.String},
+			"title":           &graphql.Field{Type: graphql.String},
+			"subtitle":        &graphql.Field{Type: graphql.String},
+			"introduction":    &graphql.Field{Type: graphql.String},
+			"body":            &graphql.Field{Type: graphql.String},
+			"tags":            &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"publish_date":    &graphql.Field{Type: graphql.DateTime},
+			"updated_date":    &graphql.Field{Type: graphql.DateTime},
+			"author_id":       &graphql.Field{Type: graphql.ID},
+			"word_count":      &graphql.Field{Type: graphql.Int},
+			"reading_minutes": &graphql.Field{Type: graphql.Int},
+			"excerpt":         &graphql.Field{Type: graphql.String},
+			"cover_image":     &graphql.Field{Type: graphql.String},
+			"cover_variants":  &graphql.Field{Type: graphql.NewList(imageVariantType)},
+			"series":          &graphql.Field{Type: seriesNavigationType},
+			"author": &graphql.Field{
+				Type:    authorType,
+				Resolve: resolveAuthor,
+			},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ArticleEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(articleType)},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"has_next_page": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"end_cursor":    &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ArticleConnection",
+		Fields: graphql.Fields{
+			"edges":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"page_info": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ArticleFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"author_id": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"tag":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"from":      &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"to":        &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"status":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"sort":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"article": &graphql.Field{
+				Type: articleType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return svc.GetArticleByID(p.Args["id"].(string))
+				},
+			},
+			"articles": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveArticles(svc, p)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// resolveArticles pages through the articles matching the filter. The body
+// is only loaded when the query asks for it.
+func resolveArticles(svc ports.ArticleService, p graphql.ResolveParams) (interface{}, error) {
+	query := domain.ArticleQuery{
+		Summary: !selects(p.Info.FieldASTs, p.Info.Fragments, "edges", "node", "body"),
+	}
+	if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+		query.AuthorID, _ = filter["author_id"].(string)
+		query.Tag, _ = filter["tag"].(string)
+		query.Status, _ = filter["status"].(string)
+		query.Sort, _ = filter["sort"].(string)
+		if from, ok := filter["from"].(time.Time); ok {
+			query.From = from
+		}
+		if to, ok := filter["to"].(time.Time); ok {
+			query.To = to
+		}
+	}
+
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		query.After = cursor
+	}
+	// One more article than asked for tells whether there is a next page
+	first := pageSize(p.Args["first"])
+	query.Limit = first + 1
+
+	articles, err := svc.FindArticles(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := *articles
+	connection := articleConnection{Edges: []articleEdge{}}
+	if len(page) > first {
+		page = page[:first]
+		connection.PageInfo.HasNextPage = true
+	}
+	for _, article := range page {
+		connection.Edges = append(connection.Edges, articleEdge{Cursor: encodeCursor(query.CursorOf(article)), Node: article})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	return connection, nil
+}
+
+// resolveAuthor defers to the request's author loader so that the authors of
+// a whole page are fetched at once. Articles whose author has no stored
+// profile fall back to the copy they carry.
+func resolveAuthor(p graphql.ResolveParams) (interface{}, error) {
+	var article domain.Article
+	switch source := p.Source.(type) {
+	case domain.Article:
+		article = source
+	case *domain.Article:
+		article = *source
+	default:
+		return nil, nil
+	}
+	loader, ok := p.Context.Value(loaderKey{}).(*authorLoader)
+	if !ok || article.AuthorID == "" {
+		return article.Author, nil
+	}
+
+	load := loader.queue(article.AuthorID)
+	return func() (interface{}, error) {
+		author, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if author == nil {
+			return article.Author, nil
+		}
+		return *author, nil
+	}, nil
+}
+
+func withAuthorLoader(ctx context.Context, svc ports.ArticleService) context.Context {
+	return context.WithValue(ctx, loaderKey{}, newAuthorLoader(svc))
+}
+
+// selects reports whether the selection under fields reaches the field path,
+// looking through fragments.
+func selects(fields []*ast.Field, fragments map[string]ast.Definition, path ...string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	for _, field := range fields {
+		if field.SelectionSet == nil {
+			continue
+		}
+		for _, child := range childFields(field.SelectionSet, fragments) {
+			if child.Name.Value == path[0] && selects([]*ast.Field{child}, fragments, path[1:]...) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func childFields(set *ast.SelectionSet, fragments map[string]ast.Definition) []*ast.Field {
+	fields := []*ast.Field{}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fields = append(fields, selection)
+		case *ast.InlineFragment:
+			fields = append(fields, childFields(selection.SelectionSet, fragments)...)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value].(*ast.FragmentDefinition); ok {
+				fields = append(fields, childFields(fragment.SelectionSet, fragments)...)
+			}
+		}
+	}
+	return fields
+}
+
+const cursorPrefix = "article:"
+
+// encodeCursor makes an opaque cursor of the sort key and ID of an article
+func encodeCursor(cursor domain.ArticleCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(append([]byte(cursorPrefix), data...))
+}
+
+func decodeCursor(encoded string) (domain.ArticleCursor, error) {
+	var cursor domain.ArticleCursor
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return cursor, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(decoded[len(cursorPrefix):], &cursor); err != nil || cursor.ArticleID == "" {
+		return cursor, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,654 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: notelify/articles/v1/articles.proto
+
+package articlespb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ArticleService_CreateArticle_FullMethodName       = "/notelify.articles.v1.ArticleService/CreateArticle"
+	ArticleService_GetArticleByID_FullMethodName      = "/notelify.articles.v1.ArticleService/GetArticleByID"
+	ArticleService_GetArticleBySlug_FullMethodName    = "/notelify.articles.v1.ArticleService/GetArticleBySlug"
+	ArticleService_GetArticles_FullMethodName         = "/notelify.articles.v1.ArticleService/GetArticles"
+	ArticleService_GetArticlesByAuthor_FullMethodName = "/notelify.articles.v1.ArticleService/GetArticlesByAuthor"
+	ArticleService_GetArticlesByTag_FullMethodName    = "/notelify.articles.v1.ArticleService/GetArticlesByTag"
+	ArticleService_FindArticles_FullMethodName        = "/notelify.articles.v1.ArticleService/FindArticles"
+	ArticleService_UpdateArticle_FullMethodName       = "/notelify.articles.v1.ArticleService/UpdateArticle"
+	ArticleService_DeleteArticle_FullMethodName       = "/notelify.articles.v1.ArticleService/DeleteArticle"
+	ArticleService_DeleteArticleAll_FullMethodName    = "/notelify.articles.v1.ArticleService/DeleteArticleAll"
+	ArticleService_GetDeletedArticles_FullMethodName  = "/notelify.articles.v1.ArticleService/GetDeletedArticles"
+	ArticleService_RestoreArticle_FullMethodName      = "/notelify.articles.v1.ArticleService/RestoreArticle"
+	ArticleService_ExportArticles_FullMethodName      = "/notelify.articles.v1.ArticleService/ExportArticles"
+	ArticleService_ImportArticles_FullMethodName      = "/notelify.articles.v1.ArticleService/ImportArticles"
+)
+
+// ArticleServiceClient is the client API for ArticleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArticleServiceClient interface {
+	CreateArticle(ctx context.Context, in *CreateArticleRequest, opts ...grpc.CallOption) (*Article, error)
+	GetArticleByID(ctx context.Context, in *GetArticleByIDRequest, opts ...grpc.CallOption) (*Article, error)
+	GetArticleBySlug(ctx context.Context, in *GetArticleBySlugRequest, opts ...grpc.CallOption) (*Article, error)
+	GetArticles(ctx context.Context, in *GetArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error)
+	GetArticlesByAuthor(ctx context.Context, in *GetArticlesByAuthorRequest, opts ...grpc.CallOption) (*ArticleList, error)
+	GetArticlesByTag(ctx context.Context, in *GetArticlesByTagRequest, opts ...grpc.CallOption) (*ArticleList, error)
+	FindArticles(ctx context.Context, in *FindArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error)
+	UpdateArticle(ctx context.Context, in *UpdateArticleRequest, opts ...grpc.CallOption) (*Article, error)
+	DeleteArticle(ctx context.Context, in *DeleteArticleRequest, opts ...grpc.CallOption) (*DeleteArticleResponse, error)
+	// DeleteArticleAll needs an admin token and confirm set to true
+	DeleteArticleAll(ctx context.Context, in *DeleteArticleAllRequest, opts ...grpc.CallOption) (*DeleteArticleResponse, error)
+	GetDeletedArticles(ctx context.Context, in *GetDeletedArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error)
+	RestoreArticle(ctx context.Context, in *RestoreArticleRequest, opts ...grpc.CallOption) (*Article, error)
+	ExportArticles(ctx context.Context, in *ExportArticlesRequest, opts ...grpc.CallOption) (ArticleService_ExportArticlesClient, error)
+	ImportArticles(ctx context.Context, opts ...grpc.CallOption) (ArticleService_ImportArticlesClient, error)
+}
+
+type articleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArticleServiceClient(cc grpc.ClientConnInterface) ArticleServiceClient {
+	return &articleServiceClient{cc}
+}
+
+func (c *articleServiceClient) CreateArticle(ctx context.Context, in *CreateArticleRequest, opts ...grpc.CallOption) (*Article, error) {
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleService_CreateArticle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticleByID(ctx context.Context, in *GetArticleByIDRequest, opts ...grpc.CallOption) (*Article, error) {
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticleByID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticleBySlug(ctx context.Context, in *GetArticleBySlugRequest, opts ...grpc.CallOption) (*Article, error) {
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticleBySlug_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticles(ctx context.Context, in *GetArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error) {
+	out := new(ArticleList)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticlesByAuthor(ctx context.Context, in *GetArticlesByAuthorRequest, opts ...grpc.CallOption) (*ArticleList, error) {
+	out := new(ArticleList)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticlesByAuthor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetArticlesByTag(ctx context.Context, in *GetArticlesByTagRequest, opts ...grpc.CallOption) (*ArticleList, error) {
+	out := new(ArticleList)
+	err := c.cc.Invoke(ctx, ArticleService_GetArticlesByTag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) FindArticles(ctx context.Context, in *FindArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error) {
+	out := new(ArticleList)
+	err := c.cc.Invoke(ctx, ArticleService_FindArticles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) UpdateArticle(ctx context.Context, in *UpdateArticleRequest, opts ...grpc.CallOption) (*Article, error) {
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleService_UpdateArticle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) DeleteArticle(ctx context.Context, in *DeleteArticleRequest, opts ...grpc.CallOption) (*DeleteArticleResponse, error) {
+	out := new(DeleteArticleResponse)
+	err := c.cc.Invoke(ctx, ArticleService_DeleteArticle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) DeleteArticleAll(ctx context.Context, in *DeleteArticleAllRequest, opts ...grpc.CallOption) (*DeleteArticleResponse, error) {
+	out := new(DeleteArticleResponse)
+	err := c.cc.Invoke(ctx, ArticleService_DeleteArticleAll_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) GetDeletedArticles(ctx context.Context, in *GetDeletedArticlesRequest, opts ...grpc.CallOption) (*ArticleList, error) {
+	out := new(ArticleList)
+	err := c.cc.Invoke(ctx, ArticleService_GetDeletedArticles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) RestoreArticle(ctx context.Context, in *RestoreArticleRequest, opts ...grpc.CallOption) (*Article, error) {
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleService_RestoreArticle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleServiceClient) ExportArticles(ctx context.Context, in *ExportArticlesRequest, opts ...grpc.CallOption) (ArticleService_ExportArticlesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ArticleService_ServiceDesc.Streams[0], ArticleService_ExportArticles_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &articleServiceExportArticlesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ArticleService_ExportArticlesClient interface {
+	Recv() (*Article, error)
+	grpc.ClientStream
+}
+
+type articleServiceExportArticlesClient struct {
+	grpc.ClientStream
+}
+
+func (x *articleServiceExportArticlesClient) Recv() (*Article, error) {
+	m := new(Article)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *articleServiceClient) ImportArticles(ctx context.Context, opts ...grpc.CallOption) (ArticleService_ImportArticlesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ArticleService_ServiceDesc.Streams[1], ArticleService_ImportArticles_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &articleServiceImportArticlesClient{stream}
+	return x, nil
+}
+
+type ArticleService_ImportArticlesClient interface {
+	Send(*Article) error
+	CloseAndRecv() (*ImportReport, error)
+	grpc.ClientStream
+}
+
+type articleServiceImportArticlesClient struct {
+	grpc.ClientStream
+}
+
+func (x *articleServiceImportArticlesClient) Send(m *Article) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *articleServiceImportArticlesClient) CloseAndRecv() (*ImportReport, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportReport)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ArticleServiceServer is the server API for ArticleService service.
+// All implementations must embed UnimplementedArticleServiceServer
+// for forward compatibility
+type ArticleServiceServer interface {
+	CreateArticle(context.Context, *CreateArticleRequest) (*Article, error)
+	GetArticleByID(context.Context, *GetArticleByIDRequest) (*Article, error)
+	GetArticleBySlug(context.Context, *GetArticleBySlugRequest) (*Article, error)
+	GetArticles(context.Context, *GetArticlesRequest) (*ArticleList, error)
+	GetArticlesByAuthor(context.Context, *GetArticlesByAuthorRequest) (*ArticleList, error)
+	GetArticlesByTag(context.Context, *GetArticlesByTagRequest) (*ArticleList, error)
+	FindArticles(context.Context, *FindArticlesRequest) (*ArticleList, error)
+	UpdateArticle(context.Context, *UpdateArticleRequest) (*Article, error)
+	DeleteArticle(context.Context, *DeleteArticleRequest) (*DeleteArticleResponse, error)
+	// DeleteArticleAll needs an admin token and confirm set to true
+	DeleteArticleAll(context.Context, *DeleteArticleAllRequest) (*DeleteArticleResponse, error)
+	GetDeletedArticles(context.Context, *GetDeletedArticlesRequest) (*ArticleList, error)
+	RestoreArticle(context.Context, *RestoreArticleRequest) (*Article, error)
+	ExportArticles(*ExportArticlesRequest, ArticleService_ExportArticlesServer) error
+	ImportArticles(ArticleService_ImportArticlesServer) error
+	mustEmbedUnimplementedArticleServiceServer()
+}
+
+// UnimplementedArticleServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedArticleServiceServer struct {
+}
+
+func (UnimplementedArticleServiceServer) CreateArticle(context.Context, *CreateArticleRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateArticle not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticleByID(context.Context, *GetArticleByIDRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleByID not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticleBySlug(context.Context, *GetArticleBySlugRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticleBySlug not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticles(context.Context, *GetArticlesRequest) (*ArticleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticlesByAuthor(context.Context, *GetArticlesByAuthorRequest) (*ArticleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticlesByAuthor not implemented")
+}
+func (UnimplementedArticleServiceServer) GetArticlesByTag(context.Context, *GetArticlesByTagRequest) (*ArticleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticlesByTag not implemented")
+}
+func (UnimplementedArticleServiceServer) FindArticles(context.Context, *FindArticlesRequest) (*ArticleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) UpdateArticle(context.Context, *UpdateArticleRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateArticle not implemented")
+}
+func (UnimplementedArticleServiceServer) DeleteArticle(context.Context, *DeleteArticleRequest) (*DeleteArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteArticle not implemented")
+}
+func (UnimplementedArticleServiceServer) DeleteArticleAll(context.Context, *DeleteArticleAllRequest) (*DeleteArticleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteArticleAll not implemented")
+}
+func (UnimplementedArticleServiceServer) GetDeletedArticles(context.Context, *GetDeletedArticlesRequest) (*ArticleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeletedArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) RestoreArticle(context.Context, *RestoreArticleRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreArticle not implemented")
+}
+func (UnimplementedArticleServiceServer) ExportArticles(*ExportArticlesRequest, ArticleService_ExportArticlesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) ImportArticles(ArticleService_ImportArticlesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportArticles not implemented")
+}
+func (UnimplementedArticleServiceServer) mustEmbedUnimplementedArticleServiceServer() {}
+
+// UnsafeArticleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArticleServiceServer will
+// result in compilation errors.
+type UnsafeArticleServiceServer interface {
+	mustEmbedUnimplementedArticleServiceServer()
+}
+
+func RegisterArticleServiceServer(s grpc.ServiceRegistrar, srv ArticleServiceServer) {
+	s.RegisterService(&ArticleService_ServiceDesc, srv)
+}
+
+func _ArticleService_CreateArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).CreateArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_CreateArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).CreateArticle(ctx, req.(*CreateArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticleByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticleByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticleByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticleByID(ctx, req.(*GetArticleByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticleBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleBySlugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticleBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticleBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticleBySlug(ctx, req.(*GetArticleBySlugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticles(ctx, req.(*GetArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticlesByAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticlesByAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticlesByAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticlesByAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticlesByAuthor(ctx, req.(*GetArticlesByAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetArticlesByTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticlesByTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetArticlesByTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetArticlesByTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetArticlesByTag(ctx, req.(*GetArticlesByTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_FindArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).FindArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_FindArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).FindArticles(ctx, req.(*FindArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_UpdateArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).UpdateArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_UpdateArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).UpdateArticle(ctx, req.(*UpdateArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_DeleteArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).DeleteArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_DeleteArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).DeleteArticle(ctx, req.(*DeleteArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_DeleteArticleAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteArticleAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).DeleteArticleAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_DeleteArticleAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).DeleteArticleAll(ctx, req.(*DeleteArticleAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_GetDeletedArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeletedArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).GetDeletedArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_GetDeletedArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).GetDeletedArticles(ctx, req.(*GetDeletedArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_RestoreArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleServiceServer).RestoreArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleService_RestoreArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleServiceServer).RestoreArticle(ctx, req.(*RestoreArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleService_ExportArticles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportArticlesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArticleServiceServer).ExportArticles(m, &articleServiceExportArticlesServer{stream})
+}
+
+type ArticleService_ExportArticlesServer interface {
+	Send(*Article) error
+	grpc.ServerStream
+}
+
+type articleServiceExportArticlesServer struct {
+	grpc.ServerStream
+}
+
+func (x *articleServiceExportArticlesServer) Send(m *Article) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ArticleService_ImportArticles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ArticleServiceServer).ImportArticles(&articleServiceImportArticlesServer{stream})
+}
+
+type ArticleService_ImportArticlesServer interface {
+	SendAndClose(*ImportReport) error
+	Recv() (*Article, error)
+	grpc.ServerStream
+}
+
+type articleServiceImportArticlesServer struct {
+	grpc.ServerStream
+}
+
+func (x *articleServiceImportArticlesServer) SendAndClose(m *ImportReport) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *articleServiceImportArticlesServer) Recv() (*Article, error) {
+	m := new(Article)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ArticleService_ServiceDesc is the grpc.ServiceDesc for ArticleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArticleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "notelify.articles.v1.ArticleService",
+	HandlerType: (*ArticleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateArticle",
+			Handler:    _ArticleService_CreateArticle_Handler,
+		},
+		{
+			MethodName: "GetArticleByID",
+			Handler:    _ArticleService_GetArticleByID_Handler,
+		},
+		{
+			MethodName: "GetArticleBySlug",
+			Handler:    _ArticleService_GetArticleBySlug_Handler,
+		},
+		{
+			MethodName: "GetArticles",
+			Handler:    _ArticleService_GetArticles_Handler,
+		},
+		{
+			MethodName: "GetArticlesByAuthor",
+			Handler:    _ArticleService_GetArticlesByAuthor_Handler,
+		},
+		{
+			MethodName: "GetArticlesByTag",
+			Handler:    _ArticleService_GetArticlesByTag_Handler,
+		},
+		{
+			MethodName: "FindArticles",
+			Handler:    _ArticleService_FindArticles_Handler,
+		},
+		{
+			MethodName: "UpdateArticle",
+			Handler:    _ArticleService_UpdateArticle_Handler,
+		},
+		{
+			MethodName: "DeleteArticle",
+			Handler:    _ArticleService_DeleteArticle_Handler,
+		},
+		{
+			MethodName: "DeleteArticleAll",
+			Handler:    _ArticleService_DeleteArticleAll_Handler,
+		},
+		{
+			MethodName: "GetDeletedArticles",
+			Handler:    _ArticleService_GetDeletedArticles_Handler,
+		},
+		{
+			MethodName: "RestoreArticle",
+			Handler:    _ArticleService_RestoreArticle_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportArticles",
+			Handler:       _ArticleService_ExportArticles_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportArticles",
+			Handler:       _ArticleService_ImportArticles_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "notelify/articles/v1/articles.proto",
+}
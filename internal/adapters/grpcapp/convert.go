@@ -0,0 +1,128 @@
+package grpcapp
+
+import (
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/grpcapp/articlespb"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoArticle(article *domain.Article) *articlespb.Article {
+	res := &articlespb.Article{
+		ArticleId:      article.ArticleID,
+		Slug:           article.Slug,
+		Title:          article.Title,
+		Subtitle:       article.Subtitle,
+		Introduction:   article.Introduction,
+		Body:           article.Body,
+		BodyHtml:       article.BodyHTML,
+		Tags:           article.Tags,
+		PublishDate:    toProtoTime(article.PublishDate),
+		UpdatedDate:    toProtoTime(article.UpdatedDate),
+		Author:         toProtoAuthor(article.Author),
+		AuthorId:       article.AuthorID,
+		WordCount:      int64(article.WordCount),
+		ReadingMinutes: int64(article.ReadingMinutes),
+		Excerpt:        article.Excerpt,
+	}
+	if article.DeletedAt != nil {
+		res.DeletedAt = timestamppb.New(*article.DeletedAt)
+	}
+	return res
+}
+
+func toProtoArticles(articles *[]domain.Article) *articlespb.ArticleList {
+	res := &articlespb.ArticleList{Articles: make([]*articlespb.Article, 0, len(*articles))}
+	for i := range *articles {
+		res.Articles = append(res.Articles, toProtoArticle(&(*articles)[i]))
+	}
+	return res
+}
+
+func toProtoAuthor(author domain.Author) *articlespb.Author {
+	return &articlespb.Author{
+		AuthorId:         author.AuthorID,
+		Firstname:        author.Firstname,
+		Lastname:         author.Lastname,
+		Handle:           author.Handle,
+		About:            author.About,
+		ProfileImage:     author.ProfileImage,
+		SocialMediaLinks: author.SocialMediaLinks,
+		Following:        int64(author.Following),
+		Followers:        int64(author.Followers),
+	}
+}
+
+// toDomainArticle converts an article sent by a client. Derived fields such
+// as the word count are left for the service to compute.
+func toDomainArticle(article *articlespb.Article) *domain.Article {
+	if article == nil {
+		return &domain.Article{}
+	}
+	res := &domain.Article{
+		ArticleID:    article.GetArticleId(),
+		Slug:         article.GetSlug(),
+		Title:        article.GetTitle(),
+		Subtitle:     article.GetSubtitle(),
+		Introduction: article.GetIntroduction(),
+		Body:         article.GetBody(),
+		Tags:         article.GetTags(),
+		PublishDate:  toDomainTime(article.GetPublishDate()),
+		UpdatedDate:  toDomainTime(article.GetUpdatedDate()),
+		AuthorID:     article.GetAuthorId(),
+	}
+	if author := article.GetAuthor(); author != nil {
+		res.Author = domain.Author{
+			AuthorID:         author.GetAuthorId(),
+			Firstname:        author.GetFirstname(),
+			Lastname:         author.GetLastname(),
+			Handle:           author.GetHandle(),
+			About:            author.GetAbout(),
+			ProfileImage:     author.GetProfileImage(),
+			SocialMediaLinks: author.GetSocialMediaLinks(),
+			Following:        int(author.GetFollowing()),
+			Followers:        int(author.GetFollowers()),
+		}
+	}
+	return res
+}
+
+func toDomainQuery(req *articlespb.FindArticlesRequest) domain.ArticleQuery {
+	return domain.ArticleQuery{
+		AuthorID: req.GetAuthorId(),
+		Tag:      req.GetTag(),
+		From:     toDomainTime(req.GetFrom()),
+		To:       toDomainTime(req.GetTo()),
+		Status:   req.GetStatus(),
+		Sort:     req.GetSort(),
+		Summary:  req.GetSummary(),
+	}
+}
+
+func toProtoImportReport(report *domain.ImportReport) *articlespb.ImportReport {
+	res := &articlespb.ImportReport{
+		Imported: int64(report.Imported),
+		Failed:   int64(report.Failed),
+		Errors:   make([]*articlespb.ImportError, 0, len(report.Errors)),
+	}
+	for _, importErr := range report.Errors {
+		res.Errors = append(res.Errors, &articlespb.ImportError{Line: int64(importErr.Line), Error: importErr.Error})
+	}
+	return res
+}
+
+// toProtoTime leaves zero times unset rather than sending year 1.
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toDomainTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
@@ -0,0 +1,182 @@
+package grpcapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/grpcapp/articlespb"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key carrying the request ID in both
+// directions. Callers may set it to correlate logs across services.
+const RequestIDKey = "x-request-id"
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	claimsContextKey
+)
+
+// adminMethods need a token with the admin role, as their HTTP routes do
+var adminMethods = map[string]bool{
+	articlespb.ArticleService_DeleteArticleAll_FullMethodName:   true,
+	articlespb.ArticleService_GetDeletedArticles_FullMethodName: true,
+	articlespb.ArticleService_RestoreArticle_FullMethodName:     true,
+	articlespb.ArticleService_ExportArticles_FullMethodName:     true,
+	articlespb.ArticleService_ImportArticles_FullMethodName:     true,
+}
+
+// authorMethods need a token of the article's author, as their HTTP routes
+// do. The methods check the author themselves once they know the article.
+var authorMethods = map[string]bool{
+	articlespb.ArticleService_UpdateArticle_FullMethodName: true,
+	articlespb.ArticleService_DeleteArticle_FullMethodName: true,
+}
+
+// RequestIDFromContext returns the ID of the request being served.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// ClaimsFromContext returns the verified token claims of the caller, if it
+// sent a token.
+func ClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*auth.Claims)
+	return claims, ok
+}
+
+// withRequestID takes the caller's request ID, or makes one up, and echoes
+// it back in the response header.
+func withRequestID(ctx context.Context) (context.Context, metadata.MD) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	return context.WithValue(ctx, requestIDContextKey, requestID), metadata.Pairs(RequestIDKey, requestID)
+}
+
+// authenticate verifies the bearer token in the authorization metadata.
+// Tokens are optional except for admin and author methods, but a token that
+// is sent must be valid.
+func authenticate(ctx context.Context, secretKey, method string) (context.Context, error) {
+	header := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+	if header == "" {
+		if adminMethods[method] || authorMethods[method] {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		return ctx, nil
+	}
+
+	token, err := auth.BearerToken(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	claims, err := auth.ParseToken(secretKey, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if adminMethods[method] && !claims.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+	return context.WithValue(ctx, claimsContextKey, claims), nil
+}
+
+func logRequest(logger ports.LoggingService, ctx context.Context, method string, start time.Time, err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message: fmt.Sprintf("GRPC %s %s %s %s",
+			method,
+			status.Code(err).String(),
+			time.Since(start).String(),
+			RequestIDFromContext(ctx),
+		),
+	}
+	logger.LogInfo(logEntry)
+}
+
+func requestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, header := withRequestID(ctx)
+		grpc.SetHeader(ctx, header)
+		return handler(ctx, req)
+	}
+}
+
+func loggingUnaryInterceptor(logger ports.LoggingService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logRequest(logger, ctx, info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+func authUnaryInterceptor(secretKey string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, secretKey, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// serverStream replaces the context of a stream, the streaming counterpart
+// of passing a new context to a unary handler.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func requestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, header := withRequestID(stream.Context())
+		stream.SetHeader(header)
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func loggingStreamInterceptor(logger ports.LoggingService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		logRequest(logger, stream.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func authStreamInterceptor(secretKey string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), secretKey, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
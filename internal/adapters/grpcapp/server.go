@@ -0,0 +1,310 @@
+package grpcapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/grpcapp/articlespb"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// articleServer implements the protobuf ArticleService on top of
+// ports.ArticleService, mirroring the status codes of the Gin handlers.
+type articleServer struct {
+	articlespb.UnimplementedArticleServiceServer
+	svc            ports.ArticleService
+	allowDeleteAll bool
+	confirmations  *auth.ConfirmationStore
+}
+
+// NewGRPCServer returns a gRPC server with the article service and the
+// request ID, logging and auth interceptors registered.
+func NewGRPCServer(svc ports.ArticleService, logger ports.LoggingService, conf appConfig.Config) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			requestIDUnaryInterceptor(),
+			loggingUnaryInterceptor(logger),
+			authUnaryInterceptor(conf.SECRET_KEY),
+		),
+		grpc.ChainStreamInterceptor(
+			requestIDStreamInterceptor(),
+			loggingStreamInterceptor(logger),
+			authStreamInterceptor(conf.SECRET_KEY),
+		),
+	)
+	articlespb.RegisterArticleServiceServer(server, &articleServer{
+		svc:            svc,
+		allowDeleteAll: conf.ALLOW_DELETE_ALL,
+		confirmations:  auth.NewConfirmationStore(conf.SECRET_KEY),
+	})
+	return server
+}
+
+// InitGRPCServer serves the gRPC API on conf.GRPC_PORT. Like InitGinRoutes it
+// blocks until the server stops.
+func InitGRPCServer(svc ports.ArticleService, logger ports.LoggingService, conf appConfig.Config) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", conf.GRPC_PORT))
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		logger.LogError(logEntry)
+		panic(err)
+	}
+
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("gRPC server running on port 0.0.0.0:%s", conf.GRPC_PORT),
+	}
+	logger.LogInfo(logEntry)
+
+	log.Printf("gRPC server running on port 0.0.0.0:%s", conf.GRPC_PORT)
+	if err := NewGRPCServer(svc, logger, conf).Serve(listener); err != nil {
+		panic(err)
+	}
+}
+
+func (s *articleServer) CreateArticle(ctx context.Context, req *articlespb.CreateArticleRequest) (*articlespb.Article, error) {
+	article, err := s.svc.CreateArticle(toDomainArticle(req.GetArticle()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toProtoArticle(article), nil
+}
+
+func (s *articleServer) GetArticleByID(ctx context.Context, req *articlespb.GetArticleByIDRequest) (*articlespb.Article, error) {
+	var article *domain.Article
+	var err error
+	switch req.GetFormat() {
+	case articlespb.ArticleFormat_ARTICLE_FORMAT_UNSPECIFIED, articlespb.ArticleFormat_ARTICLE_FORMAT_MARKDOWN:
+		article, err = s.svc.GetArticleByID(req.GetArticleId())
+	case articlespb.ArticleFormat_ARTICLE_FORMAT_HTML:
+		article, err = s.svc.GetArticleHTML(req.GetArticleId())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "format must be markdown or html")
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticle(article), nil
+}
+
+func (s *articleServer) GetArticleBySlug(ctx context.Context, req *articlespb.GetArticleBySlugRequest) (*articlespb.Article, error) {
+	article, err := s.svc.GetArticleBySlug(req.GetSlug())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticle(article), nil
+}
+
+func (s *articleServer) GetArticles(ctx context.Context, req *articlespb.GetArticlesRequest) (*articlespb.ArticleList, error) {
+	articles, err := s.svc.GetArticles()
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticles(articles), nil
+}
+
+func (s *articleServer) GetArticlesByAuthor(ctx context.Context, req *articlespb.GetArticlesByAuthorRequest) (*articlespb.ArticleList, error) {
+	articles, err := s.svc.GetArticlesByAuthor(req.GetAuthorId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticles(articles), nil
+}
+
+func (s *articleServer) GetArticlesByTag(ctx context.Context, req *articlespb.GetArticlesByTagRequest) (*articlespb.ArticleList, error) {
+	articles, err := s.svc.GetArticlesByTag(req.GetTag())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticles(articles), nil
+}
+
+func (s *articleServer) FindArticles(ctx context.Context, req *articlespb.FindArticlesRequest) (*articlespb.ArticleList, error) {
+	query := toDomainQuery(req)
+	if err := query.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	articles, err := s.svc.FindArticles(query)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toProtoArticles(articles), nil
+}
+
+// requireAuthor only lets the author of the article through. The auth
+// interceptor has already made sure the caller sent a valid token.
+func (s *articleServer) requireAuthor(ctx context.Context, article_id string) error {
+	article, err := s.svc.GetArticleByID(article_id)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if claims, ok := ClaimsFromContext(ctx); !ok || claims.Subject != article.AuthorID {
+		return status.Error(codes.PermissionDenied, "article can only be changed by its author")
+	}
+	return nil
+}
+
+func (s *articleServer) UpdateArticle(ctx context.Context, req *articlespb.UpdateArticleRequest) (*articlespb.Article, error) {
+	if err := s.requireAuthor(ctx, req.GetArticleId()); err != nil {
+		return nil, err
+	}
+	article, err := s.svc.UpdateArticle(req.GetArticleId(), toDomainArticle(req.GetArticle()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toProtoArticle(article), nil
+}
+
+func (s *articleServer) DeleteArticle(ctx context.Context, req *articlespb.DeleteArticleRequest) (*articlespb.DeleteArticleResponse, error) {
+	if err := s.requireAuthor(ctx, req.GetArticleId()); err != nil {
+		return nil, err
+	}
+	if err := s.svc.DeleteArticle(req.GetArticleId()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &articlespb.DeleteArticleResponse{Message: "Article deleted successfully"}, nil
+}
+
+// ConfirmationTokenKey is the metadata key of the confirmation token that
+// DeleteArticleAll hands out in its trailer and expects back in the request,
+// like the X-Confirmation-Token header of the HTTP API.
+const ConfirmationTokenKey = "x-confirmation-token"
+
+// DeleteArticleAll is only reachable with an admin token, enforced by the
+// auth interceptor. It needs two calls by the same admin: the first fails
+// with FailedPrecondition and returns a short lived confirmation token in
+// the trailer, the second must send it back in the request metadata.
+func (s *articleServer) DeleteArticleAll(ctx context.Context, req *articlespb.DeleteArticleAllRequest) (*articlespb.DeleteArticleResponse, error) {
+	if !s.allowDeleteAll {
+		return nil, status.Error(codes.PermissionDenied, "operation disabled in this environment")
+	}
+	subject := ""
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		subject = claims.Subject
+	}
+
+	token := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ConfirmationTokenKey); len(values) > 0 {
+			token = values[0]
+		}
+	}
+	if token == "" {
+		token, expiresAt, err := s.confirmations.Issue(subject)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		grpc.SetTrailer(ctx, metadata.Pairs(
+			ConfirmationTokenKey, token,
+			"x-confirmation-expires-at", expiresAt.UTC().Format(time.RFC3339),
+		))
+		return nil, status.Errorf(codes.FailedPrecondition, "repeat the call with the %s metadata to delete all articles", ConfirmationTokenKey)
+	}
+	if !s.confirmations.Consume(token, subject) {
+		return nil, status.Error(codes.PermissionDenied, "invalid or expired confirmation token")
+	}
+
+	if err := s.svc.DeleteArticleAll(); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &articlespb.DeleteArticleResponse{Message: "Article deleted successfully"}, nil
+}
+
+func (s *articleServer) GetDeletedArticles(ctx context.Context, req *articlespb.GetDeletedArticlesRequest) (*articlespb.ArticleList, error) {
+	articles, err := s.svc.GetDeletedArticles()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toProtoArticles(articles), nil
+}
+
+func (s *articleServer) RestoreArticle(ctx context.Context, req *articlespb.RestoreArticleRequest) (*articlespb.Article, error) {
+	article, err := s.svc.RestoreArticle(req.GetArticleId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProtoArticle(article), nil
+}
+
+// ExportArticles streams the NDJSON written by the service as one message
+// per article.
+func (s *articleServer) ExportArticles(req *articlespb.ExportArticlesRequest, stream articlespb.ArticleService_ExportArticlesServer) error {
+	reader, writer := io.Pipe()
+	// Closing the reader on return unblocks the exporting goroutine
+	defer reader.Close()
+	go func() {
+		writer.CloseWithError(s.svc.ExportArticles(writer))
+	}()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var article domain.Article
+		err := decoder.Decode(&article)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(toProtoArticle(&article)); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportArticles feeds the streamed articles to the service as NDJSON and
+// returns its report once the client closes the stream.
+func (s *articleServer) ImportArticles(stream articlespb.ArticleService_ImportArticlesServer) error {
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	var report *domain.ImportReport
+	var importErr error
+	go func() {
+		defer close(done)
+		report, importErr = s.svc.ImportArticles(reader)
+		// Let a blocked Recv loop notice the import stopped early
+		reader.CloseWithError(importErr)
+	}()
+
+	encoder := json.NewEncoder(writer)
+	for {
+		article, err := stream.Recv()
+		if err == io.EOF {
+			writer.Close()
+			break
+		}
+		if err != nil {
+			writer.CloseWithError(err)
+			<-done
+			return err
+		}
+		// Writing only fails once the import gave up reading, its error is
+		// reported below
+		if err := encoder.Encode(toDomainArticle(article)); err != nil {
+			break
+		}
+	}
+	<-done
+
+	if importErr != nil {
+		return status.Error(codes.Internal, importErr.Error())
+	}
+	return stream.SendAndClose(toProtoImportReport(report))
+}
@@ -0,0 +1,286 @@
+package grpcapp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/auth"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/grpcapp/articlespb"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "testsecret"
+
+type stubArticleService struct {
+	ports.ArticleService
+	articles   map[string]domain.Article
+	deletedAll bool
+	imported   []domain.Article
+}
+
+func (s *stubArticleService) GetArticleByID(article_id string) (*domain.Article, error) {
+	article, ok := s.articles[article_id]
+	if !ok {
+		return nil, errors.New("sql: no rows in result set")
+	}
+	return &article, nil
+}
+
+func (s *stubArticleService) DeleteArticle(article_id string) error {
+	delete(s.articles, article_id)
+	return nil
+}
+
+func (s *stubArticleService) DeleteArticleAll() error {
+	s.deletedAll = true
+	return nil
+}
+
+func (s *stubArticleService) ExportArticles(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, id := range []string{"a1", "a2"} {
+		if err := encoder.Encode(s.articles[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubArticleService) ImportArticles(r io.Reader) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{Errors: []domain.ImportError{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var article domain.Article
+		if err := json.Unmarshal(scanner.Bytes(), &article); err != nil {
+			return report, err
+		}
+		s.imported = append(s.imported, article)
+		report.Imported++
+	}
+	return report, scanner.Err()
+}
+
+type stubLogger struct{}
+
+func (stubLogger) SendLog(domain.LogMessage)    {}
+func (stubLogger) LogDebug(domain.LogMessage)   {}
+func (stubLogger) LogInfo(domain.LogMessage)    {}
+func (stubLogger) LogWarning(domain.LogMessage) {}
+func (stubLogger) LogError(domain.LogMessage)   {}
+
+func signToken(t *testing.T, subject, role string) string {
+	t.Helper()
+	claims := auth.Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// newTestClient serves svc over an in memory connection.
+func newTestClient(t *testing.T, svc ports.ArticleService) articlespb.ArticleServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	conf := appConfig.Config{SECRET_KEY: testSecret, ALLOW_DELETE_ALL: true}
+	server := NewGRPCServer(svc, stubLogger{}, conf)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return articlespb.NewArticleServiceClient(conn)
+}
+
+func TestGetArticleByID(t *testing.T) {
+	svc := &stubArticleService{articles: map[string]domain.Article{
+		"a1": {ArticleID: "a1", Title: "First", Tags: []string{"go"}, PublishDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	client := newTestClient(t, svc)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDKey, "req-1")
+	var header metadata.MD
+	article, err := client.GetArticleByID(ctx, &articlespb.GetArticleByIDRequest{ArticleId: "a1"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.GetTitle() != "First" || !article.GetPublishDate().AsTime().Equal(svc.articles["a1"].PublishDate) {
+		t.Errorf("Unexpected article %v", article)
+	}
+	if got := header.Get(RequestIDKey); len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("Expected request ID to be echoed, got %v", got)
+	}
+
+	_, err = client.GetArticleByID(context.Background(), &articlespb.GetArticleByIDRequest{ArticleId: "missing"}, grpc.Header(&header))
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	if got := header.Get(RequestIDKey); len(got) != 1 || got[0] == "" {
+		t.Errorf("Expected a generated request ID, got %v", got)
+	}
+}
+
+func TestDeleteArticleAllAuth(t *testing.T) {
+	svc := &stubArticleService{}
+	client := newTestClient(t, svc)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"no token", context.Background(), codes.Unauthenticated},
+		{"invalid token", withToken("not-a-jwt"), codes.Unauthenticated},
+		{"not admin", withToken(signToken(t, "author-1", "author")), codes.PermissionDenied},
+		{"admin", withToken(signToken(t, "admin-1", auth.RoleAdmin)), codes.FailedPrecondition},
+	}
+	for _, test := range tests {
+		_, err := client.DeleteArticleAll(test.ctx, &articlespb.DeleteArticleAllRequest{Confirm: true})
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, err)
+		}
+	}
+	if svc.deletedAll {
+		t.Errorf("Expected articles to be kept without a confirmation token")
+	}
+}
+
+func TestDeleteArticleAuthor(t *testing.T) {
+	svc := &stubArticleService{articles: map[string]domain.Article{
+		"a1": {ArticleID: "a1", AuthorID: "author-1"},
+	}}
+	client := newTestClient(t, svc)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		article_id string
+		code       codes.Code
+	}{
+		{"no token", context.Background(), "a1", codes.Unauthenticated},
+		{"other author", withToken(signToken(t, "author-2", "author")), "a1", codes.PermissionDenied},
+		{"missing article", withToken(signToken(t, "author-1", "author")), "missing", codes.NotFound},
+		{"author", withToken(signToken(t, "author-1", "author")), "a1", codes.OK},
+	}
+	for _, test := range tests {
+		_, err := client.DeleteArticle(test.ctx, &articlespb.DeleteArticleRequest{ArticleId: test.article_id})
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, err)
+		}
+	}
+	if _, ok := svc.articles["a1"]; ok {
+		t.Errorf("Expected the author to delete the article")
+	}
+}
+
+func TestDeleteArticleAllConfirmation(t *testing.T) {
+	svc := &stubArticleService{}
+	client := newTestClient(t, svc)
+	admin := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signToken(t, "admin-1", auth.RoleAdmin))
+	other := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signToken(t, "admin-2", auth.RoleAdmin))
+
+	var trailer metadata.MD
+	_, err := client.DeleteArticleAll(admin, &articlespb.DeleteArticleAllRequest{}, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", err)
+	}
+	tokens := trailer.Get(ConfirmationTokenKey)
+	if len(tokens) != 1 || tokens[0] == "" {
+		t.Fatalf("Expected a confirmation token in the trailer, got %v", trailer)
+	}
+	confirm := func(ctx context.Context) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, ConfirmationTokenKey, tokens[0])
+		_, err := client.DeleteArticleAll(ctx, &articlespb.DeleteArticleAllRequest{})
+		return err
+	}
+
+	if err := confirm(other); status.Code(err) != codes.PermissionDenied || svc.deletedAll {
+		t.Errorf("Expected the token of another admin to be rejected, got %v", err)
+	}
+	if err := confirm(admin); err != nil || !svc.deletedAll {
+		t.Fatalf("Expected articles to be deleted with the confirmation token, got %v", err)
+	}
+	if err := confirm(admin); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected the token to be single use, got %v", err)
+	}
+}
+
+func TestExportImportArticles(t *testing.T) {
+	svc := &stubArticleService{articles: map[string]domain.Article{
+		"a1": {ArticleID: "a1", Title: "First"},
+		"a2": {ArticleID: "a2", Title: "Second"},
+	}}
+	client := newTestClient(t, svc)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signToken(t, "admin-1", auth.RoleAdmin))
+
+	export, err := client.ExportArticles(ctx, &articlespb.ExportArticlesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := []*articlespb.Article{}
+	for {
+		article, err := export.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		exported = append(exported, article)
+	}
+	if len(exported) != 2 || exported[1].GetTitle() != "Second" {
+		t.Fatalf("Unexpected export %v", exported)
+	}
+
+	stream, err := client.ImportArticles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, article := range exported {
+		if err := stream.Send(article); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.GetImported() != 2 || len(svc.imported) != 2 || svc.imported[0].ArticleID != "a1" {
+		t.Errorf("Unexpected import report %v of %v", report, svc.imported)
+	}
+}
@@ -0,0 +1,118 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+type imageProcessor struct {
+	jpegQuality int
+}
+
+// NewImageProcessor reads JPEG, PNG, GIF and WebP images and writes JPEG at
+// jpegQuality. There is no WebP encoder in the standard library or
+// golang.org/x/image, so no WebP is written.
+func NewImageProcessor(jpegQuality int) *imageProcessor {
+	return &imageProcessor{jpegQuality: jpegQuality}
+}
+
+func (p *imageProcessor) StripMetadata(content []byte) ([]byte, error) {
+	return stripMetadata(content), nil
+}
+
+func (p *imageProcessor) Dimensions(content []byte) (int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+func (p *imageProcessor) Decode(content []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return orient(img, orientation(content)), nil
+}
+
+func (p *imageProcessor) Resize(img image.Image, width int, height int) image.Image {
+	src := img.Bounds()
+	if height == 0 {
+		height = (src.Dy()*width + src.Dx()/2) / src.Dx()
+		if height < 1 {
+			height = 1
+		}
+	} else if src.Dx()*height > src.Dy()*width {
+		// Wider than the target, the sides are cropped
+		cropped := (src.Dy()*width + height/2) / height
+		src.Min.X += (src.Dx() - cropped) / 2
+		src.Max.X = src.Min.X + cropped
+	} else {
+		cropped := (src.Dx()*height + width/2) / width
+		src.Min.Y += (src.Dy() - cropped) / 2
+		src.Max.Y = src.Min.Y + cropped
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+func (p *imageProcessor) Encode(w io.Writer, img image.Image, format string) error {
+	if format != "jpeg" {
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+	// JPEG has no transparency, transparent parts turn white
+	opaque := image.NewRGBA(img.Bounds())
+	draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, draw.Over)
+	return jpeg.Encode(w, opaque, &jpeg.Options{Quality: p.jpegQuality})
+}
+
+// orient turns img upright according to its EXIF orientation, mirroring it
+// for orientations 2, 4, 5 and 7
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	src := img.Bounds()
+	w, h := src.Dx(), src.Dy()
+	dstWidth, dstHeight := w, h
+	if orientation >= 5 {
+		dstWidth, dstHeight = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(src.Min.X+x, src.Min.Y+y))
+		}
+	}
+	return dst
+}
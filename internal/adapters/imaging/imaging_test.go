@@ -0,0 +1,138 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// jpegWithMetadata is a 4x2 JPEG carrying an orientation, a GPS position
+// and a comment
+func jpegWithMetadata(t *testing.T, orientation uint16) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("II\x2a\x00\x08\x00\x00\x00\x02\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientationTag)
+	tiff = append(tiff, 3, 0, 1, 0, 0, 0)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	// A GPS IFD pointer, with a position that is never read
+	tiff = append(tiff, 0x25, 0x88, 4, 0, 1, 0, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 51.5007N 0.1246W"...)
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+
+	content := []byte{0xff, 0xd8, 0xff, 0xe1}
+	content = binary.BigEndian.AppendUint16(content, uint16(2+len(exif)))
+	content = append(content, exif...)
+	content = append(content, 0xff, 0xfe, 0, 9)
+	content = append(content, "comment"...)
+	return append(content, buf.Bytes()[2:]...)
+}
+
+func TestStripMetadata(t *testing.T) {
+	p := NewImageProcessor(80)
+
+	t.Run("Test JPEG", func(t *testing.T) {
+		content := jpegWithMetadata(t, 6)
+		stripped, err := p.StripMetadata(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(stripped, []byte("GPS")) || bytes.Contains(stripped, []byte("comment")) {
+			t.Errorf("Expected the metadata gone")
+		}
+		if orientation(stripped) != 6 {
+			t.Errorf("Expected the orientation kept, got %d", orientation(stripped))
+		}
+		img, err := p.Decode(stripped)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 4 {
+			t.Errorf("Expected the image turned upright, got %v", img.Bounds())
+		}
+
+		upright, _ := p.StripMetadata(jpegWithMetadata(t, 1))
+		if bytes.Contains(upright, []byte("Exif")) {
+			t.Errorf("Expected no EXIF left for upright images")
+		}
+	})
+
+	t.Run("Test PNG", func(t *testing.T) {
+		var buf bytes.Buffer
+		png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 2)))
+		content := buf.Bytes()
+		// Text chunks go after the header
+		withText := append([]byte{}, content[:33]...)
+		withText = appendPNGChunk(withText, "tEXt", []byte("Author\x00Ada"))
+		withText = appendPNGChunk(withText, "eXIf", orientationTIFF(8))
+		withText = append(withText, content[33:]...)
+
+		stripped, err := p.StripMetadata(withText)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(stripped, []byte("Ada")) || orientation(stripped) != 8 {
+			t.Errorf("Expected only the orientation kept")
+		}
+		img, err := p.Decode(stripped)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if img.Bounds().Dx() != 2 {
+			t.Errorf("Expected the image turned upright, got %v", img.Bounds())
+		}
+	})
+
+	t.Run("Test unknown content", func(t *testing.T) {
+		content := []byte("plain text")
+		if stripped, _ := p.StripMetadata(content); !bytes.Equal(stripped, content) {
+			t.Errorf("Expected content kept as it is")
+		}
+	})
+}
+
+func TestOrient(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{Y: 1})
+	img.SetGray(1, 0, color.Gray{Y: 2})
+
+	// Where the first pixel ends up for each orientation
+	tests := map[int]image.Point{2: {1, 0}, 3: {1, 0}, 4: {0, 0}, 5: {0, 0}, 6: {0, 0}, 7: {0, 1}, 8: {0, 1}}
+	for o, want := range tests {
+		upright := orient(img, o)
+		if r, _, _, _ := upright.At(want.X, want.Y).RGBA(); r>>8 != 1 {
+			t.Errorf("Expected the first pixel at %v for orientation %d", want, o)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	p := NewImageProcessor(80)
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+
+	if got := p.Resize(img, 100, 0).Bounds(); got.Dx() != 100 || got.Dy() != 50 {
+		t.Errorf("Expected the aspect ratio kept, got %v", got)
+	}
+	if got := p.Resize(img, 60, 60).Bounds(); got.Dx() != 60 || got.Dy() != 60 {
+		t.Errorf("Expected a square crop, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := p.Encode(&buf, img, "jpeg"); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := jpeg.Decode(&buf); err != nil {
+		t.Errorf("Expected a JPEG, got %v", err)
+	}
+	if err := p.Encode(&buf, img, "avif"); err == nil {
+		t.Errorf("Expected unknown formats to be refused")
+	}
+}
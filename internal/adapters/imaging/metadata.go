@@ -0,0 +1,310 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// Cameras and phones record where, when and with what a picture was taken
+// in EXIF and XMP metadata. Uploads are stripped of it before they are
+// stored, keeping only the orientation that tells viewers which way is up.
+// The image data is copied as it is, so nothing is lost to re-encoding.
+
+const orientationTag = 0x0112
+
+var (
+	jpegSOI       = []byte{0xff, 0xd8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader    = []byte("Exif\x00\x00")
+	iccHeader     = []byte("ICC_PROFILE\x00")
+	riffHeader    = []byte("RIFF")
+	webpFourCC    = []byte("WEBP")
+	webpExifFlag  = byte(0x08)
+	webpXMPFlag   = byte(0x04)
+	pngMetadata   = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+	webpMetadata  = map[string]bool{"EXIF": true, "XMP ": true}
+	pngCRCTable   = crc32.IEEETable
+	tiffBigEndian = []byte("MM\x00\x2a")
+)
+
+func stripMetadata(content []byte) []byte {
+	switch {
+	case bytes.HasPrefix(content, jpegSOI):
+		if stripped, ok := stripJPEG(content); ok {
+			return stripped
+		}
+	case bytes.HasPrefix(content, pngSignature):
+		if stripped, ok := stripPNG(content); ok {
+			return stripped
+		}
+	case len(content) >= 12 && bytes.HasPrefix(content, riffHeader) && bytes.Equal(content[8:12], webpFourCC):
+		if stripped, ok := stripWebP(content); ok {
+			return stripped
+		}
+	}
+	return content
+}
+
+// orientation reads the EXIF orientation of an encoded image, 1 when it has
+// none
+func orientation(content []byte) int {
+	var tiff []byte
+	switch {
+	case bytes.HasPrefix(content, jpegSOI):
+		jpegSegments(content, func(marker byte, segment []byte) bool {
+			if marker == 0xe1 && bytes.HasPrefix(segment[4:], exifHeader) {
+				tiff = segment[4+len(exifHeader):]
+				return false
+			}
+			return true
+		})
+	case bytes.HasPrefix(content, pngSignature):
+		pngChunks(content, func(chunkType string, chunk []byte) bool {
+			if chunkType == "eXIf" {
+				tiff = chunk[8 : len(chunk)-4]
+				return false
+			}
+			return true
+		})
+	case len(content) >= 12 && bytes.HasPrefix(content, riffHeader) && bytes.Equal(content[8:12], webpFourCC):
+		webpChunks(content, func(fourCC string, chunk []byte) bool {
+			if fourCC == "EXIF" {
+				// Some writers keep the JPEG header in the chunk
+				tiff = bytes.TrimPrefix(chunk[8:], exifHeader)
+				return false
+			}
+			return true
+		})
+	}
+	return tiffOrientation(tiff)
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		// A SHORT value sits in the first two bytes of the value field
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == 3 {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+		}
+	}
+	return 1
+}
+
+// orientationTIFF is the smallest EXIF block recording the orientation
+func orientationTIFF(orientation int) []byte {
+	tiff := append([]byte{}, tiffBigEndian...)
+	tiff = binary.BigEndian.AppendUint32(tiff, 8)
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = binary.BigEndian.AppendUint16(tiff, 0)
+	return binary.BigEndian.AppendUint32(tiff, 0)
+}
+
+// jpegSegments calls fn with the marker and bytes, marker and length
+// included, of each segment before the image data until fn returns false.
+// It reports the offset of the image data, or -1 for malformed content.
+func jpegSegments(content []byte, fn func(marker byte, segment []byte) bool) int {
+	i := len(jpegSOI)
+	for i+4 <= len(content) {
+		if content[i] != 0xff {
+			return -1
+		}
+		marker := content[i+1]
+		if marker == 0xff {
+			// Fill byte
+			i++
+			continue
+		}
+		if marker == 0xda {
+			return i
+		}
+		length := int(binary.BigEndian.Uint16(content[i+2:]))
+		if length < 2 || i+2+length > len(content) {
+			return -1
+		}
+		if !fn(marker, content[i:i+2+length]) {
+			return i
+		}
+		i += 2 + length
+	}
+	return -1
+}
+
+// stripJPEG drops the application segments holding metadata, and comments.
+// The orientation goes after the JFIF header, which must come first.
+func stripJPEG(content []byte) ([]byte, bool) {
+	stripped := append([]byte{}, jpegSOI...)
+	var exif []byte
+	if o := orientation(content); o != 1 {
+		payload := append(append([]byte{}, exifHeader...), orientationTIFF(o)...)
+		exif = append([]byte{0xff, 0xe1}, byte((2+len(payload))>>8), byte(2+len(payload)))
+		exif = append(exif, payload...)
+	}
+	scan := jpegSegments(content, func(marker byte, segment []byte) bool {
+		if marker != 0xe0 && exif != nil {
+			stripped = append(stripped, exif...)
+			exif = nil
+		}
+		if keepJPEGSegment(marker, segment) {
+			stripped = append(stripped, segment...)
+		}
+		return true
+	})
+	if scan < 0 {
+		return nil, false
+	}
+	stripped = append(stripped, exif...)
+	return append(stripped, content[scan:]...), true
+}
+
+// keepJPEGSegment keeps everything but application segments and comments,
+// except the JFIF header, ICC profile and Adobe color transform needed to
+// show the image right
+func keepJPEGSegment(marker byte, segment []byte) bool {
+	switch {
+	case marker == 0xe0, marker == 0xee:
+		return true
+	case marker == 0xe2:
+		return bytes.HasPrefix(segment[4:], iccHeader)
+	case marker > 0xe0 && marker <= 0xef, marker == 0xfe:
+		return false
+	}
+	return true
+}
+
+// pngChunks calls fn with the type and bytes, length and CRC included, of
+// each chunk until fn returns false, reporting whether the chunks were well
+// formed.
+func pngChunks(content []byte, fn func(chunkType string, chunk []byte) bool) bool {
+	i := len(pngSignature)
+	for i < len(content) {
+		if i+12 > len(content) {
+			return false
+		}
+		length := int(binary.BigEndian.Uint32(content[i:]))
+		if length < 0 || i+12+length > len(content) {
+			return false
+		}
+		if !fn(string(content[i+4:i+8]), content[i:i+12+length]) {
+			return true
+		}
+		i += 12 + length
+	}
+	return true
+}
+
+func stripPNG(content []byte) ([]byte, bool) {
+	o := orientation(content)
+	stripped := append([]byte{}, pngSignature...)
+	ok := pngChunks(content, func(chunkType string, chunk []byte) bool {
+		if pngMetadata[chunkType] {
+			return true
+		}
+		// The orientation goes after the header, as eXIf must come before
+		// the image data
+		stripped = append(stripped, chunk...)
+		if chunkType == "IHDR" && o != 1 {
+			stripped = appendPNGChunk(stripped, "eXIf", orientationTIFF(o))
+		}
+		return true
+	})
+	return stripped, ok
+}
+
+func appendPNGChunk(dst []byte, chunkType string, data []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(data)))
+	start := len(dst)
+	dst = append(dst, chunkType...)
+	dst = append(dst, data...)
+	return binary.BigEndian.AppendUint32(dst, crc32.Checksum(dst[start:], pngCRCTable))
+}
+
+// webpChunks calls fn with the FourCC and bytes, header and padding
+// included, of each chunk until fn returns false, reporting whether the
+// chunks were well formed.
+func webpChunks(content []byte, fn func(fourCC string, chunk []byte) bool) bool {
+	i := 12
+	for i < len(content) {
+		if i+8 > len(content) {
+			return false
+		}
+		size := int(binary.LittleEndian.Uint32(content[i+4:]))
+		end := i + 8 + size + size&1
+		if size < 0 || end > len(content) {
+			// The padding of the last chunk is sometimes left out
+			if end == len(content)+1 && size&1 == 1 {
+				end = len(content)
+			} else {
+				return false
+			}
+		}
+		if !fn(string(content[i:i+4]), content[i:end]) {
+			return true
+		}
+		i = end
+	}
+	return true
+}
+
+// stripWebP drops the EXIF and XMP chunks, clearing their flags in the
+// extended header
+func stripWebP(content []byte) ([]byte, bool) {
+	o := orientation(content)
+	stripped := append([]byte{}, content[:12]...)
+	extended := false
+	ok := webpChunks(content, func(fourCC string, chunk []byte) bool {
+		if webpMetadata[fourCC] {
+			return true
+		}
+		if fourCC == "VP8X" && len(chunk) > 8 {
+			extended = true
+			flags := len(stripped) + 8
+			stripped = append(stripped, chunk...)
+			stripped[flags] &^= webpExifFlag | webpXMPFlag
+			if o != 1 {
+				stripped[flags] |= webpExifFlag
+			}
+			return true
+		}
+		stripped = append(stripped, chunk...)
+		return true
+	})
+	if !ok {
+		return nil, false
+	}
+	// Only the extended format can hold the orientation, at the end
+	if extended && o != 1 {
+		tiff := orientationTIFF(o)
+		stripped = append(stripped, "EXIF"...)
+		stripped = binary.LittleEndian.AppendUint32(stripped, uint32(len(tiff)))
+		stripped = append(stripped, tiff...)
+	}
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, true
+}
@@ -0,0 +1,57 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// blockEnd matches the tags that end a block of text in rendered HTML
+var blockEnd = regexp.MustCompile(`</(p|li|h[1-6]|td|th|pre|blockquote)>|<br ?/?>`)
+
+// markdownRenderer renders article bodies as CommonMark with the GitHub
+// flavoured extensions (tables, fenced code, strikethrough, task lists and
+// autolinks). Output is always passed through an HTML sanitizer, so raw HTML
+// or javascript: links in a body can never reach a reader's browser.
+type markdownRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+	strip    *bluemonday.Policy
+}
+
+func NewMarkdownRenderer() *markdownRenderer {
+	policy := bluemonday.UGCPolicy()
+	// Keep the language hint on fenced code blocks for client side
+	// highlighting
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+
+	return &markdownRenderer{
+		markdown: goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		policy:   policy,
+		strip:    bluemonday.StrictPolicy(),
+	}
+}
+
+func (r *markdownRenderer) RenderHTML(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+func (r *markdownRenderer) PlainText(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	// Keep words in adjacent block elements apart once the tags are gone
+	rendered := blockEnd.ReplaceAllString(buf.String(), "$0 ")
+	text := html.UnescapeString(r.strip.Sanitize(rendered))
+	return strings.Join(strings.Fields(text), " "), nil
+}
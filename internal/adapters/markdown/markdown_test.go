@@ -0,0 +1,37 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	renderer := NewMarkdownRenderer()
+
+	t.Run("Test GFM tables and code", func(t *testing.T) {
+		source := "| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(\"hi\")\n```\n"
+		html, err := renderer.RenderHTML(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(html, "<table>") {
+			t.Errorf("Expected a table, got %s", html)
+		}
+		if !strings.Contains(html, `<code class="language-go">`) {
+			t.Errorf("Expected a go code block, got %s", html)
+		}
+	})
+
+	t.Run("Test unsafe content is removed", func(t *testing.T) {
+		source := "<script>alert(1)</script>\n\n[click](javascript:alert(1))\n\n<img src=x onerror=alert(1)>"
+		html, err := renderer.RenderHTML(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, unsafe := range []string{"<script", "javascript:", "onerror"} {
+			if strings.Contains(html, unsafe) {
+				t.Errorf("Expected %q to be sanitized, got %s", unsafe, html)
+			}
+		}
+	})
+}
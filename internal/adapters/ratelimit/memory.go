@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that refilled completely are dropped
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	policy  Policy
+}
+
+// memoryStore keeps buckets in process. Limits apply per instance, so
+// deployments running several replicas should use the Redis store.
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), updated: now}
+		s.buckets[key] = b
+	}
+	b.policy = policy
+	b.tokens = refill(policy, b.tokens, now.Sub(b.updated))
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(policy, b.tokens, allowed), nil
+}
+
+// sweep drops buckets that are full again, as a new bucket would be the same.
+// The caller holds the lock.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if refill(b.policy, b.tokens, now.Sub(b.updated)) >= float64(b.policy.Limit) {
+			delete(s.buckets, key)
+		}
+	}
+}
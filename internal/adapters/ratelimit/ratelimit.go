@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Policy is a token bucket holding up to Limit tokens that refills at Limit
+// tokens per Window. Every request takes one token.
+type Policy struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Enabled reports whether the policy limits anything. A zero limit or window
+// turns it off.
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Window > 0
+}
+
+// String formats the policy as a RateLimit-Policy header value
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(math.Ceil(p.Window.Seconds())))
+}
+
+// perSecond is the refill rate in tokens per second
+func (p Policy) perSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Result describes a bucket after a request took, or failed to take, a
+// token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token is available, zero when the
+	// request was allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets of every client.
+type Store interface {
+	// Take takes a token from the bucket under key, filling a new bucket to
+	// the policy's limit.
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// newResult describes a bucket left with tokens after a request.
+func newResult(policy Policy, tokens float64, allowed bool) Result {
+	rate := policy.perSecond()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Limit) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+// refill adds the tokens earned over elapsed, up to the policy's limit
+func refill(policy Policy, tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(policy.Limit), tokens+elapsed.Seconds()*policy.perSecond())
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testBucket drains a bucket of 3 tokens per minute and waits for it to
+// refill, advancing the store's clock through now.
+func testBucket(t *testing.T, store Store, now *time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	policy := Policy{Limit: 3, Window: time.Minute}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "client", policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("Expected request to be allowed with %d remaining, got %+v", i, result)
+		}
+	}
+
+	result, _ := store.Take(ctx, "client", policy)
+	if result.Allowed || result.RetryAfter.Round(time.Second) != 20*time.Second || result.Reset.Round(time.Second) != time.Minute {
+		t.Fatalf("Expected request to be denied for 20s, got %+v", result)
+	}
+
+	// Other clients have their own bucket
+	if result, _ := store.Take(ctx, "other", policy); !result.Allowed {
+		t.Errorf("Expected another client to be allowed")
+	}
+
+	// One token is back after a third of the window
+	*now = now.Add(20 * time.Second)
+	if result, _ := store.Take(ctx, "client", policy); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Expected a refilled token, got %+v", result)
+	}
+	*now = now.Add(time.Hour)
+	if result, _ := store.Take(ctx, "client", policy); result.Remaining != 2 {
+		t.Errorf("Expected the bucket to refill no further than its limit, got %+v", result)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	testBucket(t, store, &now)
+
+	now = now.Add(time.Hour)
+	store.Take(context.Background(), "client", Policy{Limit: 3, Window: time.Minute})
+	if len(store.buckets) != 1 {
+		t.Errorf("Expected full buckets to be swept, got %d buckets", len(store.buckets))
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	now := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	store := NewRedisStore(client, "ratelimit:")
+	store.now = func() time.Time { return now }
+	testBucket(t, store, &now)
+
+	if !server.Exists("ratelimit:client") || server.TTL("ratelimit:client") <= 0 {
+		t.Errorf("Expected the bucket to be stored with an expiry")
+	}
+}
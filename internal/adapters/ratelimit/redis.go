@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from a bucket in one step, so concurrent
+// requests from several service instances cannot overdraw it. Buckets expire
+// once they would be full again.
+var takeScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local per_ms = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+	tokens = limit
+	updated = now
+end
+
+tokens = math.min(limit, tokens + math.max(0, now - updated) * per_ms)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((limit - tokens) / per_ms) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// redisStore shares buckets between every instance using the same Redis.
+// Bucket state is written with the instance's clock, which should be kept in
+// sync across instances.
+type redisStore struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore keeps buckets in Redis under keys starting with prefix.
+func NewRedisStore(client redis.Scripter, prefix string) *redisStore {
+	return &redisStore{client: client, prefix: prefix, now: time.Now}
+}
+
+func (s *redisStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	perMS := policy.perSecond() / 1000
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		policy.Limit, strconv.FormatFloat(perMS, 'f', -1, 64), s.now().UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := reply[0].(int64)
+	remaining, ok := reply[1].(string)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(policy, tokens, allowed == 1), nil
+}
@@ -1,292 +1,1093 @@
 package dynamodb
 
-// import (
-// 	"errors"
-// 	"fmt"
-// 	"log"
-
-// 	appConfig "github.com/AntonyIS/notelify-articles-service/config"
-// 	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
-// 	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
-// 	"github.com/aws/aws-sdk-go/aws"
-// 	"github.com/aws/aws-sdk-go/aws/credentials"
-// 	"github.com/aws/aws-sdk-go/aws/session"
-// 	"github.com/aws/aws-sdk-go/service/dynamodb"
-// 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-// 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
-// )
-
-// type dynamodbClient struct {
-// 	client    dynamodb.DynamoDB
-// 	tablename string
-// }
-
-// func NewDynamoDBClient(c appConfig.Config, logger ports.Logger) (ports.ArticleRepository, error) {
-// 	// Create AWS credentials
-// 	creds := credentials.NewStaticCredentials(c.AWS_ACCESS_KEY, c.AWS_SECRET_KEY, "")
-
-// 	sess := session.Must(session.NewSession(&aws.Config{
-// 		Region:      aws.String(c.AWS_DEFAULT_REGION),
-// 		Credentials: creds,
-// 	}))
-
-// 	// dynamodb client
-// 	client := *dynamodb.New(sess)
-
-// 	// Create tables
-// 	err := InitTables(c, client)
-
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	return &dynamodbClient{
-// 		client:    *dynamodb.New(sess),
-// 		tablename: c.ContentTable,
-// 	}, nil
-// }
-
-// func (db dynamodbClient) CreateArticle(article *domain.Article) (*domain.Article, error) {
-// 	entityParsed, err := dynamodbattribute.MarshalMap(article)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	input := &dynamodb.PutItemInput{
-// 		Item:      entityParsed,
-// 		TableName: aws.String(db.tablename),
-// 	}
-
-// 	_, err = db.client.PutItem(input)
-
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	return article, nil
-// }
-
-// func (db dynamodbClient) GetArticleByID(article_id string) (*domain.Article, error) {
-// 	result, err := db.client.GetItem(&dynamodb.GetItemInput{
-// 		TableName: aws.String(db.tablename),
-// 		Key: map[string]*dynamodb.AttributeValue{
-// 			"article_id": {
-// 				S: aws.String(article_id),
-// 			},
-// 		},
-// 	})
-// 	if err != nil {
-// 		return &domain.Article{}, err
-// 	}
-// 	if result.Item == nil {
-// 		msg := fmt.Sprintf("Article with id [ %s ] not found", article_id)
-// 		return &domain.Article{}, errors.New(msg)
-// 	}
-// 	var article domain.Article
-// 	err = dynamodbattribute.UnmarshalMap(result.Item, &article)
-// 	if err != nil {
-// 		return &domain.Article{}, err
-// 	}
-
-// 	return &article, nil
-// }
-
-// func (db dynamodbClient) GetArticlesByAuthor(author_id string) (*[]domain.Article, error) {
-// 	articles, err := db.GetArticles()
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	authorArticles := []domain.Article{}
-// 	for _, article := range *articles {
-// 		if article.AuthorID == author_id {
-// 			authorArticles = append(authorArticles, article)
-// 		}
-// 	}
-// 	return &authorArticles, nil
-// }
-
-// func (db dynamodbClient) GetArticlesByTag(tag string) (*[]domain.Article, error) {
-// 	filterExpression := "contains(Tags, :tag)"
-// 	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
-// 		":tag": {
-// 			S: aws.String(tag),
-// 		},
-// 	}
-
-// 	indexName := "TagsIndex" // Replace with your actual GSI name.
-// 	// Specify the query parameters.
-// 	queryInput := &dynamodb.QueryInput{
-// 		TableName:                 aws.String(db.tablename),
-// 		IndexName:                 aws.String(indexName),
-// 		KeyConditionExpression:    aws.String("Tag = :tag"), // Assuming "Tag" is the index partition key.
-// 		FilterExpression:          aws.String(filterExpression),
-// 		ExpressionAttributeValues: expressionAttributeValues,
-// 	}
-
-// 	// Execute the query.
-// 	result, err := db.client.Query(queryInput)
-// 	if err != nil {
-// 		log.Fatalf("Query error: %v", err)
-// 	}
-
-// 	// Process the query results (list of articles matching the tag).
-// 	articles := []domain.Article{}
-// 	for _, item := range result.Items {
-// 		// You can unmarshal the DynamoDB item into your Article struct.
-// 		var article domain.Article
-// 		err := dynamodbattribute.UnmarshalMap(item, &article)
-// 		if err != nil {
-// 			log.Fatalf("Error unmarshaling item: %v", err)
-// 		}
-
-// 		articles = append(articles, article)
-// 	}
-// 	return &articles, nil
-// }
-
-// func (db dynamodbClient) GetArticles() (*[]domain.Article, error) {
-// 	articles := []domain.Article{}
-// 	filt := expression.Name("ArticleID").AttributeNotExists()
-// 	proj := expression.NamesList(
-// 		expression.Name("article_id"),
-// 		expression.Name("title"),
-// 		expression.Name("subtitle"),
-// 		expression.Name("introduction"),
-// 		expression.Name("body"),
-// 		expression.Name("tags"),
-// 		expression.Name("publish_date"),
-// 		expression.Name("author_info"),
-// 	)
-// 	expr, err := expression.NewBuilder().WithFilter(filt).WithProjection(proj).Build()
-// 	if err != nil {
-// 		fmt.Println(err)
-// 		return nil, err
-// 	}
-// 	params := &dynamodb.ScanInput{
-// 		ExpressionAttributeNames:  expr.Names(),
-// 		ExpressionAttributeValues: expr.Values(),
-// 		FilterExpression:          expr.Filter(),
-// 		ProjectionExpression:      expr.Projection(),
-// 		TableName:                 aws.String(db.tablename),
-// 	}
-// 	result, err := db.client.Scan(params)
-
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	for _, item := range result.Items {
-// 		var article domain.Article
-
-// 		err = dynamodbattribute.UnmarshalMap(item, &article)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-
-// 		articles = append(articles, article)
-
-// 	}
-// 	return &articles, nil
-// }
-
-// func (db dynamodbClient) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
-// 	entityParsed, err := dynamodbattribute.MarshalMap(article)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	input := &dynamodb.PutItemInput{
-// 		Item:      entityParsed,
-// 		TableName: aws.String(db.tablename),
-// 	}
-
-// 	_, err = db.client.PutItem(input)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	return db.GetArticleByID(article.ArticleID)
-
-// }
-
-// func (db dynamodbClient) DeleteArticle(article_id string) error {
-// 	input := &dynamodb.DeleteItemInput{
-// 		Key: map[string]*dynamodb.AttributeValue{
-// 			"article_id": {
-// 				S: aws.String(article_id),
-// 			},
-// 		},
-// 		TableName: aws.String(db.tablename),
-// 	}
-
-// 	res, err := db.client.DeleteItem(input)
-// 	if res == nil {
-// 		return err
-// 	}
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return nil
-// }
-
-// func (db dynamodbClient) DeleteArticleAll() error {
-// 	articles, err := db.GetArticles()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	for _, article := range *articles {
-// 		db.DeleteArticle(article.ArticleID)
-// 	}
-// 	return nil
-// }
-
-// func InitTables(c appConfig.Config, client dynamodb.DynamoDB) error {
-// 	keySchema := []*dynamodb.KeySchemaElement{
-// 		{
-// 			AttributeName: aws.String("article_id"),
-// 			KeyType:       aws.String("HASH"), // HASH indicates the partition key
-// 		},
-// 	}
-
-// 	// Define attribute definitions (including "tags" as a String)
-// 	attributeDefinitions := []*dynamodb.AttributeDefinition{
-// 		{
-// 			AttributeName: aws.String("article_id"),
-// 			AttributeType: aws.String("S"), // S indicates String
-// 		},
-// 	}
-
-// 	// Define the provisioned throughput for the table
-// 	provisionedThroughput := &dynamodb.ProvisionedThroughput{
-// 		ReadCapacityUnits:  aws.Int64(5), // Adjust as needed
-// 		WriteCapacityUnits: aws.Int64(5), // Adjust as needed
-// 	}
-
-// 	tableInput := &dynamodb.DescribeTableInput{
-// 		TableName: &c.ContentTable,
-// 	}
-// 	// Create table if does not exist
-// 	_, err := client.DescribeTable(tableInput)
-
-// 	if err != nil {
-// 		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
-// 			// Create the table input with the GSI
-// 			createTableInput := &dynamodb.CreateTableInput{
-// 				TableName:             aws.String(c.ContentTable),
-// 				KeySchema:             keySchema,
-// 				AttributeDefinitions:  attributeDefinitions,
-// 				ProvisionedThroughput: provisionedThroughput,
-// 			}
-
-// 			// Create the DynamoDB table with the GSI
-// 			_, err = client.CreateTable(createTableInput)
-// 			if err != nil {
-// 				fmt.Println("Error creating table:", err)
-// 				return err
-// 			}
-// 		}
-// 	}
-
-// 	return nil
-// }
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// The table holds three kinds of items, told apart by their keys:
+//
+//	pk ARTICLE#<article_id>  sk ARTICLE      the article
+//	pk ARTICLE#<article_id>  sk TAG#<tag>    one per tag, indexed by tag
+//	pk SLUG#<slug>           sk SLUG         the article owning a current or previous slug
+//
+// Articles are indexed by author_id. Articles outside the trash also carry
+// a listed attribute and a sort key for each field they can be sorted by,
+// which put them in sparse indexes read in place of a scan of the whole
+// table. Articles in the trash carry a trashed attribute and the time they
+// were trashed at instead. Like every index they are eventually consistent,
+// so listings may miss writes made a moment before.
+const (
+	articlePrefix = "ARTICLE#"
+	articleSort   = "ARTICLE"
+	tagPrefix     = "TAG#"
+	slugPrefix    = "SLUG#"
+	slugSort      = "SLUG"
+
+	authorIndex = "author_id-index"
+	tagIndex    = "tag-index"
+	listedIndex = "listed-index"
+	trashIndex  = "trashed-index"
+
+	// sortTimeLayout writes times in UTC at a fixed width, so that they sort
+	// as strings in time order
+	sortTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+	// batchGetLimit is the most keys BatchGetItem accepts at once
+	batchGetLimit = 100
+
+	// writeAttempts is how many times a write is tried when the article
+	// changes between its read and its write
+	writeAttempts = 10
+
+	// batchGetAttempts is how many times BatchGetItem is called for keys it
+	// leaves unprocessed, waiting batchGetBackoff after the first call and
+	// twice as long after each further one, up to maxBatchGetBackoff
+	batchGetAttempts   = 10
+	batchGetBackoff    = 50 * time.Millisecond
+	maxBatchGetBackoff = 5 * time.Second
+)
+
+// sortIndexes name the index of listed articles sorted by each field, and
+// the attribute holding their sort key
+var sortIndexes = map[string]struct{ name, attribute string }{
+	"publish_date": {"publish_date-index", "publish_date_key"},
+	"updated_date": {"updated_date-index", "updated_date_key"},
+	"title":        {"title-index", "title_key"},
+}
+
+// ErrNotFound is returned for articles that do not exist or are in the trash
+var ErrNotFound = errors.New("article not found")
+
+// errStopListing ends listArticles early without failing it
+var errStopListing = errors.New("stop listing")
+
+// articleItem is the stored form of an article
+type articleItem struct {
+	PK string `json:"pk"`
+	SK string `json:"sk"`
+	domain.Article
+	// PreviousSlugs are released when the article is purged
+	PreviousSlugs []string `json:"previous_slugs,omitempty"`
+	// Version counts the writes of the article. Every write is conditional
+	// on the version read before it, so concurrent writes are not lost.
+	Version int64 `json:"version"`
+}
+
+type tagItem struct {
+	PK  string `json:"pk"`
+	SK  string `json:"sk"`
+	Tag string `json:"tag"`
+}
+
+type slugItem struct {
+	PK        string `json:"pk"`
+	SK        string `json:"sk"`
+	ArticleID string `json:"article_id"`
+}
+
+// dynamodbClient stores articles in a single DynamoDB table. Unlike the
+// Postgres client it keeps no outbox or webhook subscriptions, so no events
+// are published for writes made through it.
+type dynamodbClient struct {
+	client    dynamodbiface.DynamoDBAPI
+	tablename string
+	encoder   *dynamodbattribute.Encoder
+	sleep     func(time.Duration)
+}
+
+// NewDynamoDBClient connects to DynamoDB in DYNAMODB_REGION, or to
+// DYNAMODB_ENDPOINT such as DynamoDB Local, and creates ARTICLE_TABLE when it
+// does not exist. Credentials come from the usual AWS environment variables,
+// shared configuration or instance role.
+func NewDynamoDBClient(conf appConfig.Config) (*dynamodbClient, error) {
+	awsConf := aws.NewConfig().WithRegion(conf.DYNAMODB_REGION)
+	if conf.DYNAMODB_ENDPOINT != "" {
+		awsConf = awsConf.WithEndpoint(conf.DYNAMODB_ENDPOINT)
+	}
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, err
+	}
+
+	db := newClient(dynamodb.New(sess), conf.ARTICLE_TABLE)
+	if err := db.bootstrap(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func newClient(client dynamodbiface.DynamoDBAPI, tablename string) *dynamodbClient {
+	return &dynamodbClient{
+		client:    client,
+		tablename: tablename,
+		// Empty strings are valid attribute values, keep them apart from
+		// missing ones
+		encoder: dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+			e.NullEmptyString = false
+		}),
+		sleep: time.Sleep,
+	}
+}
+
+// bootstrap creates the table and its indexes unless they exist, and waits
+// until the table is usable.
+func (db *dynamodbClient) bootstrap() error {
+	_, err := db.client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(db.tablename)})
+	if err == nil {
+		return nil
+	}
+	if !isCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+		return err
+	}
+
+	attribute := func(name string) *dynamodb.AttributeDefinition {
+		return &dynamodb.AttributeDefinition{AttributeName: aws.String(name), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)}
+	}
+	key := func(name, keyType string) *dynamodb.KeySchemaElement {
+		return &dynamodb.KeySchemaElement{AttributeName: aws.String(name), KeyType: aws.String(keyType)}
+	}
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(db.tablename),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			attribute("pk"), attribute("sk"), attribute("author_id"), attribute("tag"), attribute("listed"),
+			attribute("trashed"), attribute("trashed_at"),
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			key("pk", dynamodb.KeyTypeHash), key("sk", dynamodb.KeyTypeRange),
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName:  aws.String(authorIndex),
+				KeySchema:  []*dynamodb.KeySchemaElement{key("author_id", dynamodb.KeyTypeHash), key("pk", dynamodb.KeyTypeRange)},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+			{
+				IndexName:  aws.String(tagIndex),
+				KeySchema:  []*dynamodb.KeySchemaElement{key("tag", dynamodb.KeyTypeHash), key("pk", dynamodb.KeyTypeRange)},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+			},
+			{
+				IndexName:  aws.String(listedIndex),
+				KeySchema:  []*dynamodb.KeySchemaElement{key("listed", dynamodb.KeyTypeHash), key("pk", dynamodb.KeyTypeRange)},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+			{
+				IndexName:  aws.String(trashIndex),
+				KeySchema:  []*dynamodb.KeySchemaElement{key("trashed", dynamodb.KeyTypeHash), key("trashed_at", dynamodb.KeyTypeRange)},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+		},
+	}
+	for _, field := range []string{"publish_date", "updated_date", "title"} {
+		index := sortIndexes[field]
+		input.AttributeDefinitions = append(input.AttributeDefinitions, attribute(index.attribute))
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.name),
+			KeySchema:  []*dynamodb.KeySchemaElement{key("listed", dynamodb.KeyTypeHash), key(index.attribute, dynamodb.KeyTypeRange)},
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+		})
+	}
+	_, err = db.client.CreateTable(input)
+	// Another instance may be creating the table at the same time
+	if err != nil && !isCode(err, dynamodb.ErrCodeResourceInUseException) {
+		return err
+	}
+	return db.client.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: aws.String(db.tablename)})
+}
+
+func (db *dynamodbClient) CreateArticle(article *domain.Article) (*domain.Article, error) {
+	writes, err := db.articleWrites(nil, article)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.transactArticle(writes, article.Slug); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+func (db *dynamodbClient) GetArticleByID(article_id string) (*domain.Article, error) {
+	item, err := db.getArticle(article_id)
+	if err != nil {
+		return nil, err
+	}
+	if item.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &item.Article, nil
+}
+
+func (db *dynamodbClient) GetArticleBySlug(slug string) (*domain.Article, error) {
+	owner, err := db.slugOwner(slug)
+	if err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		return nil, ErrNotFound
+	}
+	return db.GetArticleByID(owner)
+}
+
+func (db *dynamodbClient) IsSlugTaken(slug string, article_id string) (bool, error) {
+	owner, err := db.slugOwner(slug)
+	return owner != "" && owner != article_id, err
+}
+
+func (db *dynamodbClient) GetArticles() (*[]domain.Article, error) {
+	articles := []domain.Article{}
+	err := db.listArticles("", func(item *articleItem) error {
+		articles = append(articles, item.Article)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &articles, nil
+}
+
+func (db *dynamodbClient) GetArticlesByAuthor(author_id string) (*[]domain.Article, error) {
+	articles := []domain.Article{}
+	err := db.query(authorIndex, "author_id", author_id, func(item map[string]*dynamodb.AttributeValue) error {
+		var article articleItem
+		if err := dynamodbattribute.UnmarshalMap(item, &article); err != nil {
+			return err
+		}
+		if article.DeletedAt == nil {
+			articles = append(articles, article.Article)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &articles, nil
+}
+
+func (db *dynamodbClient) GetArticlesByTag(tag string) (*[]domain.Article, error) {
+	article_ids := []string{}
+	err := db.query(tagIndex, "tag", tag, func(item map[string]*dynamodb.AttributeValue) error {
+		article_ids = append(article_ids, strings.TrimPrefix(aws.StringValue(item["pk"].S), articlePrefix))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := db.batchGetArticles(article_ids)
+	if err != nil {
+		return nil, err
+	}
+	articles := []domain.Article{}
+	for _, item := range items {
+		if item.DeletedAt == nil {
+			articles = append(articles, item.Article)
+		}
+	}
+	return &articles, nil
+}
+
+// FindArticles narrows the articles down through the author index when the
+// query names an author, sorting them here, and otherwise reads the index
+// sorted by the sort field from the cursor on until the page is full. The
+// remaining filters are applied here, as DynamoDB charges filtered reads the
+// same as unfiltered ones.
+func (db *dynamodbClient) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	// The cursor as an article with only the sort key and ID set
+	after := domain.Article{ArticleID: query.After.ArticleID}
+	if query.After.ArticleID != "" {
+		key, err := query.AfterKey()
+		if err != nil {
+			return nil, err
+		}
+		switch key := key.(type) {
+		case time.Time:
+			after.PublishDate, after.UpdatedDate = key, key
+		case string:
+			after.Title = key
+		}
+	}
+	if query.AuthorID != "" {
+		return db.findAuthorArticles(query, after)
+	}
+
+	field, desc := query.SortField()
+	index := sortIndexes[field]
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(db.tablename),
+		IndexName:                aws.String(index.name),
+		KeyConditionExpression:   aws.String("#k = :v"),
+		ExpressionAttributeNames: map[string]*string{"#k": aws.String("listed")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {S: aws.String(articleSort)},
+		},
+		ScanIndexForward: aws.Bool(!desc),
+	}
+	if query.Limit > 0 {
+		// One more article tells that the articles sharing the sort key of
+		// the last one were all read
+		input.Limit = aws.Int64(int64(query.Limit) + 1)
+	}
+	if after.ArticleID != "" {
+		// The index sorts ties by ID in the direction of the sort, the
+		// articles sharing the sort key of the cursor are all read when
+		// descending and left out by ID below
+		seek := "#k = :v AND #s > :after"
+		from := sortKey(field, &after)
+		if desc {
+			seek, from = "#k = :v AND #s < :after", sortValue(field, &after)+"\x01"
+		}
+		input.KeyConditionExpression = aws.String(seek)
+		input.ExpressionAttributeNames["#s"] = aws.String(index.attribute)
+		input.ExpressionAttributeValues[":after"] = &dynamodb.AttributeValue{S: aws.String(from)}
+	}
+
+	now := time.Now()
+	articles := []domain.Article{}
+	tied, tiedValue := []domain.Article{}, ""
+	// flush adds the articles sharing a sort key in ID order
+	flush := func() {
+		sort.Slice(tied, func(i, j int) bool { return tied[i].ArticleID < tied[j].ArticleID })
+		for _, article := range tied {
+			if after.ArticleID != "" && tiedValue == sortValue(field, &after) && article.ArticleID <= after.ArticleID {
+				continue
+			}
+			if !matches(article, query, now) {
+				continue
+			}
+			if query.Summary {
+				article.Body = ""
+			}
+			articles = append(articles, article)
+		}
+		tied = tied[:0]
+	}
+	err := db.queryPages(input, func(raw map[string]*dynamodb.AttributeValue) error {
+		var item articleItem
+		if err := dynamodbattribute.UnmarshalMap(raw, &item); err != nil {
+			return err
+		}
+		value := sortValue(field, &item.Article)
+		if len(tied) > 0 && value != tiedValue {
+			flush()
+			if query.Limit > 0 && len(articles) >= query.Limit {
+				return errStopListing
+			}
+		}
+		tied, tiedValue = append(tied, item.Article), value
+		return nil
+	})
+	if err != nil && err != errStopListing {
+		return nil, err
+	}
+	flush()
+	if query.Limit > 0 && len(articles) > query.Limit {
+		articles = articles[:query.Limit]
+	}
+	return &articles, nil
+}
+
+// findAuthorArticles sorts the articles of the author of query, read
+// through the author index, and returns those after the cursor after.
+func (db *dynamodbClient) findAuthorArticles(query domain.ArticleQuery, after domain.Article) (*[]domain.Article, error) {
+	all, err := db.GetArticlesByAuthor(query.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	articles := []domain.Article{}
+	for _, article := range *all {
+		if !matches(article, query, now) {
+			continue
+		}
+		if query.Summary {
+			article.Body = ""
+		}
+		articles = append(articles, article)
+	}
+
+	field, desc := query.SortField()
+	less := func(a, b domain.Article) bool {
+		cmp := strings.Compare(sortValue(field, &a), sortValue(field, &b))
+		if desc {
+			cmp = -cmp
+		}
+		if cmp == 0 {
+			return a.ArticleID < b.ArticleID
+		}
+		return cmp < 0
+	}
+	sort.SliceStable(articles, func(i, j int) bool { return less(articles[i], articles[j]) })
+
+	if after.ArticleID != "" {
+		start := sort.Search(len(articles), func(i int) bool { return less(after, articles[i]) })
+		articles = articles[start:]
+	}
+	if query.Limit > 0 && len(articles) > query.Limit {
+		articles = articles[:query.Limit]
+	}
+	return &articles, nil
+}
+
+// sortValue returns field of article as a string sorting like the field
+func sortValue(field string, article *domain.Article) string {
+	switch field {
+	case "publish_date":
+		return article.PublishDate.UTC().Format(sortTimeLayout)
+	case "updated_date":
+		return article.UpdatedDate.UTC().Format(sortTimeLayout)
+	}
+	return article.Title
+}
+
+// sortKey returns the key article is sorted by in the index of field: the
+// value of the field and the article ID, so that ties sort by ID and titles
+// never make an empty key
+func sortKey(field string, article *domain.Article) string {
+	return sortValue(field, article) + "\x00" + article.ArticleID
+}
+
+// matches reports whether article passes the filters of query, tags being
+// compared regardless of case.
+func matches(article domain.Article, query domain.ArticleQuery, now time.Time) bool {
+	if query.AuthorID != "" && article.AuthorID != query.AuthorID {
+		return false
+	}
+	if query.Tag != "" {
+		tagged := false
+		for _, tag := range article.Tags {
+			tagged = tagged || strings.EqualFold(tag, query.Tag)
+		}
+		if !tagged {
+			return false
+		}
+	}
+	if !query.From.IsZero() && article.PublishDate.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && article.PublishDate.After(query.To) {
+		return false
+	}
+	switch query.Status {
+	case domain.ArticleStatusPublished:
+		return article.IsPublished(now)
+	case domain.ArticleStatusScheduled:
+		return !article.IsPublished(now)
+	}
+	return true
+}
+
+func (db *dynamodbClient) GetAuthors(author_ids []string) (map[string]domain.Author, error) {
+	authors := map[string]domain.Author{}
+	for _, author_id := range author_ids {
+		articles, err := db.GetArticlesByAuthor(author_id)
+		if err != nil {
+			return nil, err
+		}
+		// Articles carry a copy of the author profile, the latest write wins
+		var latest *domain.Article
+		for i, article := range *articles {
+			if latest == nil || article.UpdatedDate.After(latest.UpdatedDate) {
+				latest = &(*articles)[i]
+			}
+		}
+		if latest != nil {
+			authors[author_id] = latest.Author
+		}
+	}
+	return authors, nil
+}
+
+func (db *dynamodbClient) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
+	var res domain.Article
+	err := retry(func() error {
+		before, err := db.getArticle(article_id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return ErrNotFound
+		}
+
+		res = before.Article.ApplyUpdate(article)
+		writes, err := db.articleWrites(before, &res)
+		if err != nil {
+			return err
+		}
+		return db.transactArticle(writes, res.Slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (db *dynamodbClient) DeleteArticle(article_id string) error {
+	return db.trash(article_id, time.Now().UTC())
+}
+
+// DeleteArticleAll trashes the articles one at a time. Articles trashed
+// before a failure stay in the trash.
+func (db *dynamodbClient) DeleteArticleAll() error {
+	article_ids := []string{}
+	err := db.listArticles("", func(item *articleItem) error {
+		article_ids = append(article_ids, item.ArticleID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(article_ids) == 0 {
+		return errors.New("no Articles to delete")
+	}
+
+	now := time.Now().UTC()
+	for _, article_id := range article_ids {
+		// The index may list articles trashed a moment before
+		if err := db.trash(article_id, now); err != nil && err != ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// trash moves an article outside the trash into it
+func (db *dynamodbClient) trash(article_id string, now time.Time) error {
+	return retry(func() error {
+		item, err := db.getArticle(article_id)
+		if err != nil {
+			return err
+		}
+		if item.DeletedAt != nil {
+			return ErrNotFound
+		}
+		item.DeletedAt = &now
+		return db.putArticle(item)
+	})
+}
+
+// GetDeletedArticles reads the trash index, latest trashed first
+func (db *dynamodbClient) GetDeletedArticles() (*[]domain.Article, error) {
+	articles := []domain.Article{}
+	err := db.listTrash(time.Time{}, func(item *articleItem) error {
+		articles = append(articles, item.Article)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &articles, nil
+}
+
+func (db *dynamodbClient) RestoreArticle(article_id string) (*domain.Article, error) {
+	var item *articleItem
+	err := retry(func() error {
+		var err error
+		item, err = db.getArticle(article_id)
+		if err != nil {
+			return err
+		}
+		if item.DeletedAt == nil {
+			return ErrNotFound
+		}
+		item.DeletedAt = nil
+		return db.putArticle(item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item.Article, nil
+}
+
+func (db *dynamodbClient) PurgeDeletedArticles(before time.Time) (int64, []domain.Media, error) {
+	items := []*articleItem{}
+	err := db.listTrash(before, func(item *articleItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var count int64
+	for _, item := range items {
+		// Articles restored or changed since they were listed are left alone
+		remove := deleteItem(db.tablename, item.PK, articleSort)
+		remove.Delete.ConditionExpression = aws.String("version = :version")
+		remove.Delete.ExpressionAttributeValues = versionValue(item.Version)
+		deletes := []*dynamodb.TransactWriteItem{remove}
+		for _, tag := range uniqueTags(item.Tags) {
+			deletes = append(deletes, deleteItem(db.tablename, item.PK, tagPrefix+tag))
+		}
+		for _, slug := range append([]string{item.Slug}, item.PreviousSlugs...) {
+			if slug != "" {
+				deletes = append(deletes, deleteItem(db.tablename, slugPrefix+slug, slugSort))
+			}
+		}
+		err := db.transact(deletes)
+		if conflicted(err) {
+			continue
+		}
+		if err != nil {
+			return count, nil, err
+		}
+		count++
+	}
+	return count, nil, nil
+}
+
+func (db *dynamodbClient) StreamArticles(fn func(article *domain.Article) error) error {
+	return db.listArticles("", func(item *articleItem) error {
+		return fn(&item.Article)
+	})
+}
+
+// UpsertArticles writes every article in a transaction of its own, as a
+// DynamoDB transaction is limited to 100 items.
+func (db *dynamodbClient) UpsertArticles(articles []domain.Article) ([]error, error) {
+	results := make([]error, len(articles))
+	for i := range articles {
+		var readErr error
+		results[i] = retry(func() error {
+			before, err := db.getArticle(articles[i].ArticleID)
+			if err == ErrNotFound {
+				before, err = nil, nil
+			}
+			if err != nil {
+				readErr = err
+				return err
+			}
+			writes, err := db.articleWrites(before, &articles[i])
+			if err != nil {
+				return err
+			}
+			return db.transactArticle(writes, articles[i].Slug)
+		})
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	return results, nil
+}
+
+func (db *dynamodbClient) CountPublishedArticles() (int, error) {
+	count := 0
+	now := time.Now()
+	err := db.listArticles("", func(item *articleItem) error {
+		if item.IsPublished(now) {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// GetSitemapPage seeks the listed index, which is sorted by article ID, past
+// after and stops reading once the page is full.
+func (db *dynamodbClient) GetSitemapPage(after string, offset, limit int) ([]domain.SitemapEntry, error) {
+	entries := []domain.SitemapEntry{}
+	now := time.Now()
+	err := db.listArticles(after, func(item *articleItem) error {
+		if !item.IsPublished(now) {
+			return nil
+		}
+		if offset > 0 {
+			offset--
+			return nil
+		}
+		if len(entries) == limit {
+			return errStopListing
+		}
+		entries = append(entries, domain.SitemapEntry{
+			ArticleID:   item.ArticleID,
+			Slug:        item.Slug,
+			UpdatedDate: item.UpdatedDate,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// getArticle reads an article whether or not it is in the trash
+func (db *dynamodbClient) getArticle(article_id string) (*articleItem, error) {
+	result, err := db.client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(db.tablename),
+		Key:            key(articlePrefix+article_id, articleSort),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+	var item articleItem
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// slugOwner returns the ID of the article that uses or used slug, or an
+// empty string when no article did.
+func (db *dynamodbClient) slugOwner(slug string) (string, error) {
+	result, err := db.client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(db.tablename),
+		Key:            key(slugPrefix+slug, slugSort),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || result.Item == nil {
+		return "", err
+	}
+	var item slugItem
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &item); err != nil {
+		return "", err
+	}
+	return item.ArticleID, nil
+}
+
+// articleWrites returns the writes replacing before, nil for a new article,
+// with article. The article itself is always the first write, conditional
+// on the article not existing yet or being still at the version of before.
+func (db *dynamodbClient) articleWrites(before *articleItem, article *domain.Article) ([]*dynamodb.TransactWriteItem, error) {
+	item := &articleItem{PK: articlePrefix + article.ArticleID, SK: articleSort, Article: *article, Version: 1}
+	oldTags := []string{}
+	if before != nil {
+		item.Version = before.Version + 1
+		item.PreviousSlugs = before.PreviousSlugs
+		if before.Slug != "" && before.Slug != article.Slug {
+			item.PreviousSlugs = append(item.PreviousSlugs, before.Slug)
+		}
+		oldTags = uniqueTags(before.Tags)
+	}
+	// The new slug may be one this article used before
+	previous := []string{}
+	for _, slug := range item.PreviousSlugs {
+		if slug != article.Slug {
+			previous = append(previous, slug)
+		}
+	}
+	item.PreviousSlugs = previous
+
+	put, err := db.marshalArticle(item)
+	if err != nil {
+		return nil, err
+	}
+	put.ConditionExpression = aws.String("attribute_not_exists(pk)")
+	if before != nil {
+		put.ConditionExpression = aws.String("version = :version")
+		put.ExpressionAttributeValues = versionValue(before.Version)
+	}
+	writes := []*dynamodb.TransactWriteItem{{Put: put}}
+
+	newTags := uniqueTags(article.Tags)
+	for _, tag := range oldTags {
+		if !contains(newTags, tag) {
+			writes = append(writes, deleteItem(db.tablename, item.PK, tagPrefix+tag))
+		}
+	}
+	for _, tag := range newTags {
+		if contains(oldTags, tag) {
+			continue
+		}
+		av, err := db.encoder.Encode(tagItem{PK: item.PK, SK: tagPrefix + tag, Tag: tag})
+		if err != nil {
+			return nil, err
+		}
+		writes = append(writes, &dynamodb.TransactWriteItem{Put: &dynamodb.Put{TableName: aws.String(db.tablename), Item: av.M}})
+	}
+
+	if article.Slug != "" && (before == nil || before.Slug != article.Slug) {
+		av, err := db.encoder.Encode(slugItem{PK: slugPrefix + article.Slug, SK: slugSort, ArticleID: article.ArticleID})
+		if err != nil {
+			return nil, err
+		}
+		// A slug, current or previous, belongs to one article only
+		writes = append(writes, &dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+			TableName:           aws.String(db.tablename),
+			Item:                av.M,
+			ConditionExpression: aws.String("attribute_not_exists(pk) OR article_id = :article_id"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":article_id": {S: aws.String(article.ArticleID)},
+			},
+		}})
+	}
+	return writes, nil
+}
+
+func (db *dynamodbClient) marshalArticle(item *articleItem) (*dynamodb.Put, error) {
+	av, err := db.encoder.Encode(item)
+	if err != nil {
+		return nil, err
+	}
+	// Index keys must not be empty, articles without an author stay out of
+	// the author index
+	if item.AuthorID == "" {
+		delete(av.M, "author_id")
+	}
+	if item.DeletedAt == nil {
+		av.M["listed"] = &dynamodb.AttributeValue{S: aws.String(articleSort)}
+		for field, index := range sortIndexes {
+			av.M[index.attribute] = &dynamodb.AttributeValue{S: aws.String(sortKey(field, &item.Article))}
+		}
+	} else {
+		av.M["trashed"] = &dynamodb.AttributeValue{S: aws.String(articleSort)}
+		av.M["trashed_at"] = &dynamodb.AttributeValue{S: aws.String(item.DeletedAt.UTC().Format(sortTimeLayout))}
+	}
+	return &dynamodb.Put{TableName: aws.String(db.tablename), Item: av.M}, nil
+}
+
+// putArticle replaces an article, still at the version item was read at,
+// without touching its tags or slugs
+func (db *dynamodbClient) putArticle(item *articleItem) error {
+	read := item.Version
+	item.Version++
+	put, err := db.marshalArticle(item)
+	if err != nil {
+		return err
+	}
+	_, err = db.client.PutItem(&dynamodb.PutItemInput{
+		TableName:                 put.TableName,
+		Item:                      put.Item,
+		ConditionExpression:       aws.String("version = :version"),
+		ExpressionAttributeValues: versionValue(read),
+	})
+	return err
+}
+
+func versionValue(version int64) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{":version": {N: aws.String(strconv.FormatInt(version, 10))}}
+}
+
+// retry runs write again while it fails because the article changed between
+// its read and its write, writeAttempts times at most
+func retry(write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if attempt == writeAttempts || !conflicted(err) {
+			return err
+		}
+	}
+}
+
+// conflicted reports whether err is the failure of the condition on the
+// article, which is the first write of a transaction
+func conflicted(err error) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		reasons := canceled.CancellationReasons
+		return len(reasons) > 0 && aws.StringValue(reasons[0].Code) == "ConditionalCheckFailed"
+	}
+	return isCode(err, dynamodb.ErrCodeConditionalCheckFailedException)
+}
+
+func (db *dynamodbClient) transact(writes []*dynamodb.TransactWriteItem) error {
+	_, err := db.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	return err
+}
+
+// transactArticle runs the writes of articleWrites. Unless the article
+// itself changed, which is left to retry, a failed condition on the slug
+// means that slug belongs to another article and is returned as
+// domain.ErrSlugTaken.
+func (db *dynamodbClient) transactArticle(writes []*dynamodb.TransactWriteItem, slug string) error {
+	err := db.transact(writes)
+	var canceled *dynamodb.TransactionCanceledException
+	if conflicted(err) || !errors.As(err, &canceled) {
+		return err
+	}
+	for i, reason := range canceled.CancellationReasons {
+		if i >= len(writes) || writes[i].Put == nil || aws.StringValue(reason.Code) != "ConditionalCheckFailed" {
+			continue
+		}
+		if strings.HasPrefix(aws.StringValue(writes[i].Put.Item["pk"].S), slugPrefix) {
+			return fmt.Errorf("%w: %s", domain.ErrSlugTaken, slug)
+		}
+	}
+	return err
+}
+
+// listTrash calls fn for the articles in the trash, latest trashed first,
+// reading the trash index a page at a time. A non zero before leaves out the
+// articles trashed at or after it.
+func (db *dynamodbClient) listTrash(before time.Time, fn func(item *articleItem) error) error {
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(db.tablename),
+		IndexName:                aws.String(trashIndex),
+		KeyConditionExpression:   aws.String("#k = :v"),
+		ExpressionAttributeNames: map[string]*string{"#k": aws.String("trashed")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {S: aws.String(articleSort)},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if !before.IsZero() {
+		input.KeyConditionExpression = aws.String("#k = :v AND trashed_at < :before")
+		input.ExpressionAttributeValues[":before"] = &dynamodb.AttributeValue{S: aws.String(before.UTC().Format(sortTimeLayout))}
+	}
+	return db.queryPages(input, func(raw map[string]*dynamodb.AttributeValue) error {
+		var item articleItem
+		if err := dynamodbattribute.UnmarshalMap(raw, &item); err != nil {
+			return err
+		}
+		return fn(&item)
+	})
+}
+
+// listArticles calls fn for the articles outside the trash whose ID sorts
+// after after, in article ID order, reading the listed index a page at a
+// time. fn returns errStopListing to stop reading early.
+func (db *dynamodbClient) listArticles(after string, fn func(item *articleItem) error) error {
+	err := db.queryPages(&dynamodb.QueryInput{
+		TableName:                aws.String(db.tablename),
+		IndexName:                aws.String(listedIndex),
+		KeyConditionExpression:   aws.String("#k = :v AND pk > :after"),
+		ExpressionAttributeNames: map[string]*string{"#k": aws.String("listed")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v":     {S: aws.String(articleSort)},
+			":after": {S: aws.String(articlePrefix + after)},
+		},
+	}, func(raw map[string]*dynamodb.AttributeValue) error {
+		var item articleItem
+		if err := dynamodbattribute.UnmarshalMap(raw, &item); err != nil {
+			return err
+		}
+		return fn(&item)
+	})
+	if err == errStopListing {
+		return nil
+	}
+	return err
+}
+
+// query calls fn for every item of index whose attribute name equals value,
+// reading a page at a time. Indexes are eventually consistent.
+func (db *dynamodbClient) query(index, name, value string, fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	return db.queryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(db.tablename),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    aws.String("#k = :v"),
+		ExpressionAttributeNames:  map[string]*string{"#k": aws.String(name)},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":v": {S: aws.String(value)}},
+	}, fn)
+}
+
+func (db *dynamodbClient) queryPages(input *dynamodb.QueryInput, fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	for {
+		result, err := db.client.Query(input)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// batchGetArticles reads the articles with the given IDs, in batches of the
+// most keys DynamoDB accepts. Keys DynamoDB leaves unprocessed, when it is
+// throttled, are retried with exponential backoff batchGetAttempts times at
+// most.
+func (db *dynamodbClient) batchGetArticles(article_ids []string) ([]*articleItem, error) {
+	items := []*articleItem{}
+	for start := 0; start < len(article_ids); start += batchGetLimit {
+		end := start + batchGetLimit
+		if end > len(article_ids) {
+			end = len(article_ids)
+		}
+		keys := []map[string]*dynamodb.AttributeValue{}
+		for _, article_id := range article_ids[start:end] {
+			keys = append(keys, key(articlePrefix+article_id, articleSort))
+		}
+
+		request := map[string]*dynamodb.KeysAndAttributes{db.tablename: {Keys: keys}}
+		for attempt := 1; len(request) > 0; attempt++ {
+			if attempt > batchGetAttempts {
+				return nil, fmt.Errorf("articles left unprocessed after %d attempts", batchGetAttempts)
+			}
+			if attempt > 1 {
+				wait := batchGetBackoff << (attempt - 2)
+				if wait > maxBatchGetBackoff {
+					wait = maxBatchGetBackoff
+				}
+				db.sleep(wait)
+			}
+			result, err := db.client.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, err
+			}
+			for _, raw := range result.Responses[db.tablename] {
+				var item articleItem
+				if err := dynamodbattribute.UnmarshalMap(raw, &item); err != nil {
+					return nil, err
+				}
+				items = append(items, &item)
+			}
+			request = result.UnprocessedKeys
+		}
+	}
+	return items, nil
+}
+
+func key(pk, sk string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"pk": {S: aws.String(pk)},
+		"sk": {S: aws.String(sk)},
+	}
+}
+
+func deleteItem(tablename, pk, sk string) *dynamodb.TransactWriteItem {
+	return &dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{TableName: aws.String(tablename), Key: key(pk, sk)}}
+}
+
+// uniqueTags leaves out empty and repeated tags, which would make a
+// transaction write the same item twice
+func uniqueTags(tags []string) []string {
+	unique := []string{}
+	for _, tag := range tags {
+		if tag != "" && !contains(unique, tag) {
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isCode(err error, code string) bool {
+	var aerr interface{ Code() string }
+	return errors.As(err, &aerr) && aerr.Code() == code
+}
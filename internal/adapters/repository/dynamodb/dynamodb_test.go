@@ -0,0 +1,647 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/repotest"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamoDB keeps a table in memory. It understands the key conditions
+// and attribute_exists conditions the client uses, and returns at most
+// pageSize items per scan or query so that paging is exercised. Calls are
+// serialised by mu, scans counts the Scan calls and read the items returned
+// by queries. BatchGetItem leaves the last key unprocessed while throttled
+// is above zero, counting it down. A beforeTransact hook
+// runs once, ahead of the next transaction, to interleave another write.
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	mu             sync.Mutex
+	items          map[string]map[string]*dynamodb.AttributeValue
+	created        *dynamodb.CreateTableInput
+	pageSize       int
+	scans          int
+	read           int
+	throttled      int
+	beforeTransact func()
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}, pageSize: 2}
+}
+
+func itemKey(item map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(item["pk"].S) + "|" + aws.StringValue(item["sk"].S)
+}
+
+func (f *fakeDynamoDB) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.created == nil {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found", nil)
+	}
+	return &dynamodb.DescribeTableOutput{}, nil
+}
+
+func (f *fakeDynamoDB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = input
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (f *fakeDynamoDB) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
+	return nil
+}
+
+func (f *fakeDynamoDB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[itemKey(input.Key)]}, nil
+}
+
+// check evaluates conditions made of attribute_exists, attribute_not_exists
+// and equality with a value, joined by either AND or OR
+func (f *fakeDynamoDB) check(condition *string, values map[string]*dynamodb.AttributeValue, key string) error {
+	if condition == nil {
+		return nil
+	}
+	item := f.items[key]
+	holds := func(part string) (bool, error) {
+		var name string
+		if _, err := fmt.Sscanf(part, "attribute_not_exists(%s", &name); err == nil {
+			_, ok := item[strings.TrimSuffix(name, ")")]
+			return !ok, nil
+		}
+		if _, err := fmt.Sscanf(part, "attribute_exists(%s", &name); err == nil {
+			_, ok := item[strings.TrimSuffix(name, ")")]
+			return ok, nil
+		}
+		if name, value, ok := strings.Cut(part, " = "); ok && values[value] != nil {
+			return item[name] != nil && item[name].String() == values[value].String(), nil
+		}
+		return false, fmt.Errorf("unsupported condition %s", part)
+	}
+
+	operator, either := " AND ", false
+	if strings.Contains(*condition, " OR ") {
+		operator, either = " OR ", true
+	}
+	for _, part := range strings.Split(*condition, operator) {
+		ok, err := holds(part)
+		if err != nil {
+			return err
+		}
+		if ok == either {
+			if either {
+				return nil
+			}
+			return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+		}
+	}
+	if either {
+		return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	return nil
+}
+
+func (f *fakeDynamoDB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check(input.ConditionExpression, input.ExpressionAttributeValues, itemKey(input.Item)); err != nil {
+		return nil, err
+	}
+	f.items[itemKey(input.Item)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	if hook := f.beforeTransact; hook != nil {
+		f.beforeTransact = nil
+		hook()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seen := map[string]bool{}
+	reasons := []*dynamodb.CancellationReason{}
+	canceled := false
+	for _, write := range input.TransactItems {
+		var err error
+		key := ""
+		if write.Put != nil {
+			key = itemKey(write.Put.Item)
+			err = f.check(write.Put.ConditionExpression, write.Put.ExpressionAttributeValues, key)
+		} else {
+			key = itemKey(write.Delete.Key)
+			err = f.check(write.Delete.ConditionExpression, write.Delete.ExpressionAttributeValues, key)
+		}
+		if seen[key] {
+			return nil, errors.New("transaction writes the same item twice")
+		}
+		seen[key] = true
+
+		code := "None"
+		if isCode(err, dynamodb.ErrCodeConditionalCheckFailedException) {
+			code, canceled = "ConditionalCheckFailed", true
+		} else if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String(code)})
+	}
+	if canceled {
+		return nil, &dynamodb.TransactionCanceledException{Message_: aws.String("condition failed"), CancellationReasons: reasons}
+	}
+	for _, write := range input.TransactItems {
+		if write.Put != nil {
+			f.items[itemKey(write.Put.Item)] = write.Put.Item
+		} else {
+			delete(f.items, itemKey(write.Delete.Key))
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *fakeDynamoDB) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	responses := map[string][]map[string]*dynamodb.AttributeValue{}
+	unprocessed := map[string]*dynamodb.KeysAndAttributes{}
+	for table, request := range input.RequestItems {
+		keys := request.Keys
+		if f.throttled > 0 && len(keys) > 0 {
+			f.throttled--
+			unprocessed[table] = &dynamodb.KeysAndAttributes{Keys: keys[len(keys)-1:]}
+			keys = keys[:len(keys)-1]
+		}
+		for _, key := range keys {
+			if item, ok := f.items[itemKey(key)]; ok {
+				responses[table] = append(responses[table], item)
+			}
+		}
+	}
+	return &dynamodb.BatchGetItemOutput{Responses: responses, UnprocessedKeys: unprocessed}, nil
+}
+
+// page returns the items after start in key order, at most pageSize of them
+func (f *fakeDynamoDB) page(match func(item map[string]*dynamodb.AttributeValue) bool, start map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, map[string]*dynamodb.AttributeValue) {
+	keys := []string{}
+	for key, item := range f.items {
+		if match(item) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	items := []map[string]*dynamodb.AttributeValue{}
+	for _, key := range keys {
+		if start != nil && key <= itemKey(start) {
+			continue
+		}
+		if len(items) == f.pageSize {
+			last := items[len(items)-1]
+			return items, map[string]*dynamodb.AttributeValue{"pk": last["pk"], "sk": last["sk"]}
+		}
+		items = append(items, f.items[key])
+	}
+	return items, nil
+}
+
+func (f *fakeDynamoDB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scans++
+	items, last := f.page(func(map[string]*dynamodb.AttributeValue) bool { return true }, input.ExclusiveStartKey)
+	return &dynamodb.ScanOutput{Items: items, LastEvaluatedKey: last}, nil
+}
+
+// Query reads an index in the order of its range key, then of the table
+// key, for key conditions comparing the hash key with = and optionally the
+// range key with > or <.
+func (f *fakeDynamoDB) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var schema []*dynamodb.KeySchemaElement
+	for _, index := range f.created.GlobalSecondaryIndexes {
+		if aws.StringValue(index.IndexName) == aws.StringValue(input.IndexName) {
+			schema = index.KeySchema
+		}
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("unknown index %s", aws.StringValue(input.IndexName))
+	}
+	hashKey, rangeKey := aws.StringValue(schema[0].AttributeName), aws.StringValue(schema[1].AttributeName)
+
+	conditions := []func(item map[string]*dynamodb.AttributeValue) bool{}
+	for i, part := range strings.Split(aws.StringValue(input.KeyConditionExpression), " AND ") {
+		fields := strings.Fields(part)
+		if len(fields) != 3 || input.ExpressionAttributeValues[fields[2]] == nil {
+			return nil, fmt.Errorf("unsupported key condition %s", part)
+		}
+		name, operator, value := fields[0], fields[1], aws.StringValue(input.ExpressionAttributeValues[fields[2]].S)
+		if alias, ok := input.ExpressionAttributeNames[name]; ok {
+			name = aws.StringValue(alias)
+		}
+		if !(i == 0 && name == hashKey && operator == "=") && !(i == 1 && name == rangeKey && (operator == ">" || operator == "<")) {
+			return nil, fmt.Errorf("unsupported key condition %s", part)
+		}
+		conditions = append(conditions, func(item map[string]*dynamodb.AttributeValue) bool {
+			if item[name] == nil {
+				return false
+			}
+			switch operator {
+			case ">":
+				return aws.StringValue(item[name].S) > value
+			case "<":
+				return aws.StringValue(item[name].S) < value
+			}
+			return aws.StringValue(item[name].S) == value
+		})
+	}
+
+	items := []map[string]*dynamodb.AttributeValue{}
+	for _, item := range f.items {
+		matched := item[rangeKey] != nil
+		for _, condition := range conditions {
+			matched = matched && condition(item)
+		}
+		if matched {
+			items = append(items, item)
+		}
+	}
+	forward := input.ScanIndexForward == nil || *input.ScanIndexForward
+	sort.Slice(items, func(i, j int) bool {
+		a, b := aws.StringValue(items[i][rangeKey].S), aws.StringValue(items[j][rangeKey].S)
+		if a == b {
+			a, b = itemKey(items[i]), itemKey(items[j])
+		}
+		return (a < b) == forward
+	})
+	if start := input.ExclusiveStartKey; start != nil {
+		for i, item := range items {
+			if itemKey(item) == itemKey(start) {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+
+	size := f.pageSize
+	if input.Limit != nil && int(*input.Limit) < size {
+		size = int(*input.Limit)
+	}
+	output := &dynamodb.QueryOutput{Items: items}
+	if len(items) > size {
+		last := items[size-1]
+		output.Items = items[:size]
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"pk": last["pk"], "sk": last["sk"]}
+	}
+	f.read += len(output.Items)
+	return output, nil
+}
+
+func newTestClient(t *testing.T) (*dynamodbClient, *fakeDynamoDB) {
+	fake := newFakeDynamoDB()
+	db := newClient(fake, "Articles")
+	if err := db.bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	return db, fake
+}
+
+func newArticle(id, author_id, slug string, tags ...string) *domain.Article {
+	return &domain.Article{
+		ArticleID:   id,
+		Title:       "Article " + id,
+		Body:        "Body of " + id,
+		Slug:        slug,
+		Tags:        tags,
+		AuthorID:    author_id,
+		Author:      domain.Author{AuthorID: author_id, Firstname: "Ada"},
+		PublishDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func ids(articles *[]domain.Article) []string {
+	res := []string{}
+	for _, article := range *articles {
+		res = append(res, article.ArticleID)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestBootstrapCreatesIndexes(t *testing.T) {
+	_, fake := newTestClient(t)
+	if fake.created == nil {
+		t.Fatal("Expected the table to be created")
+	}
+	indexes := []string{}
+	for _, index := range fake.created.GlobalSecondaryIndexes {
+		indexes = append(indexes, aws.StringValue(index.IndexName))
+	}
+	want := []string{authorIndex, tagIndex, listedIndex, trashIndex, "publish_date-index", "updated_date-index", "title-index"}
+	if strings.Join(indexes, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected indexes %v", indexes)
+	}
+}
+
+func TestCreateAndGetArticle(t *testing.T) {
+	db, _ := newTestClient(t)
+	article := newArticle("1", "a", "first", "go", "aws")
+	if _, err := db.CreateArticle(article); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateArticle(article); err == nil {
+		t.Errorf("Expected creating an existing article to fail")
+	}
+
+	res, err := db.GetArticleByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Title != article.Title || res.Author.Firstname != "Ada" || !res.PublishDate.Equal(article.PublishDate) || len(res.Tags) != 2 {
+		t.Errorf("Unexpected article %+v", res)
+	}
+	if _, err := db.GetArticleByID("2"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if res, err := db.GetArticleBySlug("first"); err != nil || res.ArticleID != "1" {
+		t.Errorf("GetArticleBySlug = %v, %v", res, err)
+	}
+}
+
+func TestIndexedLookupsPage(t *testing.T) {
+	db, fake := newTestClient(t)
+	for i := 1; i <= 5; i++ {
+		author := "a"
+		if i > 3 {
+			author = "b"
+		}
+		if _, err := db.CreateArticle(newArticle(fmt.Sprint(i), author, fmt.Sprint("slug-", i), "go")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := db.GetArticles()
+	if err != nil || len(*all) != 5 {
+		t.Fatalf("GetArticles = %v, %v", all, err)
+	}
+	byAuthor, err := db.GetArticlesByAuthor("a")
+	if err != nil || strings.Join(ids(byAuthor), ",") != "1,2,3" {
+		t.Errorf("GetArticlesByAuthor = %v, %v", byAuthor, err)
+	}
+	byTag, err := db.GetArticlesByTag("go")
+	if err != nil || len(*byTag) != 5 {
+		t.Errorf("GetArticlesByTag = %v, %v", byTag, err)
+	}
+
+	if err := db.DeleteArticle("5"); err != nil {
+		t.Fatal(err)
+	}
+	page, err := db.GetSitemapPage("1", 1, 2)
+	if err != nil || len(page) != 2 || page[0].ArticleID != "3" || page[1].ArticleID != "4" {
+		t.Errorf("GetSitemapPage = %v, %v", page, err)
+	}
+	if page, err := db.GetSitemapPage("3", 0, 2); err != nil || len(page) != 1 || page[0].ArticleID != "4" {
+		t.Errorf("Expected trashed articles to be left out of the sitemap, got %v, %v", page, err)
+	}
+	if count, err := db.CountPublishedArticles(); err != nil || count != 4 {
+		t.Errorf("CountPublishedArticles = %d, %v", count, err)
+	}
+	if fake.scans != 0 {
+		t.Errorf("Expected articles outside the trash to be read without a scan, got %d scans", fake.scans)
+	}
+}
+
+func TestUpdateMovesTagsAndSlugs(t *testing.T) {
+	db, fake := newTestClient(t)
+	if _, err := db.CreateArticle(newArticle("1", "a", "old", "go", "aws")); err != nil {
+		t.Fatal(err)
+	}
+	update := newArticle("1", "", "new", "go", "dynamodb")
+	update.Title = "Renamed"
+	res, err := db.UpdateArticle("1", update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Title != "Renamed" || res.Slug != "new" || res.AuthorID != "a" {
+		t.Errorf("Unexpected update %+v", res)
+	}
+
+	if byTag, _ := db.GetArticlesByTag("aws"); len(*byTag) != 0 {
+		t.Errorf("Expected the removed tag to be unindexed")
+	}
+	if byTag, _ := db.GetArticlesByTag("dynamodb"); len(*byTag) != 1 {
+		t.Errorf("Expected the added tag to be indexed")
+	}
+	for _, slug := range []string{"old", "new"} {
+		if res, err := db.GetArticleBySlug(slug); err != nil || res.Slug != "new" {
+			t.Errorf("GetArticleBySlug(%s) = %v, %v", slug, res, err)
+		}
+	}
+	if taken, _ := db.IsSlugTaken("old", "2"); !taken {
+		t.Errorf("Expected the previous slug to stay reserved")
+	}
+
+	if err := db.DeleteArticle("1"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, err := db.PurgeDeletedArticles(time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("PurgeDeletedArticles = %d, %v", n, err)
+	}
+	if len(fake.items) != 0 {
+		t.Errorf("Expected purging to remove every item, left %d", len(fake.items))
+	}
+}
+
+func TestUpdateToTakenSlug(t *testing.T) {
+	db, _ := newTestClient(t)
+	for _, id := range []string{"1", "2"} {
+		if _, err := db.CreateArticle(newArticle(id, "a", "slug-"+id, "go")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.CreateArticle(newArticle("3", "a", "slug-1")); !errors.Is(err, domain.ErrSlugTaken) {
+		t.Errorf("Expected ErrSlugTaken creating an article, got %v", err)
+	}
+	if _, err := db.UpdateArticle("2", newArticle("2", "", "slug-1", "rust")); !errors.Is(err, domain.ErrSlugTaken) {
+		t.Errorf("Expected ErrSlugTaken updating an article, got %v", err)
+	}
+	if res, err := db.GetArticleByID("2"); err != nil || res.Slug != "slug-2" || res.Tags[0] != "go" {
+		t.Errorf("Expected the article left unchanged, got %+v, %v", res, err)
+	}
+}
+
+func TestBatchGetBacksOff(t *testing.T) {
+	db, fake := newTestClient(t)
+	for _, id := range []string{"1", "2", "3"} {
+		if _, err := db.CreateArticle(newArticle(id, "a", "slug-"+id, "go")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waits := []time.Duration{}
+	db.sleep = func(wait time.Duration) { waits = append(waits, wait) }
+
+	fake.throttled = 3
+	if byTag, err := db.GetArticlesByTag("go"); err != nil || len(*byTag) != 3 {
+		t.Errorf("GetArticlesByTag = %v, %v", byTag, err)
+	}
+	if fmt.Sprint(waits) != "[50ms 100ms 200ms]" {
+		t.Errorf("Expected waits doubling between attempts, got %v", waits)
+	}
+
+	fake.throttled = batchGetAttempts
+	if _, err := db.GetArticlesByTag("go"); err == nil {
+		t.Errorf("Expected keys left unprocessed after every attempt to fail the read")
+	}
+}
+
+func TestUpdateRetriesStaleWrites(t *testing.T) {
+	db, fake := newTestClient(t)
+	if _, err := db.CreateArticle(newArticle("1", "a", "one", "go")); err != nil {
+		t.Fatal(err)
+	}
+	// Another update lands between the read and the write of this one
+	fake.beforeTransact = func() {
+		if _, err := db.UpdateArticle("1", newArticle("1", "", "one", "rust")); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := db.UpdateArticle("1", newArticle("1", "", "one", "aws")); err != nil {
+		t.Fatal(err)
+	}
+
+	for tag, want := range map[string]int{"go": 0, "rust": 0, "aws": 1} {
+		if byTag, _ := db.GetArticlesByTag(tag); len(*byTag) != want {
+			t.Errorf("Expected %d articles tagged %s, got %d", want, tag, len(*byTag))
+		}
+	}
+	if item, err := db.getArticle("1"); err != nil || item.Version != 3 {
+		t.Errorf("Expected the article at version 3, got %v, %v", item, err)
+	}
+
+	// A purge leaves alone articles restored since it read them
+	if err := db.DeleteArticle("1"); err != nil {
+		t.Fatal(err)
+	}
+	fake.beforeTransact = func() {
+		if _, err := db.RestoreArticle("1"); err != nil {
+			t.Error(err)
+		}
+	}
+	if n, _, err := db.PurgeDeletedArticles(time.Now().Add(time.Minute)); err != nil || n != 0 {
+		t.Errorf("PurgeDeletedArticles = %d, %v", n, err)
+	}
+	if _, err := db.GetArticleByID("1"); err != nil {
+		t.Errorf("Expected the restored article to stay, got %v", err)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	db, fake := newTestClient(t)
+	for _, id := range []string{"1", "2"} {
+		if _, err := db.CreateArticle(newArticle(id, "a", "slug-"+id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.DeleteArticle("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetArticleByID("1"); err != ErrNotFound {
+		t.Errorf("Expected trashed articles to be hidden, got %v", err)
+	}
+	if byAuthor, _ := db.GetArticlesByAuthor("a"); strings.Join(ids(byAuthor), ",") != "2" {
+		t.Errorf("Expected trashed articles to be left out, got %v", ids(byAuthor))
+	}
+	if deleted, _ := db.GetDeletedArticles(); len(*deleted) != 1 {
+		t.Errorf("Expected one trashed article")
+	}
+	if n, _, err := db.PurgeDeletedArticles(time.Now().Add(-time.Minute)); err != nil || n != 0 {
+		t.Errorf("Expected articles trashed after the cutoff to stay, got %d, %v", n, err)
+	}
+	if _, err := db.RestoreArticle("1"); err != nil {
+		t.Fatal(err)
+	}
+	if fake.scans != 0 {
+		t.Errorf("Expected the trash to be read without a scan, got %d scans", fake.scans)
+	}
+
+	if err := db.DeleteArticleAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteArticleAll(); err == nil {
+		t.Errorf("Expected an error with no articles left to delete")
+	}
+}
+
+func TestFindArticles(t *testing.T) {
+	db, _ := newTestClient(t)
+	future := newArticle("3", "b", "future", "Go")
+	future.PublishDate = time.Now().Add(time.Hour)
+	for _, article := range []*domain.Article{newArticle("1", "a", "one", "go"), newArticle("2", "a", "two", "rust"), future} {
+		if _, err := db.CreateArticle(article); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := db.FindArticles(domain.ArticleQuery{Tag: "GO", Sort: "-publish_date", Summary: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*res) != 2 || (*res)[0].ArticleID != "3" || (*res)[1].Body != "" {
+		t.Errorf("Unexpected articles %+v", *res)
+	}
+	res, _ = db.FindArticles(domain.ArticleQuery{AuthorID: "a", Status: domain.ArticleStatusPublished, Sort: "title"})
+	if strings.Join(ids(res), ",") != "1,2" {
+		t.Errorf("Unexpected articles %v", ids(res))
+	}
+	if _, err := db.FindArticles(domain.ArticleQuery{Sort: "body"}); err == nil {
+		t.Errorf("Expected an invalid sort field to be rejected")
+	}
+}
+
+func TestFindArticlesReadsPages(t *testing.T) {
+	db, fake := newTestClient(t)
+	for i := 10; i <= 20; i++ {
+		article := newArticle(fmt.Sprint(i), "a", fmt.Sprint("slug-", i))
+		article.PublishDate = article.PublishDate.AddDate(0, 0, i)
+		if _, err := db.CreateArticle(article); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := domain.ArticleQuery{Limit: 2}
+	res, err := db.FindArticles(query)
+	if err != nil || strings.Join(ids(res), ",") != "19,20" {
+		t.Fatalf("FindArticles = %v, %v", res, err)
+	}
+	query.After = query.CursorOf((*res)[1])
+	res, err = db.FindArticles(query)
+	if err != nil || strings.Join(ids(res), ",") != "17,18" {
+		t.Fatalf("FindArticles after %+v = %v, %v", query.After, res, err)
+	}
+	// Each page reads up to an article past its end, two at a time
+	if fake.read != 8 || fake.scans != 0 {
+		t.Errorf("Expected two pages read from the index, got %d items and %d scans", fake.read, fake.scans)
+	}
+}
+
+func TestContract(t *testing.T) {
+	repotest.RunArticleRepository(t, func(t *testing.T) ports.ArticleRepository {
+		db, _ := newTestClient(t)
+		return db
+	})
+}
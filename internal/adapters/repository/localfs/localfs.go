@@ -0,0 +1,83 @@
+package localfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// localStore keeps blobs as files below dir, a key's slashes separating
+// directories.
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore keeps blobs below dir, creating it when missing.
+func NewLocalStore(dir string) (*localStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &localStore{dir: dir}, nil
+}
+
+// path returns the file of key. Keys must already be clean, so that none
+// reaches outside dir.
+func (store *localStore) path(key string) (string, error) {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(store.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes content to a temporary file first, so that readers never see a
+// partly written blob.
+func (store *localStore) Put(key string, content_type string, content io.ReadSeeker) error {
+	file, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func (store *localStore) Get(key string) (io.ReadCloser, error) {
+	file, err := store.path(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+	}
+	return os.Open(file)
+}
+
+// Delete succeeds for keys that are not stored
+func (store *localStore) Delete(key string) error {
+	file, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
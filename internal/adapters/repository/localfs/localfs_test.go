@@ -0,0 +1,64 @@
+package localfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "media"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("articles/1/a.png", "image/png", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put("articles/1/a.png", "image/png", strings.NewReader("second")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	blob, err := store.Get("articles/1/a.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	content, _ := io.ReadAll(blob)
+	blob.Close()
+	if string(content) != "second" {
+		t.Errorf("Expected the latest content, got %q", content)
+	}
+
+	if err := store.Delete("articles/1/a.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete("articles/1/a.png"); err != nil {
+		t.Errorf("Expected deleting a missing blob to succeed, got %v", err)
+	}
+	if _, err := store.Get("articles/1/a.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "media", "articles", "1"))
+	if len(entries) != 0 {
+		t.Errorf("Expected no temporary files left, got %v", entries)
+	}
+}
+
+func TestKeysStayInsideDir(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../secret", "articles/../../secret", "/etc/passwd", "articles//a.png"} {
+		if err := store.Put(key, "image/png", strings.NewReader("x")); err == nil {
+			t.Errorf("Expected key %q to be refused", key)
+		}
+		if _, err := store.Get(key); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected key %q to be missing, got %v", key, err)
+		}
+	}
+}
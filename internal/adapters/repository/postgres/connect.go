@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// maxConnectBackoff caps the wait between two connection attempts
+const maxConnectBackoff = 30 * time.Second
+
+// open connects to the database described by conf, sizes its connection
+// pool and waits until the server answers.
+func open(conf appConfig.Config, logger ports.LoggingService) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn(conf))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(conf.POSTGRES_MAX_OPEN_CONNS)
+	db.SetMaxIdleConns(conf.POSTGRES_MAX_IDLE_CONNS)
+	db.SetConnMaxLifetime(conf.POSTGRES_CONN_MAX_LIFETIME)
+	db.SetConnMaxIdleTime(conf.POSTGRES_CONN_MAX_IDLE_TIME)
+
+	err = retry(conf.POSTGRES_CONNECT_ATTEMPTS, conf.POSTGRES_CONNECT_BACKOFF, db.Ping, time.Sleep, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// dsn is the libpq connection string for conf. Optional settings are left
+// out when empty so the driver defaults apply.
+func dsn(conf appConfig.Config) string {
+	params := map[string]string{
+		"host":             conf.POSTGRES_HOST,
+		"port":             conf.POSTGRES_PORT,
+		"user":             conf.POSTGRES_USER,
+		"dbname":           conf.POSTGRES_DB,
+		"password":         conf.POSTGRES_PASSWORD,
+		"sslmode":          conf.POSTGRES_SSLMODE,
+		"sslrootcert":      conf.POSTGRES_SSLROOTCERT,
+		"application_name": conf.POSTGRES_APPLICATION_NAME,
+	}
+	// Unknown keys are sent to the server as run time parameters
+	if conf.POSTGRES_STATEMENT_TIMEOUT > 0 {
+		params["statement_timeout"] = strconv.FormatInt(conf.POSTGRES_STATEMENT_TIMEOUT.Milliseconds(), 10)
+	}
+
+	keys := make([]string, 0, len(params))
+	for key, value := range params {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + quote(params[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// quote wraps value in single quotes, escaping backslashes and quotes, so
+// passwords with spaces or quotes survive the connection string.
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// retry calls ping up to attempts times, sleeping backoff after the first
+// failure and twice as long after each further one, up to
+// maxConnectBackoff.
+func retry(attempts int, backoff time.Duration, ping func() error, sleep func(time.Duration), logger ports.LoggingService) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = ping(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  fmt.Sprintf("connecting to postgres failed (attempt %d of %d), retrying in %s: %v", attempt, attempts, backoff, err),
+		}
+		logger.LogWarning(logEntry)
+		sleep(backoff)
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+	return fmt.Errorf("connecting to postgres failed after %d attempts: %w", attempts, err)
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+)
+
+func TestDSN(t *testing.T) {
+	conf := appConfig.Config{
+		POSTGRES_HOST:              "db.internal",
+		POSTGRES_PORT:              "5432",
+		POSTGRES_USER:              "articles",
+		POSTGRES_DB:                "notelify",
+		POSTGRES_PASSWORD:          `it's a \secret`,
+		POSTGRES_SSLMODE:           "verify-full",
+		POSTGRES_APPLICATION_NAME:  "notelify-articles-service",
+		POSTGRES_STATEMENT_TIMEOUT: 1500 * time.Millisecond,
+	}
+	want := `application_name='notelify-articles-service' dbname='notelify' host='db.internal' ` +
+		`password='it\'s a \\secret' port='5432' sslmode='verify-full' statement_timeout='1500' user='articles'`
+	if got := dsn(conf); got != want {
+		t.Errorf("dsn = %s, want %s", got, want)
+	}
+
+	conf.POSTGRES_SSLROOTCERT = "/etc/ssl/rds.pem"
+	conf.POSTGRES_STATEMENT_TIMEOUT = 0
+	want = `application_name='notelify-articles-service' dbname='notelify' host='db.internal' ` +
+		`password='it\'s a \\secret' port='5432' sslmode='verify-full' sslrootcert='/etc/ssl/rds.pem' user='articles'`
+	if got := dsn(conf); got != want {
+		t.Errorf("dsn = %s, want %s", got, want)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	down := errors.New("connection refused")
+
+	slept := []time.Duration{}
+	calls := 0
+	err := retry(4, 10*time.Second, func() error {
+		calls++
+		if calls < 4 {
+			return down
+		}
+		return nil
+	}, func(d time.Duration) { slept = append(slept, d) }, stubLogger{})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if want := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}; !reflect.DeepEqual(slept, want) {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+
+	slept = slept[:0]
+	err = retry(2, time.Second, func() error { return down }, func(d time.Duration) { slept = append(slept, d) }, stubLogger{})
+	if !errors.Is(err, down) {
+		t.Errorf("retry = %v, want %v", err, down)
+	}
+	if len(slept) != 1 {
+		t.Errorf("slept %d times, want 1", len(slept))
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/repotest"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// TestContract needs a database. It runs when ARTICLES_CONTRACT_POSTGRES is
+// set, connecting with the configuration loaded from the environment, and
+// drops the tables it creates.
+func TestContract(t *testing.T) {
+	if os.Getenv("ARTICLES_CONTRACT_POSTGRES") == "" {
+		t.Skip("ARTICLES_CONTRACT_POSTGRES is not set")
+	}
+	conf, err := appConfig.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRepository := func(t *testing.T) *postgresDBClient {
+		testConf := *conf
+		testConf.ARTICLE_TABLE = fmt.Sprintf("contract_%d", time.Now().UnixNano())
+		psql, err := NewPostgresClient(testConf, stubLogger{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			rows, err := psql.db.Query(`SELECT tablename FROM pg_tables WHERE tablename LIKE $1`, testConf.ARTICLE_TABLE+"%")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tables := []string{}
+			for rows.Next() {
+				var table string
+				rows.Scan(&table)
+				tables = append(tables, table)
+			}
+			rows.Close()
+			for _, table := range tables {
+				if _, err := psql.db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s CASCADE`, table)); err != nil {
+					t.Error(err)
+				}
+			}
+			psql.db.Close()
+		})
+		return psql
+	}
+
+	repotest.RunArticleRepository(t, func(t *testing.T) ports.ArticleRepository {
+		return newRepository(t)
+	})
+	repotest.RunSeriesRepository(t, func(t *testing.T) repotest.SeriesStore {
+		return newRepository(t)
+	})
+	repotest.RunMediaRepository(t, func(t *testing.T) repotest.MediaStore {
+		return newRepository(t)
+	})
+}
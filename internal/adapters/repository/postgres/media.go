@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+const mediaColumns = `media_id, article_id, key, url, filename, content_type, size, width, height, created_date, variants`
+
+func scanMedia(row rowScanner) (*domain.Media, error) {
+	var media domain.Media
+	var variantsJSON []byte
+	err := row.Scan(
+		&media.MediaID,
+		&media.ArticleID,
+		&media.Key,
+		&media.URL,
+		&media.Filename,
+		&media.ContentType,
+		&media.Size,
+		&media.Width,
+		&media.Height,
+		&media.CreatedDate,
+		&variantsJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(variantsJSON) > 0 {
+		if err := json.Unmarshal(variantsJSON, &media.Variants); err != nil {
+			return nil, err
+		}
+	}
+	return &media, nil
+}
+
+// scanMediaRows reads every row of rows and closes them
+func scanMediaRows(rows *sql.Rows) ([]domain.Media, error) {
+	defer rows.Close()
+	media := []domain.Media{}
+	for rows.Next() {
+		item, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *item)
+	}
+	return media, rows.Err()
+}
+
+func (psql *postgresDBClient) CreateMedia(media *domain.Media) (*domain.Media, error) {
+	variantsJSON, err := json.Marshal(media.Variants)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`, psql.mediaTable, mediaColumns)
+
+	_, err = psql.db.Exec(
+		query,
+		media.MediaID,
+		media.ArticleID,
+		media.Key,
+		media.URL,
+		media.Filename,
+		media.ContentType,
+		media.Size,
+		media.Width,
+		media.Height,
+		media.CreatedDate,
+		string(variantsJSON),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (psql *postgresDBClient) GetMedia(media_id string) (*domain.Media, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE media_id = $1`, mediaColumns, psql.mediaTable)
+	return scanMedia(psql.db.QueryRow(query, media_id))
+}
+
+func (psql *postgresDBClient) GetArticleMedia(article_id string) (*[]domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE article_id = $1
+		ORDER BY created_date, media_id`, mediaColumns, psql.mediaTable)
+	rows, err := psql.db.Query(query, article_id)
+	if err != nil {
+		return nil, err
+	}
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (psql *postgresDBClient) DeleteMedia(media_id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE media_id = $1`, psql.mediaTable)
+	res, err := psql.db.Exec(query, media_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) UpdateMediaVariants(media_id string, variants []domain.ImageVariant) error {
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`UPDATE %s SET variants = $1 WHERE media_id = $2`, psql.mediaTable)
+	res, err := psql.db.Exec(query, string(variantsJSON), media_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) SetArticleCover(article_id string, cover_image string, updated_date time.Time) (*domain.Article, error) {
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE article_id = $1 AND deleted_at IS NULL
+		FOR UPDATE`, articleColumns, psql.tablename)
+	before, err := scanArticle(tx.QueryRow(query, article_id))
+	if err != nil {
+		return nil, err
+	}
+	query = fmt.Sprintf(`
+		UPDATE %s SET cover_image = $1, cover_variants = NULL, updated_date = $2
+		WHERE article_id = $3
+		RETURNING %s`, psql.tablename, articleColumns)
+	article, err := scanArticle(tx.QueryRow(query, cover_image, updated_date, article_id))
+	if err != nil {
+		return nil, err
+	}
+	if err := psql.insertEvents(tx, domain.ArticleWriteEvents(before, article)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	psql.replicas.wrote(articleKeys(article)...)
+	return article, nil
+}
+
+func (psql *postgresDBClient) SetCoverVariants(article_id string, cover_image string, variants []domain.ImageVariant) error {
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s SET cover_variants = $1
+		WHERE article_id = $2 AND cover_image = $3
+		RETURNING %s`, psql.tablename, articleColumns)
+	article, err := scanArticle(psql.db.QueryRow(query, string(variantsJSON), article_id, cover_image))
+	if err == sql.ErrNoRows {
+		// The cover was replaced meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	psql.replicas.wrote(articleKeys(article)...)
+	return nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// insertEvents writes events to the outbox as part of tx, so they are only
+// recorded if the article change they describe commits.
+func (psql *postgresDBClient) insertEvents(tx *sql.Tx, events []domain.Event) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (event_id, event_type, article_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)`, psql.outboxTable)
+
+	for _, event := range events {
+		payload, err := json.Marshal(event.Article)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(query, event.EventID, event.EventType, event.ArticleID, string(payload), event.OccurredAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) ClaimPendingEvents(limit int, lease time.Duration) ([]domain.Event, error) {
+	// Only the oldest pending event of each article is claimable, so events
+	// about one article are published in order even across relays. SKIP
+	// LOCKED lets several service instances claim disjoint batches.
+	query := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE event_id IN (
+			SELECT event_id FROM %[1]s pending
+			WHERE delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= NOW()
+			AND NOT EXISTS (
+				SELECT 1 FROM %[1]s earlier
+				WHERE earlier.article_id = pending.article_id
+				AND earlier.delivered_at IS NULL AND earlier.dead_at IS NULL
+				AND (earlier.occurred_at, earlier.event_id) < (pending.occurred_at, pending.event_id)
+			)
+			ORDER BY occurred_at, event_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING event_id, event_type, article_id, payload, occurred_at, attempts`, psql.outboxTable)
+
+	rows, err := psql.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		var event domain.Event
+		var payload []byte
+		err := rows.Scan(&event.EventID, &event.EventType, &event.ArticleID, &payload, &event.OccurredAt, &event.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		event.Article = &domain.Article{}
+		if err := json.Unmarshal(payload, event.Article); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].OccurredAt.Before(events[j].OccurredAt)
+		}
+		return events[i].EventID < events[j].EventID
+	})
+	return events, nil
+}
+
+func (psql *postgresDBClient) MarkEventDelivered(event_id string) error {
+	query := fmt.Sprintf(`UPDATE %s SET delivered_at = NOW(), last_error = NULL WHERE event_id = $1`, psql.outboxTable)
+	_, err := psql.db.Exec(query, event_id)
+	return err
+}
+
+func (psql *postgresDBClient) MarkEventFailed(event_id string, reason string, next_attempt_at time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE event_id = $1`, psql.outboxTable)
+	_, err := psql.db.Exec(query, event_id, reason, next_attempt_at)
+	return err
+}
+
+func (psql *postgresDBClient) MarkEventDead(event_id string, reason string) error {
+	query := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1, last_error = $2, dead_at = NOW() WHERE event_id = $1`, psql.outboxTable)
+	_, err := psql.db.Exec(query, event_id, reason)
+	return err
+}
@@ -5,36 +5,79 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	appConfig "github.com/AntonyIS/notelify-articles-service/config"
 	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
 )
 
+// articleColumnNames are the columns selected by every article query, in
+// the order scanArticle expects them.
+var articleColumnNames = []string{
+	"article_id",
+	"title",
+	"subtitle",
+	"introduction",
+	"body",
+	"tags",
+	"publish_date",
+	"updated_date",
+	"author",
+	"author_id",
+	"word_count",
+	"reading_minutes",
+	"excerpt",
+	"slug",
+	"cover_image",
+	"cover_variants",
+	"deleted_at",
+}
+
+// articleColumns is the column list of articleColumnNames
+var articleColumns = selectColumns(false)
+
+// selectColumns lists articleColumnNames, selecting an empty body in place
+// of the body for summaries
+func selectColumns(summary bool) string {
+	columns := make([]string, len(articleColumnNames))
+	for i, name := range articleColumnNames {
+		if summary && name == "body" {
+			name = "'' AS body"
+		}
+		columns[i] = name
+	}
+	return strings.Join(columns, ", ")
+}
+
 type postgresDBClient struct {
 	db        *sql.DB
 	tablename string
+	// slugTable keeps the previous slugs of articles whose title changed
+	slugTable string
+	// outboxTable holds domain events until the relay has delivered them
+	outboxTable string
+	// webhookTable and deliveryTable hold webhook subscriptions and the
+	// deliveries made to them
+	webhookTable  string
+	deliveryTable string
+	// mediaTable records the media uploaded for articles
+	mediaTable string
+	// seriesTable holds series of articles, memberTable the place of each
+	// article in its series
+	seriesTable string
+	memberTable string
+	// replicas serves article reads, all other queries use db
+	replicas *replicaSet
 }
 
-func NewPostgresClient(conf appConfig.Config) (*postgresDBClient, error) {
-	dbname := conf.POSTGRES_DB
+func NewPostgresClient(conf appConfig.Config, logger ports.LoggingService) (*postgresDBClient, error) {
 	tablename := conf.ARTICLE_TABLE
-	user := conf.POSTGRES_USER
-	password := conf.POSTGRES_PASSWORD
-	port := conf.POSTGRES_PORT
-	host := conf.POSTGRES_HOST
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable", host, port, user, dbname, password)
-
-	db, err := sql.Open("postgres", dsn)
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = db.Ping()
 
+	db, err := open(conf, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -58,11 +101,185 @@ func NewPostgresClient(conf appConfig.Config) (*postgresDBClient, error) {
 		return nil, err
 	}
 
+	if err := migrate(db, tablename); err != nil {
+		return nil, err
+	}
+
+	replicas, err := openReplicas(db, conf, logger)
 	if err != nil {
 		return nil, err
 	}
+	go replicas.Run(conf.POSTGRES_REPLICA_CHECK_INTERVAL, make(chan struct{}))
+
+	return &postgresDBClient{
+		replicas:      replicas,
+		db:            db,
+		tablename:     tablename,
+		slugTable:     tablename + "_slugs",
+		outboxTable:   tablename + "_outbox",
+		webhookTable:  tablename + "_webhooks",
+		deliveryTable: tablename + "_webhook_deliveries",
+		mediaTable:    tablename + "_media",
+		seriesTable:   tablename + "_series",
+		memberTable:   tablename + "_series_articles",
+	}, nil
+}
+
+// migrate brings tables created by older versions of the service up to date.
+// Every statement must be safe to run on each start up.
+func migrate(db *sql.DB, tablename string) error {
+	migrations := []string{
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS word_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS reading_minutes INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS excerpt TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS slug VARCHAR(255)`,
+		// Articles created before slugs existed stay addressable by their ID
+		`UPDATE %[1]s SET slug = article_id WHERE slug IS NULL`,
+		`ALTER TABLE %[1]s ALTER COLUMN slug SET NOT NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS %[1]s_slug_idx ON %[1]s (slug)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_slugs (
+			slug VARCHAR(255) PRIMARY KEY,
+			article_id VARCHAR(255) NOT NULL,
+			created_date TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS cover_image TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS cover_variants JSONB`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_outbox (
+			event_id VARCHAR(255) PRIMARY KEY,
+			event_type VARCHAR(255) NOT NULL,
+			article_id VARCHAR(255) NOT NULL,
+			payload JSONB NOT NULL,
+			occurred_at TIMESTAMP NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			delivered_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_outbox_pending_idx ON %[1]s_outbox (occurred_at) WHERE delivered_at IS NULL`,
+		`ALTER TABLE %[1]s_outbox ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW()`,
+		`ALTER TABLE %[1]s_outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_outbox_article_idx ON %[1]s_outbox (article_id, occurred_at) WHERE delivered_at IS NULL AND dead_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_webhooks (
+			subscription_id VARCHAR(255) PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret VARCHAR(255) NOT NULL,
+			events TEXT[] NOT NULL DEFAULT '{}',
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_date TIMESTAMP NOT NULL,
+			updated_date TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_webhook_deliveries (
+			delivery_id VARCHAR(255) PRIMARY KEY,
+			subscription_id VARCHAR(255) NOT NULL REFERENCES %[1]s_webhooks (subscription_id) ON DELETE CASCADE,
+			event JSONB NOT NULL,
+			status VARCHAR(16) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL,
+			log JSONB NOT NULL DEFAULT '[]',
+			created_date TIMESTAMP NOT NULL,
+			updated_date TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_webhook_deliveries_due_idx ON %[1]s_webhook_deliveries (next_attempt_at) WHERE status = 'pending'`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_webhook_deliveries_subscription_idx ON %[1]s_webhook_deliveries (subscription_id, created_date)`,
+		// An event relayed again after a partial failure must not be
+		// delivered twice to the same subscription
+		`CREATE UNIQUE INDEX IF NOT EXISTS %[1]s_webhook_deliveries_event_idx ON %[1]s_webhook_deliveries (subscription_id, ((event->>'event_id')))`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_media (
+			media_id VARCHAR(255) PRIMARY KEY,
+			article_id VARCHAR(255) NOT NULL,
+			key TEXT NOT NULL,
+			url TEXT NOT NULL,
+			filename TEXT NOT NULL DEFAULT '',
+			content_type VARCHAR(255) NOT NULL,
+			size BIGINT NOT NULL,
+			width INTEGER NOT NULL DEFAULT 0,
+			height INTEGER NOT NULL DEFAULT 0,
+			created_date TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_media_article_idx ON %[1]s_media (article_id, created_date)`,
+		`ALTER TABLE %[1]s_media ADD COLUMN IF NOT EXISTS variants JSONB`,
+		`CREATE TABLE IF NOT EXISTS %[1]s_series (
+			series_id VARCHAR(255) PRIMARY KEY,
+			author_id VARCHAR(255) NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_date TIMESTAMP NOT NULL,
+			updated_date TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_series_author_idx ON %[1]s_series (author_id, created_date)`,
+		// The article is the key, so that it belongs to one series at most.
+		// Purged articles leave their series.
+		`CREATE TABLE IF NOT EXISTS %[1]s_series_articles (
+			article_id VARCHAR(255) PRIMARY KEY REFERENCES %[1]s (article_id) ON DELETE CASCADE,
+			series_id VARCHAR(255) NOT NULL REFERENCES %[1]s_series (series_id) ON DELETE CASCADE,
+			position INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_series_articles_series_idx ON %[1]s_series_articles (series_id, position)`,
+	}
+	for _, migration := range migrations {
+		if _, err := db.Exec(fmt.Sprintf(migration, tablename)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return &postgresDBClient{db: db, tablename: tablename}, nil
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArticle(row rowScanner) (*domain.Article, error) {
+	article := &domain.Article{}
+	var authorJSON, variantsJSON []byte
+	err := row.Scan(
+		&article.ArticleID,
+		&article.Title,
+		&article.Subtitle,
+		&article.Introduction,
+		&article.Body,
+		pq.Array(&article.Tags),
+		&article.PublishDate,
+		&article.UpdatedDate,
+		&authorJSON,
+		&article.AuthorID,
+		&article.WordCount,
+		&article.ReadingMinutes,
+		&article.Excerpt,
+		&article.Slug,
+		&article.CoverImage,
+		&variantsJSON,
+		&article.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSONB data into Author struct
+	err = json.Unmarshal(authorJSON, &article.Author)
+	if err != nil {
+		return nil, err
+	}
+	// Articles written before variants existed have none
+	if len(variantsJSON) > 0 {
+		if err := json.Unmarshal(variantsJSON, &article.CoverVariants); err != nil {
+			return nil, err
+		}
+	}
+	return article, nil
+}
+
+func scanArticles(rows *sql.Rows) (*[]domain.Article, error) {
+	defer rows.Close()
+
+	articles := []domain.Article{}
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, *article)
+	}
+	return &articles, rows.Err()
 }
 
 func (psql *postgresDBClient) CreateArticle(article *domain.Article) (*domain.Article, error) {
@@ -71,21 +288,21 @@ func (psql *postgresDBClient) CreateArticle(article *domain.Article) (*domain.Ar
 	if err != nil {
 		return nil, err
 	}
+	variantsJSON, err := json.Marshal(article.CoverVariants)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
-		INSERT INTO %s (
-			article_id,
-			title,
-			subtitle,
-			introduction,
-			body,
-			tags,
-			publish_date,
-			updated_date,
-			author,
-			author_id
-		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`, psql.tablename)
-	_, err = psql.db.Exec(
+		INSERT INTO %s (%s)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)`, psql.tablename, articleColumns)
+
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
 		query,
 		article.ArticleID,
 		article.Title,
@@ -97,236 +314,287 @@ func (psql *postgresDBClient) CreateArticle(article *domain.Article) (*domain.Ar
 		article.UpdatedDate,
 		string(authorJSON), // Convert Author struct to JSON string
 		article.AuthorID,
+		article.WordCount,
+		article.ReadingMinutes,
+		article.Excerpt,
+		article.Slug,
+		article.CoverImage,
+		string(variantsJSON),
+		article.DeletedAt,
 	)
 
 	if err != nil {
-		fmt.Println(err)
+		return nil, psql.slugError(err)
+	}
+
+	if err := psql.insertEvents(tx, domain.ArticleWriteEvents(nil, article)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
+	psql.replicas.wrote(articleKeys(article)...)
 
 	return article, nil
 }
 
+// slugError wraps violations of the unique slug index in
+// domain.ErrSlugTaken. Postgres folds the unquoted index name to lower case.
+func (psql *postgresDBClient) slugError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && strings.EqualFold(pqErr.Constraint, psql.tablename+"_slug_idx") {
+		return fmt.Errorf("%w: %s", domain.ErrSlugTaken, pqErr.Detail)
+	}
+	return err
+}
+
 func (psql *postgresDBClient) GetArticleByID(article_id string) (*domain.Article, error) {
+	var article *domain.Article
+	err := psql.replicas.read(func(db *sql.DB) (err error) {
+		article, err = psql.getArticleByID(db, article_id)
+		return err
+	}, "article:"+article_id)
+	return article, err
+}
+
+func (psql *postgresDBClient) getArticleByID(db *sql.DB, article_id string) (*domain.Article, error) {
 	query := fmt.Sprintf(`
-		SELECT 
-			article_id,
-			title,
-			subtitle,
-			introduction,
-			body,
-			tags,
-			publish_date,
-			updated_date,
-			author,
-			author_id
-		FROM %s 
-		WHERE article_id = $1`,
+		SELECT %s
+		FROM %s
+		WHERE article_id = $1 AND deleted_at IS NULL`,
+		articleColumns,
 		psql.tablename,
 	)
-	article := &domain.Article{}
-	row := psql.db.QueryRow(query, article_id)
-	var authorJSON []byte
-	err := row.Scan(
-		&article.ArticleID,
-		&article.Title,
-		&article.Subtitle,
-		&article.Introduction,
-		&article.Body,
-		pq.Array(&article.Tags),
-		&article.PublishDate,
-		&article.UpdatedDate,
-		&authorJSON,
-		&article.AuthorID,
-	)
+	return scanArticle(db.QueryRow(query, article_id))
+}
 
-	if err != nil {
-		return nil, err
+func (psql *postgresDBClient) GetArticleBySlug(slug string) (*domain.Article, error) {
+	var article *domain.Article
+	err := psql.replicas.read(func(db *sql.DB) (err error) {
+		article, err = psql.getArticleBySlug(db, slug)
+		return err
+	}, "slug:"+slug)
+	return article, err
+}
+
+func (psql *postgresDBClient) getArticleBySlug(db *sql.DB, slug string) (*domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE slug = $1 AND deleted_at IS NULL`,
+		articleColumns,
+		psql.tablename,
+	)
+	article, err := scanArticle(db.QueryRow(query, slug))
+	if err != sql.ErrNoRows {
+		return article, err
 	}
 
-	// Unmarshal JSONB data into Author struct
-	err = json.Unmarshal(authorJSON, &article.Author)
-	if err != nil {
+	var article_id string
+	query = fmt.Sprintf(`SELECT article_id FROM %s WHERE slug = $1`, psql.slugTable)
+	if err := db.QueryRow(query, slug).Scan(&article_id); err != nil {
 		return nil, err
 	}
-	return article, nil
+	return psql.getArticleByID(db, article_id)
+}
+
+func (psql *postgresDBClient) IsSlugTaken(slug string, article_id string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM %s WHERE slug = $1 AND article_id <> $2)
+			OR EXISTS (SELECT 1 FROM %s WHERE slug = $1 AND article_id <> $2)`,
+		psql.tablename,
+		psql.slugTable,
+	)
+	var taken bool
+	err := psql.db.QueryRow(query, slug, article_id).Scan(&taken)
+	return taken, err
 }
 
 func (psql *postgresDBClient) GetArticlesByAuthor(author_id string) (*[]domain.Article, error) {
 	query := fmt.Sprintf(`
-		SELECT 
-			article_id,
-			title,
-			subtitle,
-			introduction,
-			body,
-			tags,
-			publish_date,
-			updated_date,
-			author,
-			author_id
-		FROM %s 
-		WHERE author_id = $1`, psql.tablename)
-	rows, err := psql.db.Query(query, author_id)
+		SELECT %s
+		FROM %s
+		WHERE author_id = $1 AND deleted_at IS NULL`, articleColumns, psql.tablename)
+	return psql.queryArticles(query, []interface{}{author_id}, "author:"+author_id)
+}
 
+func (psql *postgresDBClient) GetAuthors(author_ids []string) (map[string]domain.Author, error) {
+	// Articles carry a copy of the author profile, the latest write wins
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (author_id) author_id, author
+		FROM %s
+		WHERE author_id = ANY($1) AND deleted_at IS NULL
+		ORDER BY author_id, updated_date DESC`, psql.tablename)
+	rows, err := psql.db.Query(query, pq.Array(author_ids))
 	if err != nil {
 		return nil, err
 	}
-
 	defer rows.Close()
 
-	articles := []domain.Article{}
-
+	authors := map[string]domain.Author{}
 	for rows.Next() {
-		var article domain.Article
-		var authorJSON []byte
-		err := rows.Scan(
-			&article.ArticleID,
-			&article.Title,
-			&article.Subtitle,
-			&article.Introduction,
-			&article.Body,
-			pq.Array(&article.Tags),
-			&article.PublishDate,
-			&article.UpdatedDate,
-			&authorJSON,
-			&article.AuthorID,
-		)
-		if err != nil {
+		var author_id string
+		var author []byte
+		if err := rows.Scan(&author_id, &author); err != nil {
 			return nil, err
 		}
-
-		// Unmarshal JSONB data into Author struct
-		err = json.Unmarshal(authorJSON, &article.Author)
-		if err != nil {
+		var res domain.Author
+		if err := json.Unmarshal(author, &res); err != nil {
 			return nil, err
 		}
-		articles = append(articles, article)
+		authors[author_id] = res
 	}
-
-	return &articles, nil
+	return authors, rows.Err()
 }
 
 func (psql *postgresDBClient) GetArticlesByTag(tag string) (*[]domain.Article, error) {
 	query := fmt.Sprintf(`
-		SELECT 
-			article_id,
-			title,
-			subtitle,
-			introduction,
-			body,
-			tags,
-			publish_date,
-			updated_date,
-			author,
-			author_id
-		FROM %s 
-		WHERE $1 = ANY(tags)`,
+		SELECT %s
+		FROM %s
+		WHERE $1 = ANY(tags) AND deleted_at IS NULL`,
+		articleColumns,
 		psql.tablename,
 	)
-	rows, err := psql.db.Query(query, tag)
+	return psql.queryArticles(query, []interface{}{tag}, "tag:"+tag)
+}
 
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (psql *postgresDBClient) GetArticles() (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NULL`, articleColumns, psql.tablename)
 
-	var articles []domain.Article
+	return psql.queryArticles(query, nil, allArticles)
+}
 
-	for rows.Next() {
-		var article domain.Article
-		err := rows.Scan(
-			&article.ArticleID,
-			&article.Title,
-			&article.Subtitle,
-			&article.Introduction,
-			&article.Body,
-			pq.Array(&article.Tags),
-			&article.PublishDate,
-			&article.UpdatedDate,
-			&article.Author,
-			&article.AuthorID,
-		)
+// queryArticles reads the articles query selects from the database the
+// replicas pick for key.
+func (psql *postgresDBClient) queryArticles(query string, args []interface{}, key string) (*[]domain.Article, error) {
+	var articles *[]domain.Article
+	err := psql.replicas.read(func(db *sql.DB) error {
+		rows, err := db.Query(query, args...)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		articles = append(articles, article)
-	}
-
-	return &articles, nil
+		articles, err = scanArticles(rows)
+		return err
+	}, key)
+	return articles, err
 }
 
-func (psql *postgresDBClient) GetArticles() (*[]domain.Article, error) {
-	query := fmt.Sprintf(`
-	SELECT 
-		article_id,
-		title,
-		subtitle,
-		introduction,
-		body,
-		tags,
-		publish_date,
-		updated_date,
-		author,
-		author_id
-	FROM %s `, psql.tablename)
+func (psql *postgresDBClient) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	queryString, args, err := buildFindArticlesQuery(psql.tablename, query)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := psql.db.Query(query)
+	rows, err := psql.db.Query(queryString, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	articles := []domain.Article{}
-	for rows.Next() {
-		var article domain.Article
-		var authorJSON []byte
-		err := rows.Scan(
-			&article.ArticleID,
-			&article.Title,
-			&article.Subtitle,
-			&article.Introduction,
-			&article.Body,
-			pq.Array(&article.Tags),
-			&article.PublishDate,
-			&article.UpdatedDate,
-			&authorJSON,
-			&article.AuthorID,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return scanArticles(rows)
+}
+
+// buildFindArticlesQuery turns an ArticleQuery into a SELECT statement and
+// its arguments. Filter values are always bound as placeholders; the sort
+// column is only ever taken from a fixed whitelist.
+func buildFindArticlesQuery(tablename string, query domain.ArticleQuery) (string, []interface{}, error) {
+	if err := query.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.AuthorID != "" {
+		addCondition("author_id = $%d", query.AuthorID)
+	}
+	if query.Tag != "" {
+		addCondition("EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE lower(tag) = lower($%d))", query.Tag)
+	}
+	if !query.From.IsZero() {
+		addCondition("publish_date >= $%d", query.From)
+	}
+	if !query.To.IsZero() {
+		addCondition("publish_date <= $%d", query.To)
+	}
+	switch query.Status {
+	case domain.ArticleStatusPublished:
+		conditions = append(conditions, "publish_date <= NOW()")
+	case domain.ArticleStatusScheduled:
+		conditions = append(conditions, "publish_date > NOW()")
+	}
 
-		// Unmarshal JSONB data into Author struct
-		err = json.Unmarshal(authorJSON, &article.Author)
+	sortField, desc := query.SortField()
+	direction, seek := "ASC", ">"
+	if desc {
+		direction, seek = "DESC", "<"
+	}
+	if query.After.ArticleID != "" {
+		// Seek past the cursor, article_id breaking ties in the sort key
+		key, err := query.AfterKey()
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
-		articles = append(articles, article)
+		args = append(args, key, query.After.ArticleID)
+		conditions = append(conditions, fmt.Sprintf(
+			"(%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND article_id > $%[4]d))",
+			sortField, seek, len(args)-1, len(args),
+		))
 	}
 
-	return &articles, nil
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	limit := ""
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+
+	// Summary listings leave out the body, the only column that grows with
+	// the article
+	columns := selectColumns(query.Summary)
+
+	queryString := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		%s
+		ORDER BY %s %s, article_id ASC
+		%s`,
+		columns,
+		tablename,
+		where,
+		sortField,
+		direction,
+		limit,
+	)
+	return queryString, args, nil
 }
 
 func (psql *postgresDBClient) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
-	res, err := psql.GetArticleByID(article_id)
+	// Replicas may lag behind, the article is read back from the primary
+	res, err := psql.getArticleByID(psql.db, article_id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	res.Title = article.Title
-	res.Subtitle = article.Subtitle
-	res.Introduction = article.Introduction
-	res.Body = article.Body
-	res.Tags = article.Tags
-	res.PublishDate = article.PublishDate
-	res.UpdatedDate = article.UpdatedDate
-	res.AuthorID = article.AuthorID
+	before := *res
+	previousSlug := res.Slug
+	*res = res.ApplyUpdate(article)
 
+	authorJSON, err := json.Marshal(res.Author)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
-	UPDATE 
-		%s 
-	SET 
+	UPDATE
+		%s
+	SET
 		title=$1,
 		subtitle=$2,
 		introduction=$3,
@@ -334,14 +602,24 @@ func (psql *postgresDBClient) UpdateArticle(article_id string, article *domain.A
 		tags=$5,
 		publish_date=$6,
 		updated_date=$7,
-		author=$8
-		author_id=$9	
-	WHERE 
-		article_id=$8`,
+		author=$8,
+		author_id=$9,
+		word_count=$10,
+		reading_minutes=$11,
+		excerpt=$12,
+		slug=$13
+	WHERE
+		article_id=$14`,
 		psql.tablename,
 	)
 
-	_, err = psql.db.Exec(
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
 		query,
 		res.Title,
 		res.Subtitle,
@@ -350,45 +628,346 @@ func (psql *postgresDBClient) UpdateArticle(article_id string, article *domain.A
 		pq.Array(res.Tags),
 		res.PublishDate,
 		res.UpdatedDate,
-		res.Author,
+		string(authorJSON),
+		res.AuthorID,
+		res.WordCount,
+		res.ReadingMinutes,
+		res.Excerpt,
+		res.Slug,
 		res.ArticleID,
 	)
 
 	if err != nil {
+		return nil, psql.slugError(err)
+	}
+
+	if res.Slug != previousSlug {
+		// The new slug may be one this article used before
+		_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE slug = $1 AND article_id = $2`, psql.slugTable), res.Slug, res.ArticleID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(fmt.Sprintf(`
+			INSERT INTO %s (slug, article_id)
+			VALUES ($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET article_id = EXCLUDED.article_id`, psql.slugTable), previousSlug, res.ArticleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := psql.insertEvents(tx, domain.ArticleWriteEvents(&before, res)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
+	psql.replicas.wrote(articleKeys(&before, res)...)
 
-	return psql.GetArticleByID(article_id)
+	return psql.getArticleByID(psql.db, article_id)
 }
 
 func (psql *postgresDBClient) DeleteArticle(article_id string) error {
-	_, err := psql.GetArticleByID(article_id)
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NOW()
+		WHERE article_id = $1 AND deleted_at IS NULL
+		RETURNING %s`, psql.tablename, articleColumns)
 
+	tx, err := psql.db.Begin()
 	if err != nil {
 		return err
 	}
-	query := fmt.Sprintf(`DELETE FROM %s WHERE article_id = $1`, psql.tablename)
+	defer tx.Rollback()
 
-	_, err = psql.db.Exec(query, article_id)
+	article, err := scanArticle(tx.QueryRow(query, article_id))
 	if err != nil {
 		return err
 	}
+	if err := psql.insertEvents(tx, []domain.Event{domain.NewArticleEvent(domain.EventArticleDeleted, article)}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	psql.replicas.wrote(articleKeys(article)...)
 	return nil
 }
 
 func (psql *postgresDBClient) DeleteArticleAll() error {
-	articles, err := psql.GetArticles()
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NOW()
+		WHERE deleted_at IS NULL
+		RETURNING %s`, psql.tablename, articleColumns)
+
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	articles, err := scanArticles(rows)
 	if err != nil {
 		return err
 	}
 	if len(*articles) == 0 {
 		return errors.New("no Articles to delete")
 	}
-	query := fmt.Sprintf(`DELETE FROM %s `, psql.tablename)
-	_, err = psql.db.Exec(query)
 
-	if err != nil {
+	events := []domain.Event{}
+	for i := range *articles {
+		events = append(events, domain.NewArticleEvent(domain.EventArticleDeleted, &(*articles)[i]))
+	}
+	if err := psql.insertEvents(tx, events); err != nil {
 		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	psql.replicas.wrote(everyArticle)
 	return nil
 }
+
+func (psql *postgresDBClient) GetDeletedArticles() (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`, articleColumns, psql.tablename)
+
+	rows, err := psql.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (psql *postgresDBClient) RestoreArticle(article_id string) (*domain.Article, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NULL
+		WHERE article_id = $1 AND deleted_at IS NOT NULL
+		RETURNING %s`, psql.tablename, articleColumns)
+
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	article, err := scanArticle(tx.QueryRow(query, article_id))
+	if err != nil {
+		return nil, err
+	}
+	if err := psql.insertEvents(tx, []domain.Event{domain.NewArticleEvent(domain.EventArticleRestored, article)}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	psql.replicas.wrote(articleKeys(article)...)
+	return article, nil
+}
+
+func (psql *postgresDBClient) PurgeDeletedArticles(before time.Time) (int64, []domain.Media, error) {
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE article_id IN (SELECT article_id FROM %s WHERE deleted_at < $1)`, psql.slugTable, psql.tablename)
+	if _, err := tx.Exec(query, before); err != nil {
+		return 0, nil, err
+	}
+
+	query = fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE article_id IN (SELECT article_id FROM %s WHERE deleted_at < $1)
+		RETURNING %s`, psql.mediaTable, psql.tablename, mediaColumns)
+	rows, err := tx.Query(query, before)
+	if err != nil {
+		return 0, nil, err
+	}
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE deleted_at < $1`, psql.tablename)
+	res, err := tx.Exec(query, before)
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, media, tx.Commit()
+}
+
+func (psql *postgresDBClient) StreamArticles(fn func(article *domain.Article) error) error {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NULL
+		ORDER BY article_id`, articleColumns, psql.tablename)
+
+	rows, err := psql.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(article); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (psql *postgresDBClient) UpsertArticles(articles []domain.Article) ([]error, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		ON CONFLICT (article_id) DO UPDATE SET
+			title = EXCLUDED.title,
+			subtitle = EXCLUDED.subtitle,
+			introduction = EXCLUDED.introduction,
+			body = EXCLUDED.body,
+			tags = EXCLUDED.tags,
+			publish_date = EXCLUDED.publish_date,
+			updated_date = EXCLUDED.updated_date,
+			author = EXCLUDED.author,
+			author_id = EXCLUDED.author_id,
+			word_count = EXCLUDED.word_count,
+			reading_minutes = EXCLUDED.reading_minutes,
+			excerpt = EXCLUDED.excerpt,
+			slug = EXCLUDED.slug,
+			cover_image = EXCLUDED.cover_image,
+			cover_variants = EXCLUDED.cover_variants,
+			deleted_at = EXCLUDED.deleted_at
+		RETURNING (xmax = 0) AS inserted`, psql.tablename, articleColumns)
+
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Each row runs inside its own savepoint so that one bad article does
+	// not abort the rest of the batch.
+	results := make([]error, len(articles))
+	for i, article := range articles {
+		authorJSON, err := json.Marshal(article.Author)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		variantsJSON, err := json.Marshal(article.CoverVariants)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		if _, err := tx.Exec("SAVEPOINT article_upsert"); err != nil {
+			return nil, err
+		}
+		var inserted bool
+		err = tx.QueryRow(
+			query,
+			article.ArticleID,
+			article.Title,
+			article.Subtitle,
+			article.Introduction,
+			article.Body,
+			pq.Array(article.Tags),
+			article.PublishDate,
+			article.UpdatedDate,
+			string(authorJSON),
+			article.AuthorID,
+			article.WordCount,
+			article.ReadingMinutes,
+			article.Excerpt,
+			article.Slug,
+			article.CoverImage,
+			string(variantsJSON),
+			article.DeletedAt,
+		).Scan(&inserted)
+		if err == nil {
+			events := []domain.Event{domain.NewArticleEvent(domain.EventArticleUpdated, &articles[i])}
+			if inserted {
+				events = domain.ArticleWriteEvents(nil, &articles[i])
+			}
+			err = psql.insertEvents(tx, events)
+		}
+		if err != nil {
+			results[i] = err
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT article_upsert"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT article_upsert"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	written := []*domain.Article{}
+	for i := range articles {
+		if results[i] == nil {
+			written = append(written, &articles[i])
+		}
+	}
+	psql.replicas.wrote(articleKeys(written...)...)
+	return results, nil
+}
+
+func (psql *postgresDBClient) CountPublishedArticles() (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE deleted_at IS NULL AND publish_date <= NOW()`, psql.tablename)
+
+	var count int
+	if err := psql.db.QueryRow(query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (psql *postgresDBClient) GetSitemapPage(after string, offset, limit int) ([]domain.SitemapEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT article_id, slug, updated_date
+		FROM %s
+		WHERE deleted_at IS NULL AND publish_date <= NOW() AND article_id > $1
+		ORDER BY article_id
+		LIMIT $2 OFFSET $3`, psql.tablename)
+
+	rows, err := psql.db.Query(query, after, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.SitemapEntry{}
+	for rows.Next() {
+		var entry domain.SitemapEntry
+		if err := rows.Scan(&entry.ArticleID, &entry.Slug, &entry.UpdatedDate); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+const (
+	// allArticles is the key of reads listing all articles, written along
+	// with any article
+	allArticles = "articles"
+	// everyArticle is written by changes to all articles at once and keeps
+	// every read on the primary
+	everyArticle = "*"
+)
+
+type replica struct {
+	// name tells replicas apart in logs without revealing their password
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaSet routes reads to healthy replicas in turn. Reads of keys written
+// within window, and all reads while no replica is healthy, go to the
+// primary. Writes are only tracked within this process: another instance of
+// the service may still read a key it did not write from a lagging replica.
+type replicaSet struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint32
+	window   time.Duration
+	now      func() time.Time
+	logger   ports.LoggingService
+
+	mu sync.Mutex
+	// written holds the time every key was last written at
+	written map[string]time.Time
+}
+
+// openReplicas connects to the replicas in conf with the primary's pool
+// settings. Replicas that cannot be reached start out ejected rather than
+// failing start up.
+func openReplicas(primary *sql.DB, conf appConfig.Config, logger ports.LoggingService) (*replicaSet, error) {
+	set := &replicaSet{
+		primary: primary,
+		window:  conf.POSTGRES_READ_AFTER_WRITE_WINDOW,
+		now:     time.Now,
+		logger:  logger,
+		written: map[string]time.Time{},
+	}
+	for i, dsn := range conf.POSTGRES_REPLICAS {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(conf.POSTGRES_MAX_OPEN_CONNS)
+		db.SetMaxIdleConns(conf.POSTGRES_MAX_IDLE_CONNS)
+		db.SetConnMaxLifetime(conf.POSTGRES_CONN_MAX_LIFETIME)
+		db.SetConnMaxIdleTime(conf.POSTGRES_CONN_MAX_IDLE_TIME)
+		set.replicas = append(set.replicas, &replica{name: "replica " + strconv.Itoa(i+1), db: db})
+	}
+	set.check()
+	return set, nil
+}
+
+// Run checks the health of every replica each interval until stop is
+// closed.
+func (set *replicaSet) Run(interval time.Duration, stop <-chan struct{}) {
+	if len(set.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			set.check()
+		}
+	}
+}
+
+// check pings every replica, ejecting those that fail and readmitting those
+// that recovered.
+func (set *replicaSet) check() {
+	for _, r := range set.replicas {
+		if err := r.db.Ping(); err != nil {
+			set.eject(r, err)
+		} else if !r.healthy.Swap(true) {
+			logEntry := domain.LogMessage{
+				LogLevel: "INFO",
+				Service:  "articles",
+				Message:  fmt.Sprintf("postgres %s is healthy, reading from it", r.name),
+			}
+			set.logger.LogInfo(logEntry)
+		}
+	}
+}
+
+func (set *replicaSet) eject(r *replica, err error) {
+	if r.healthy.Swap(false) {
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  fmt.Sprintf("postgres %s ejected, reading from the primary: %v", r.name, err),
+		}
+		set.logger.LogWarning(logEntry)
+	}
+}
+
+// reader picks the database to read keys from, and the replica behind it
+// when it is not the primary.
+func (set *replicaSet) reader(keys ...string) (*sql.DB, *replica) {
+	if len(set.replicas) == 0 || set.recentlyWritten(keys) {
+		return set.primary, nil
+	}
+	start := set.next.Add(1)
+	for i := range set.replicas {
+		r := set.replicas[(int(start)+i)%len(set.replicas)]
+		if r.healthy.Load() {
+			return r.db, r
+		}
+	}
+	return set.primary, nil
+}
+
+// read runs query against the database picked for keys. A replica failing
+// the query is ejected and the query repeated on the primary. Missing rows
+// are not a failure.
+func (set *replicaSet) read(query func(db *sql.DB) error, keys ...string) error {
+	db, r := set.reader(keys...)
+	err := query(db)
+	if err == nil || err == sql.ErrNoRows || r == nil {
+		return err
+	}
+	set.eject(r, err)
+	return query(set.primary)
+}
+
+// wrote records that keys were just written, keeping their reads on the
+// primary until replicas caught up.
+func (set *replicaSet) wrote(keys ...string) {
+	if len(set.replicas) == 0 || set.window <= 0 {
+		return
+	}
+	now := set.now()
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for key, at := range set.written {
+		if now.Sub(at) >= set.window {
+			delete(set.written, key)
+		}
+	}
+	for _, key := range keys {
+		set.written[key] = now
+	}
+}
+
+func (set *replicaSet) recentlyWritten(keys []string) bool {
+	now := set.now()
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for _, key := range append(keys, everyArticle) {
+		if at, ok := set.written[key]; ok && now.Sub(at) < set.window {
+			return true
+		}
+	}
+	return false
+}
+
+// articleKeys are the keys under which reads find articles: their ID, slug,
+// author and tags, and the list of all articles.
+func articleKeys(articles ...*domain.Article) []string {
+	keys := []string{allArticles}
+	for _, article := range articles {
+		if article == nil {
+			continue
+		}
+		keys = append(keys, "article:"+article.ArticleID, "slug:"+article.Slug, "author:"+article.AuthorID)
+		for _, tag := range article.Tags {
+			keys = append(keys, "tag:"+tag)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+type stubLogger struct{}
+
+func (stubLogger) SendLog(domain.LogMessage)    {}
+func (stubLogger) LogDebug(domain.LogMessage)   {}
+func (stubLogger) LogInfo(domain.LogMessage)    {}
+func (stubLogger) LogWarning(domain.LogMessage) {}
+func (stubLogger) LogError(domain.LogMessage)   {}
+
+// newTestReplicaSet builds a set over unconnected databases, with the
+// replicas healthy and the clock at now.
+func newTestReplicaSet(t *testing.T, replicas int, now *time.Time) *replicaSet {
+	open := func(name string) *sql.DB {
+		db, err := sql.Open("postgres", "host="+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	set := &replicaSet{
+		primary: open("primary"),
+		window:  5 * time.Second,
+		now:     func() time.Time { return *now },
+		logger:  stubLogger{},
+		written: map[string]time.Time{},
+	}
+	for i := 0; i < replicas; i++ {
+		r := &replica{name: "replica", db: open("replica")}
+		r.healthy.Store(true)
+		set.replicas = append(set.replicas, r)
+	}
+	return set
+}
+
+func TestReplicaRoundRobin(t *testing.T) {
+	now := time.Now()
+	set := newTestReplicaSet(t, 2, &now)
+
+	first, _ := set.reader("article:1")
+	second, _ := set.reader("article:1")
+	third, _ := set.reader("article:1")
+	if first == set.primary || second == set.primary || first == second || first != third {
+		t.Errorf("Expected reads to alternate between the replicas")
+	}
+
+	set.eject(set.replicas[0], errors.New("connection refused"))
+	set.eject(set.replicas[1], errors.New("connection refused"))
+	if db, r := set.reader("article:1"); db != set.primary || r != nil {
+		t.Errorf("Expected reads on the primary without healthy replicas")
+	}
+}
+
+func TestReplicaReadAfterWrite(t *testing.T) {
+	now := time.Now()
+	set := newTestReplicaSet(t, 1, &now)
+
+	set.wrote(articleKeys(&domain.Article{ArticleID: "1", Slug: "hello", AuthorID: "a", Tags: []string{"go"}})...)
+	for _, key := range []string{"article:1", "slug:hello", "author:a", "tag:go", allArticles} {
+		if db, _ := set.reader(key); db != set.primary {
+			t.Errorf("Expected %s to be read from the primary after a write", key)
+		}
+	}
+	if db, _ := set.reader("article:2"); db == set.primary {
+		t.Errorf("Expected other articles to be read from the replica")
+	}
+
+	now = now.Add(5 * time.Second)
+	if db, _ := set.reader("article:1"); db == set.primary {
+		t.Errorf("Expected reads on the replica once the window passed")
+	}
+
+	set.wrote(everyArticle)
+	if db, _ := set.reader("article:2"); db != set.primary {
+		t.Errorf("Expected every read on the primary after writing all articles")
+	}
+}
+
+func TestReplicaFailureFallsBack(t *testing.T) {
+	now := time.Now()
+	set := newTestReplicaSet(t, 1, &now)
+
+	used := []*sql.DB{}
+	err := set.read(func(db *sql.DB) error {
+		used = append(used, db)
+		if db != set.primary {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, "article:1")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(used) != 2 || used[0] != set.replicas[0].db || used[1] != set.primary {
+		t.Errorf("Expected the read to fall back to the primary")
+	}
+	if set.replicas[0].healthy.Load() {
+		t.Errorf("Expected the failing replica to be ejected")
+	}
+
+	// Missing rows are an answer, not a failure
+	set.replicas[0].healthy.Store(true)
+	err = set.read(func(db *sql.DB) error { return sql.ErrNoRows }, "article:1")
+	if err != sql.ErrNoRows || !set.replicas[0].healthy.Load() {
+		t.Errorf("Expected missing rows to keep the replica, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+// seriesColumns selects a series with its articles in reading order. The
+// series table is aliased s.
+const seriesColumns = `s.series_id, s.author_id, s.title, s.description, s.created_date, s.updated_date,
+	ARRAY(SELECT m.article_id FROM %s m WHERE m.series_id = s.series_id ORDER BY m.position)`
+
+func (psql *postgresDBClient) seriesSelect() string {
+	return fmt.Sprintf(`SELECT `+seriesColumns+` FROM %s s`, psql.memberTable, psql.seriesTable)
+}
+
+func scanSeries(row rowScanner) (*domain.Series, error) {
+	var series domain.Series
+	err := row.Scan(
+		&series.SeriesID,
+		&series.AuthorID,
+		&series.Title,
+		&series.Description,
+		&series.CreatedDate,
+		&series.UpdatedDate,
+		pq.Array(&series.ArticleIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if series.ArticleIDs == nil {
+		series.ArticleIDs = []string{}
+	}
+	return &series, nil
+}
+
+func (psql *postgresDBClient) CreateSeries(series *domain.Series) (*domain.Series, error) {
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (series_id, author_id, title, description, created_date, updated_date)
+		VALUES ($1, $2, $3, $4, $5, $6)`, psql.seriesTable)
+	_, err = tx.Exec(query, series.SeriesID, series.AuthorID, series.Title, series.Description, series.CreatedDate, series.UpdatedDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := psql.setSeriesArticles(tx, series); err != nil {
+		return nil, err
+	}
+	return series, tx.Commit()
+}
+
+func (psql *postgresDBClient) GetSeries(series_id string) (*domain.Series, error) {
+	query := psql.seriesSelect() + ` WHERE s.series_id = $1`
+	return scanSeries(psql.db.QueryRow(query, series_id))
+}
+
+func (psql *postgresDBClient) GetSeriesList(author_id string) (*[]domain.Series, error) {
+	query := psql.seriesSelect()
+	args := []interface{}{}
+	if author_id != "" {
+		query += ` WHERE s.author_id = $1`
+		args = append(args, author_id)
+	}
+	rows, err := psql.db.Query(query+` ORDER BY s.created_date, s.series_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Series{}
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *series)
+	}
+	return &list, rows.Err()
+}
+
+func (psql *postgresDBClient) UpdateSeries(series_id string, series *domain.Series) (*domain.Series, error) {
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET title = $1, description = $2, updated_date = $3
+		WHERE series_id = $4`, psql.seriesTable)
+	res, err := tx.Exec(query, series.Title, series.Description, series.UpdatedDate, series_id)
+	if err != nil {
+		return nil, err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return nil, sql.ErrNoRows
+	}
+	series.SeriesID = series_id
+	if err := psql.setSeriesArticles(tx, series); err != nil {
+		return nil, err
+	}
+	return series, tx.Commit()
+}
+
+// setSeriesArticles replaces the articles of the series, numbering them in
+// the order given
+func (psql *postgresDBClient) setSeriesArticles(tx *sql.Tx, series *domain.Series) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE series_id = $1`, psql.memberTable)
+	if _, err := tx.Exec(query, series.SeriesID); err != nil {
+		return err
+	}
+	query = fmt.Sprintf(`INSERT INTO %s (article_id, series_id, position) VALUES ($1, $2, $3)`, psql.memberTable)
+	for i, article_id := range series.ArticleIDs {
+		if _, err := tx.Exec(query, article_id, series.SeriesID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) DeleteSeries(series_id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE series_id = $1`, psql.seriesTable)
+	res, err := psql.db.Exec(query, series_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) GetArticleSeries(article_id string) (*domain.Series, error) {
+	query := psql.seriesSelect() + fmt.Sprintf(`
+		WHERE s.series_id = (SELECT series_id FROM %s WHERE article_id = $1)`, psql.memberTable)
+	series, err := scanSeries(psql.db.QueryRow(query, article_id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return series, err
+}
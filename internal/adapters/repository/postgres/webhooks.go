@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+const subscriptionColumns = `subscription_id, url, secret, events, active, created_date, updated_date`
+
+const deliveryColumns = `delivery_id, subscription_id, event, status, attempts, next_attempt_at, log, created_date, updated_date`
+
+func scanSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	err := row.Scan(
+		&subscription.SubscriptionID,
+		&subscription.URL,
+		&subscription.Secret,
+		pq.Array(&subscription.Events),
+		&subscription.Active,
+		&subscription.CreatedDate,
+		&subscription.UpdatedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var event, log []byte
+	err := row.Scan(
+		&delivery.DeliveryID,
+		&delivery.SubscriptionID,
+		&event,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&log,
+		&delivery.CreatedDate,
+		&delivery.UpdatedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(event, &delivery.Event); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(log, &delivery.Log); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]domain.WebhookDelivery, error) {
+	defer rows.Close()
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (psql *postgresDBClient) CreateSubscription(subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`, psql.webhookTable, subscriptionColumns)
+
+	_, err := psql.db.Exec(
+		query,
+		subscription.SubscriptionID,
+		subscription.URL,
+		subscription.Secret,
+		pq.Array(subscription.Events),
+		subscription.Active,
+		subscription.CreatedDate,
+		subscription.UpdatedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (psql *postgresDBClient) GetSubscription(subscription_id string) (*domain.WebhookSubscription, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE subscription_id = $1`, subscriptionColumns, psql.webhookTable)
+	return scanSubscription(psql.db.QueryRow(query, subscription_id))
+}
+
+func (psql *postgresDBClient) GetSubscriptions() (*[]domain.WebhookSubscription, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY created_date, subscription_id`, subscriptionColumns, psql.webhookTable)
+	rows, err := psql.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return &subscriptions, rows.Err()
+}
+
+func (psql *postgresDBClient) UpdateSubscription(subscription_id string, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET url = $2, secret = $3, events = $4, active = $5, updated_date = $6
+		WHERE subscription_id = $1
+		RETURNING %s`, psql.webhookTable, subscriptionColumns)
+
+	return scanSubscription(psql.db.QueryRow(
+		query,
+		subscription_id,
+		subscription.URL,
+		subscription.Secret,
+		pq.Array(subscription.Events),
+		subscription.Active,
+		subscription.UpdatedDate,
+	))
+}
+
+func (psql *postgresDBClient) DeleteSubscription(subscription_id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE subscription_id = $1`, psql.webhookTable)
+	res, err := psql.db.Exec(query, subscription_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (psql *postgresDBClient) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	tx, err := psql.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (subscription_id, (event->>'event_id')) DO NOTHING`, psql.deliveryTable, deliveryColumns)
+
+	for _, delivery := range deliveries {
+		event, err := json.Marshal(delivery.Event)
+		if err != nil {
+			return err
+		}
+		log, err := json.Marshal(delivery.Log)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			query,
+			delivery.DeliveryID,
+			delivery.SubscriptionID,
+			string(event),
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			string(log),
+			delivery.CreatedDate,
+			delivery.UpdatedDate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (psql *postgresDBClient) GetDelivery(delivery_id string) (*domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE delivery_id = $1`, deliveryColumns, psql.deliveryTable)
+	return scanDelivery(psql.db.QueryRow(query, delivery_id))
+}
+
+func (psql *postgresDBClient) GetDeliveries(subscription_id string) (*[]domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE subscription_id = $1
+		ORDER BY created_date DESC, delivery_id`, deliveryColumns, psql.deliveryTable)
+	rows, err := psql.db.Query(query, subscription_id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &deliveries, nil
+}
+
+func (psql *postgresDBClient) GetDeadDeliveries() (*[]domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE status = $1
+		ORDER BY updated_date DESC, delivery_id`, deliveryColumns, psql.deliveryTable)
+	rows, err := psql.db.Query(query, domain.DeliveryDead)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &deliveries, nil
+}
+
+func (psql *postgresDBClient) ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	// SKIP LOCKED lets several service instances claim disjoint batches
+	query := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET next_attempt_at = NOW() + $3 * INTERVAL '1 second'
+		WHERE delivery_id IN (
+			SELECT delivery_id FROM %[1]s
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %[2]s`, psql.deliveryTable, deliveryColumns)
+
+	rows, err := psql.db.Query(query, domain.DeliveryPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+func (psql *postgresDBClient) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	log, err := json.Marshal(delivery.Log)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = $2, attempts = $3, next_attempt_at = $4, log = $5, updated_date = $6
+		WHERE delivery_id = $1`, psql.deliveryTable)
+
+	_, err = psql.db.Exec(
+		query,
+		delivery.DeliveryID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		string(log),
+		delivery.UpdatedDate,
+	)
+	return err
+}
@@ -0,0 +1,91 @@
+package repotest
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// MediaStore is an article repository that also records media
+type MediaStore interface {
+	ports.ArticleRepository
+	ports.MediaRepository
+}
+
+// NewMediaRepository returns an empty repository. It is called once per
+// subtest.
+type NewMediaRepository func(t *testing.T) MediaStore
+
+// NewMedia returns media of the article stored under key
+func NewMedia(id, article_id, key string) *domain.Media {
+	return &domain.Media{
+		MediaID:     id,
+		ArticleID:   article_id,
+		Key:         key,
+		URL:         "https://cdn.example.com/" + key,
+		Filename:    id + ".png",
+		ContentType: "image/png",
+		Size:        100,
+		Width:       10,
+		Height:      10,
+		CreatedDate: publishDate,
+	}
+}
+
+// RunMediaRepository runs the media contract against repositories made by
+// newRepository.
+func RunMediaRepository(t *testing.T, newRepository NewMediaRepository) {
+	t.Run("Test purge removes media", func(t *testing.T) { testPurgeMedia(t, newRepository(t)) })
+}
+
+func testPurgeMedia(t *testing.T, repo MediaStore) {
+	mustCreate(t, repo, NewArticle("1", "author-1"), NewArticle("2", "author-1"))
+	for _, media := range []*domain.Media{NewMedia("m1", "1", "articles/1/m1.png"), NewMedia("m2", "2", "articles/2/m2.png")} {
+		if _, err := repo.CreateMedia(media); err != nil {
+			t.Fatalf("CreateMedia(%s): %v", media.MediaID, err)
+		}
+	}
+	variants := []domain.ImageVariant{{Name: "w480", Format: "jpeg", Key: "articles/1/m1-w480.jpeg", Width: 480, Height: 480}}
+	if err := repo.UpdateMediaVariants("m1", variants); err != nil {
+		t.Fatalf("UpdateMediaVariants: %v", err)
+	}
+
+	if err := repo.DeleteArticle("1"); err != nil {
+		t.Fatalf("DeleteArticle: %v", err)
+	}
+	// Trashed articles keep their media until they are purged
+	if media, err := repo.GetArticleMedia("1"); err != nil || len(*media) != 1 {
+		t.Errorf("Expected the media of the trashed article kept, got %v, %v", media, err)
+	}
+
+	count, purged, err := repo.PurgeDeletedArticles(time.Now().Add(time.Minute))
+	if err != nil || count != 1 {
+		t.Fatalf("Expected one article purged, got %d, %v", count, err)
+	}
+	if len(purged) != 1 || purged[0].Key != "articles/1/m1.png" || !reflect.DeepEqual(purged[0].Variants, variants) {
+		t.Errorf("Expected the purged media with its variants, got %+v", purged)
+	}
+	if _, err := repo.GetMedia("m1"); err == nil {
+		t.Errorf("Expected the media of the purged article to be gone")
+	}
+	if media, err := repo.GetArticleMedia("1"); err != nil || len(*media) != 0 {
+		t.Errorf("Expected no media left for the purged article, got %v, %v", media, err)
+	}
+
+	media, err := repo.GetArticleMedia("2")
+	if err != nil {
+		t.Fatalf("GetArticleMedia: %v", err)
+	}
+	ids := []string{}
+	for _, item := range *media {
+		ids = append(ids, item.MediaID)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"m2"}) {
+		t.Errorf("Expected the media of other articles kept, got %v", ids)
+	}
+}
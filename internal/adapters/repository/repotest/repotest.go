@@ -0,0 +1,643 @@
+// Package repotest is the contract every ports.ArticleRepository adapter
+// must meet. Adapter packages run it from their own tests:
+//
+//	func TestContract(t *testing.T) {
+//		repotest.RunArticleRepository(t, func(t *testing.T) ports.ArticleRepository {
+//			return newEmptyRepository(t)
+//		})
+//	}
+//
+// Adapters keeping series run RunSeriesRepository as well.
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// NewRepository returns an empty repository. It is called once per subtest.
+type NewRepository func(t *testing.T) ports.ArticleRepository
+
+// publishDate is the first publish date of the test articles. Dates are whole
+// seconds in UTC, which every store keeps exactly.
+var publishDate = time.Date(2023, 9, 1, 8, 0, 0, 0, time.UTC)
+
+// NewArticle returns a published article whose fields are all set, so that
+// a column an adapter forgets to write or read shows up as a difference.
+func NewArticle(id, author_id string, tags ...string) *domain.Article {
+	return &domain.Article{
+		ArticleID:    id,
+		Title:        "Article " + id,
+		Subtitle:     "Subtitle of " + id,
+		Introduction: "Introduction of " + id,
+		Body:         "Body of " + id,
+		Tags:         tags,
+		PublishDate:  publishDate,
+		UpdatedDate:  publishDate,
+		Author: domain.Author{
+			AuthorID:         author_id,
+			Firstname:        "Ada",
+			Lastname:         "Lovelace",
+			Handle:           "@" + author_id,
+			SocialMediaLinks: []string{"https://example.com/" + author_id},
+		},
+		AuthorID:       author_id,
+		WordCount:      3,
+		ReadingMinutes: 1,
+		Excerpt:        "Excerpt of " + id,
+		Slug:           "article-" + id,
+		CoverImage:     "/media/articles/" + id + "/cover.png",
+		CoverVariants: []domain.ImageVariant{{
+			Name:   "thumbnail",
+			Format: "jpeg",
+			Key:    "articles/" + id + "/cover/thumbnail.jpg",
+			URL:    "/media/articles/" + id + "/cover/thumbnail.jpg",
+			Width:  320,
+			Height: 320,
+		}},
+	}
+}
+
+// RunArticleRepository runs the contract against repositories made by
+// newRepository.
+func RunArticleRepository(t *testing.T, newRepository NewRepository) {
+	t.Run("Test create and get", func(t *testing.T) { testCreateAndGet(t, newRepository(t)) })
+	t.Run("Test not found", func(t *testing.T) { testNotFound(t, newRepository(t)) })
+	t.Run("Test update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Test update every field", func(t *testing.T) { testUpdateEveryField(t, newRepository(t)) })
+	t.Run("Test slugs", func(t *testing.T) { testSlugs(t, newRepository(t)) })
+	t.Run("Test author and tag queries", func(t *testing.T) { testAuthorAndTag(t, newRepository(t)) })
+	t.Run("Test find ordering", func(t *testing.T) { testFindOrdering(t, newRepository(t)) })
+	t.Run("Test trash", func(t *testing.T) { testTrash(t, newRepository(t)) })
+	t.Run("Test upsert and stream", func(t *testing.T) { testUpsertAndStream(t, newRepository(t)) })
+	t.Run("Test sitemap pages", func(t *testing.T) { testSitemapPages(t, newRepository(t)) })
+	t.Run("Test concurrent writes", func(t *testing.T) { testConcurrentWrites(t, newRepository(t)) })
+}
+
+func mustCreate(t *testing.T, repo ports.ArticleRepository, articles ...*domain.Article) {
+	t.Helper()
+	for _, article := range articles {
+		if _, err := repo.CreateArticle(article); err != nil {
+			t.Fatalf("CreateArticle(%s): %v", article.ArticleID, err)
+		}
+	}
+}
+
+// sortedIDs returns the IDs of articles in ascending order
+func sortedIDs(articles *[]domain.Article) []string {
+	res := IDs(articles)
+	sort.Strings(res)
+	return res
+}
+
+// IDs returns the IDs of articles in their order
+func IDs(articles *[]domain.Article) []string {
+	res := []string{}
+	if articles == nil {
+		return res
+	}
+	for _, article := range *articles {
+		res = append(res, article.ArticleID)
+	}
+	return res
+}
+
+// assertArticle fails when got differs from want in a stored field
+func assertArticle(t *testing.T, got *domain.Article, want *domain.Article) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("Expected article %s, got nil", want.ArticleID)
+	}
+	gotCopy, wantCopy := *got, *want
+	if !gotCopy.PublishDate.Equal(wantCopy.PublishDate) || !gotCopy.UpdatedDate.Equal(wantCopy.UpdatedDate) {
+		t.Errorf("Expected dates %s and %s, got %s and %s", wantCopy.PublishDate, wantCopy.UpdatedDate, gotCopy.PublishDate, gotCopy.UpdatedDate)
+	}
+	gotCopy.PublishDate, wantCopy.PublishDate = time.Time{}, time.Time{}
+	gotCopy.UpdatedDate, wantCopy.UpdatedDate = time.Time{}, time.Time{}
+	gotCopy.DeletedAt, wantCopy.DeletedAt = nil, nil
+	// Stores may hand back no tags as either nil or empty
+	if len(gotCopy.Tags) == 0 && len(wantCopy.Tags) == 0 {
+		gotCopy.Tags, wantCopy.Tags = nil, nil
+	}
+	if !reflect.DeepEqual(gotCopy, wantCopy) {
+		t.Errorf("Expected article\n%+v\ngot\n%+v", wantCopy, gotCopy)
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo ports.ArticleRepository) {
+	article := NewArticle("1", "author-1", "go", "databases")
+	mustCreate(t, repo, article)
+
+	res, err := repo.GetArticleByID("1")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	assertArticle(t, res, article)
+
+	res, err = repo.GetArticleBySlug("article-1")
+	if err != nil {
+		t.Fatalf("GetArticleBySlug: %v", err)
+	}
+	assertArticle(t, res, article)
+
+	untagged := NewArticle("2", "author-1")
+	mustCreate(t, repo, untagged)
+	res, err = repo.GetArticleByID("2")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	assertArticle(t, res, untagged)
+
+	articles, err := repo.GetArticles()
+	if err != nil {
+		t.Fatalf("GetArticles: %v", err)
+	}
+	if ids := sortedIDs(articles); !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("Expected articles [1 2], got %v", ids)
+	}
+	if count, err := repo.CountPublishedArticles(); err != nil || count != 2 {
+		t.Errorf("Expected 2 published articles, got %d, %v", count, err)
+	}
+
+	if _, err := repo.CreateArticle(NewArticle("1", "author-1")); err == nil {
+		t.Errorf("Expected creating a duplicate ID to fail")
+	}
+}
+
+func testNotFound(t *testing.T, repo ports.ArticleRepository) {
+	mustCreate(t, repo, NewArticle("1", "author-1", "go"))
+
+	if res, err := repo.GetArticleByID("missing"); err == nil || res != nil {
+		t.Errorf("Expected GetArticleByID to fail, got %v, %v", res, err)
+	}
+	if res, err := repo.GetArticleBySlug("missing"); err == nil || res != nil {
+		t.Errorf("Expected GetArticleBySlug to fail, got %v, %v", res, err)
+	}
+	if res, err := repo.UpdateArticle("missing", &domain.Article{Title: "New"}); err == nil || res != nil {
+		t.Errorf("Expected UpdateArticle to fail, got %v, %v", res, err)
+	}
+	if err := repo.DeleteArticle("missing"); err == nil {
+		t.Errorf("Expected DeleteArticle to fail")
+	}
+	if res, err := repo.RestoreArticle("1"); err == nil || res != nil {
+		t.Errorf("Expected restoring an article outside the trash to fail, got %v, %v", res, err)
+	}
+
+	// Empty results are not errors
+	for name, query := range map[string]func() (*[]domain.Article, error){
+		"GetArticlesByAuthor": func() (*[]domain.Article, error) { return repo.GetArticlesByAuthor("nobody") },
+		"GetArticlesByTag":    func() (*[]domain.Article, error) { return repo.GetArticlesByTag("nothing") },
+		"GetDeletedArticles":  repo.GetDeletedArticles,
+	} {
+		articles, err := query()
+		if err != nil || articles == nil || len(*articles) != 0 {
+			t.Errorf("Expected %s to return no articles, got %v, %v", name, IDs(articles), err)
+		}
+	}
+	authors, err := repo.GetAuthors([]string{"nobody"})
+	if err != nil || len(authors) != 0 {
+		t.Errorf("Expected no authors, got %v, %v", authors, err)
+	}
+}
+
+func testUpdate(t *testing.T, repo ports.ArticleRepository) {
+	article := NewArticle("1", "author-1", "go")
+	mustCreate(t, repo, article)
+
+	updated := publishDate.Add(time.Hour)
+	update := &domain.Article{
+		Title:       "Renamed",
+		Body:        "New body",
+		Tags:        []string{"rust", "databases"},
+		UpdatedDate: updated,
+		AuthorID:    "author-1",
+		Author:      domain.Author{AuthorID: "author-1", Firstname: "Grace"},
+		WordCount:   2,
+	}
+	res, err := repo.UpdateArticle("1", update)
+	if err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+
+	want := article.ApplyUpdate(update)
+	assertArticle(t, res, &want)
+	stored, err := repo.GetArticleByID("1")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	assertArticle(t, stored, &want)
+
+	// Tags are replaced, not merged
+	if articles, err := repo.GetArticlesByTag("go"); err != nil || len(*articles) != 0 {
+		t.Errorf("Expected the old tag to be gone, got %v, %v", IDs(articles), err)
+	}
+	if articles, err := repo.GetArticlesByTag("rust"); err != nil || len(*articles) != 1 {
+		t.Errorf("Expected the new tag to be found, got %v, %v", IDs(articles), err)
+	}
+	authors, err := repo.GetAuthors([]string{"author-1"})
+	if err != nil || authors["author-1"].Firstname != "Grace" {
+		t.Errorf("Expected the updated author profile, got %v, %v", authors, err)
+	}
+}
+
+// testUpdateEveryField writes a distinct value to every editable field, so
+// that a value bound to the wrong column or an update of the wrong row shows
+func testUpdateEveryField(t *testing.T, repo ports.ArticleRepository) {
+	article, other := NewArticle("1", "author-1", "go"), NewArticle("2", "author-1", "go")
+	mustCreate(t, repo, article, other)
+
+	update := &domain.Article{
+		Title:        "New title",
+		Subtitle:     "New subtitle",
+		Introduction: "New introduction",
+		Body:         "New body of the article",
+		Tags:         []string{"rust"},
+		PublishDate:  publishDate.Add(24 * time.Hour),
+		UpdatedDate:  publishDate.Add(time.Hour),
+		AuthorID:     "author-2",
+		Author: domain.Author{
+			AuthorID:         "author-2",
+			Firstname:        "Grace",
+			Lastname:         "Hopper",
+			Handle:           "@grace",
+			SocialMediaLinks: []string{"https://example.com/grace"},
+		},
+		WordCount:      5,
+		ReadingMinutes: 2,
+		Excerpt:        "New excerpt",
+		Slug:           "new-title",
+	}
+	res, err := repo.UpdateArticle("1", update)
+	if err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	want := article.ApplyUpdate(update)
+	assertArticle(t, res, &want)
+	stored, err := repo.GetArticleByID("1")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	assertArticle(t, stored, &want)
+
+	if stored, err := repo.GetArticleByID("2"); err != nil {
+		t.Errorf("GetArticleByID: %v", err)
+	} else {
+		assertArticle(t, stored, other)
+	}
+	// An update never moves the article to another author
+	if articles, err := repo.GetArticlesByAuthor("author-2"); err != nil || len(*articles) != 0 {
+		t.Errorf("Expected the article to stay with author-1, got %v, %v", IDs(articles), err)
+	}
+	if stored.AuthorID != "author-1" || stored.Author.Firstname != article.Author.Firstname {
+		t.Errorf("Expected the author and profile to be kept, got %+v", stored.Author)
+	}
+	if _, err := repo.UpdateArticle("missing", update); err == nil {
+		t.Errorf("Expected updating a missing article to fail")
+	}
+}
+
+func testSlugs(t *testing.T, repo ports.ArticleRepository) {
+	mustCreate(t, repo, NewArticle("1", "author-1"), NewArticle("2", "author-1"))
+
+	if _, err := repo.CreateArticle(&domain.Article{ArticleID: "3", Title: "Clash", Slug: "article-1", PublishDate: publishDate, UpdatedDate: publishDate}); !errors.Is(err, domain.ErrSlugTaken) {
+		t.Errorf("Expected creating a taken slug to fail with ErrSlugTaken, got %v", err)
+	}
+	if _, err := repo.UpdateArticle("2", &domain.Article{Slug: "article-1"}); !errors.Is(err, domain.ErrSlugTaken) {
+		t.Errorf("Expected updating to a taken slug to fail with ErrSlugTaken, got %v", err)
+	}
+	if _, err := repo.UpdateArticle("1", &domain.Article{Slug: "renamed"}); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+
+	res, err := repo.GetArticleBySlug("article-1")
+	if err != nil || res.ArticleID != "1" || res.Slug != "renamed" {
+		t.Errorf("Expected the old slug to resolve to the renamed article, got %v, %v", res, err)
+	}
+	for _, c := range []struct {
+		slug, article_id string
+		taken            bool
+	}{
+		{"renamed", "1", false},
+		{"renamed", "2", true},
+		{"article-1", "2", true},
+		{"article-1", "1", false},
+		{"unused", "2", false},
+	} {
+		taken, err := repo.IsSlugTaken(c.slug, c.article_id)
+		if err != nil || taken != c.taken {
+			t.Errorf("IsSlugTaken(%s, %s): expected %v, got %v, %v", c.slug, c.article_id, c.taken, taken, err)
+		}
+	}
+}
+
+func testAuthorAndTag(t *testing.T, repo ports.ArticleRepository) {
+	mustCreate(t, repo,
+		NewArticle("1", "author-1", "go", "databases"),
+		NewArticle("2", "author-1", "go"),
+		NewArticle("3", "author-2", "rust"),
+		NewArticle("4", "author-2"),
+	)
+
+	for author_id, want := range map[string][]string{"author-1": {"1", "2"}, "author-2": {"3", "4"}} {
+		articles, err := repo.GetArticlesByAuthor(author_id)
+		if err != nil {
+			t.Fatalf("GetArticlesByAuthor(%s): %v", author_id, err)
+		}
+		if ids := sortedIDs(articles); !reflect.DeepEqual(ids, want) {
+			t.Errorf("Expected %s to have written %v, got %v", author_id, want, ids)
+		}
+	}
+	for tag, want := range map[string][]string{"go": {"1", "2"}, "databases": {"1"}, "rust": {"3"}} {
+		articles, err := repo.GetArticlesByTag(tag)
+		if err != nil {
+			t.Fatalf("GetArticlesByTag(%s): %v", tag, err)
+		}
+		if ids := sortedIDs(articles); !reflect.DeepEqual(ids, want) {
+			t.Errorf("Expected %v tagged %s, got %v", want, tag, ids)
+		}
+	}
+
+	authors, err := repo.GetAuthors([]string{"author-1", "author-2", "nobody"})
+	if err != nil {
+		t.Fatalf("GetAuthors: %v", err)
+	}
+	if len(authors) != 2 || authors["author-2"].Handle != "@author-2" {
+		t.Errorf("Expected the profiles of both authors, got %v", authors)
+	}
+}
+
+func testFindOrdering(t *testing.T, repo ports.ArticleRepository) {
+	for i, title := range []string{"Charlie", "Alpha", "Bravo", "Delta"} {
+		article := NewArticle(fmt.Sprint(i+1), "author-1", "Go")
+		article.Title = title
+		article.PublishDate = publishDate.AddDate(0, 0, i)
+		article.UpdatedDate = publishDate.AddDate(0, 0, 10-i)
+		mustCreate(t, repo, article)
+	}
+	// Ties on the sort field are broken by ID
+	tie := NewArticle("0", "author-2", "go")
+	tie.Title = "Alpha"
+	tie.PublishDate = publishDate
+	mustCreate(t, repo, tie)
+
+	scheduled := NewArticle("9", "author-2")
+	scheduled.PublishDate = time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	mustCreate(t, repo, scheduled)
+
+	for _, c := range []struct {
+		name  string
+		query domain.ArticleQuery
+		want  []string
+	}{
+		{"publish date", domain.ArticleQuery{Sort: "publish_date", Status: domain.ArticleStatusPublished}, []string{"0", "1", "2", "3", "4"}},
+		{"publish date descending", domain.ArticleQuery{Sort: "-publish_date"}, []string{"9", "4", "3", "2", "0", "1"}},
+		{"updated date", domain.ArticleQuery{Sort: "updated_date", AuthorID: "author-1"}, []string{"4", "3", "2", "1"}},
+		{"title", domain.ArticleQuery{Sort: "title", Tag: "go"}, []string{"0", "2", "3", "1", "4"}},
+		{"scheduled", domain.ArticleQuery{Status: domain.ArticleStatusScheduled}, []string{"9"}},
+		{"date range", domain.ArticleQuery{Sort: "publish_date", From: publishDate.AddDate(0, 0, 1), To: publishDate.AddDate(0, 0, 2)}, []string{"2", "3"}},
+	} {
+		articles, err := repo.FindArticles(c.query)
+		if err != nil {
+			t.Fatalf("FindArticles by %s: %v", c.name, err)
+		}
+		if ids := IDs(articles); !reflect.DeepEqual(ids, c.want) {
+			t.Errorf("Expected articles by %s in order %v, got %v", c.name, c.want, ids)
+		}
+
+		// Reading pages of two after the last article of the previous page
+		// returns the same articles
+		paged := []string{}
+		page := c.query
+		page.Limit = 2
+		for len(paged) <= len(c.want) {
+			articles, err := repo.FindArticles(page)
+			if err != nil {
+				t.Fatalf("FindArticles by %s after %+v: %v", c.name, page.After, err)
+			}
+			paged = append(paged, IDs(articles)...)
+			if len(*articles) < page.Limit {
+				break
+			}
+			page.After = page.CursorOf((*articles)[len(*articles)-1])
+		}
+		if !reflect.DeepEqual(paged, c.want) {
+			t.Errorf("Expected pages by %s in order %v, got %v", c.name, c.want, paged)
+		}
+	}
+
+	summaries, err := repo.FindArticles(domain.ArticleQuery{Summary: true})
+	if err != nil {
+		t.Fatalf("FindArticles summaries: %v", err)
+	}
+	for _, article := range *summaries {
+		if article.Body != "" || article.Title == "" {
+			t.Errorf("Expected summaries without bodies, got %+v", article)
+		}
+	}
+}
+
+func testTrash(t *testing.T, repo ports.ArticleRepository) {
+	mustCreate(t, repo, NewArticle("1", "author-1", "go"), NewArticle("2", "author-1", "go"), NewArticle("3", "author-1"))
+
+	for _, article_id := range []string{"1", "2"} {
+		if err := repo.DeleteArticle(article_id); err != nil {
+			t.Fatalf("DeleteArticle(%s): %v", article_id, err)
+		}
+		// Keeps the deletion times apart for the ordering below
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := repo.DeleteArticle("1"); err == nil {
+		t.Errorf("Expected deleting a trashed article to fail")
+	}
+
+	if _, err := repo.GetArticleByID("1"); err == nil {
+		t.Errorf("Expected trashed articles to be hidden")
+	}
+	if articles, err := repo.GetArticlesByTag("go"); err != nil || len(*articles) != 0 {
+		t.Errorf("Expected trashed articles to be left out of tag queries, got %v, %v", IDs(articles), err)
+	}
+	if articles, err := repo.GetArticles(); err != nil || !reflect.DeepEqual(IDs(articles), []string{"3"}) {
+		t.Errorf("Expected only article 3 to be listed, got %v, %v", IDs(articles), err)
+	}
+
+	deleted, err := repo.GetDeletedArticles()
+	if err != nil {
+		t.Fatalf("GetDeletedArticles: %v", err)
+	}
+	if ids := IDs(deleted); !reflect.DeepEqual(ids, []string{"2", "1"}) {
+		t.Errorf("Expected the trash newest first [2 1], got %v", ids)
+	}
+	for _, article := range *deleted {
+		if article.DeletedAt == nil {
+			t.Errorf("Expected article %s to carry its deletion time", article.ArticleID)
+		}
+	}
+
+	restored, err := repo.RestoreArticle("1")
+	if err != nil || restored.ArticleID != "1" || restored.DeletedAt != nil {
+		t.Fatalf("Expected article 1 restored, got %v, %v", restored, err)
+	}
+	if _, err := repo.GetArticleByID("1"); err != nil {
+		t.Errorf("Expected the restored article to be found, got %v", err)
+	}
+
+	purged, _, err := repo.PurgeDeletedArticles(time.Now().Add(time.Minute))
+	if err != nil || purged != 1 {
+		t.Errorf("Expected one article purged, got %d, %v", purged, err)
+	}
+	if deleted, err := repo.GetDeletedArticles(); err != nil || len(*deleted) != 0 {
+		t.Errorf("Expected an empty trash, got %v, %v", IDs(deleted), err)
+	}
+
+	if err := repo.DeleteArticleAll(); err != nil {
+		t.Fatalf("DeleteArticleAll: %v", err)
+	}
+	if articles, err := repo.GetArticles(); err != nil || len(*articles) != 0 {
+		t.Errorf("Expected no articles left, got %v, %v", IDs(articles), err)
+	}
+}
+
+func testUpsertAndStream(t *testing.T, repo ports.ArticleRepository) {
+	mustCreate(t, repo, NewArticle("1", "author-1", "go"))
+
+	replaced := NewArticle("1", "author-1", "rust")
+	replaced.Title = "Replaced"
+	clash := NewArticle("3", "author-1")
+	clash.Slug = "article-2"
+	results, err := repo.UpsertArticles([]domain.Article{*replaced, *NewArticle("2", "author-2"), *clash})
+	if err != nil {
+		t.Fatalf("UpsertArticles: %v", err)
+	}
+	if len(results) != 3 || results[0] != nil || results[1] != nil || results[2] == nil {
+		t.Fatalf("Expected only the slug clash to fail, got %v", results)
+	}
+
+	res, err := repo.GetArticleByID("1")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	assertArticle(t, res, replaced)
+	if articles, err := repo.GetArticlesByTag("go"); err != nil || len(*articles) != 0 {
+		t.Errorf("Expected the replaced tags to be gone, got %v, %v", IDs(articles), err)
+	}
+
+	streamed := []string{}
+	err = repo.StreamArticles(func(article *domain.Article) error {
+		streamed = append(streamed, article.ArticleID)
+		return nil
+	})
+	sort.Strings(streamed)
+	if err != nil || !reflect.DeepEqual(streamed, []string{"1", "2"}) {
+		t.Errorf("Expected articles [1 2] streamed, got %v, %v", streamed, err)
+	}
+
+	stop := fmt.Errorf("stop")
+	calls := 0
+	err = repo.StreamArticles(func(article *domain.Article) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected streaming to stop at the first error, got %d calls and %v", calls, err)
+	}
+}
+
+func testSitemapPages(t *testing.T, repo ports.ArticleRepository) {
+	for _, article_id := range []string{"c", "a", "e", "b", "d"} {
+		mustCreate(t, repo, NewArticle(article_id, "author-1"))
+	}
+	scheduled := NewArticle("f", "author-1")
+	scheduled.PublishDate = time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	mustCreate(t, repo, scheduled)
+
+	pages := [][]string{}
+	after := ""
+	for {
+		entries, err := repo.GetSitemapPage(after, 0, 2)
+		if err != nil {
+			t.Fatalf("GetSitemapPage(%q): %v", after, err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		page := []string{}
+		for _, entry := range entries {
+			page = append(page, entry.ArticleID)
+			if entry.Slug != "article-"+entry.ArticleID || !entry.UpdatedDate.Equal(publishDate) {
+				t.Errorf("Unexpected sitemap entry %+v", entry)
+			}
+		}
+		pages = append(pages, page)
+		after = page[len(page)-1]
+	}
+	if want := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("Expected sitemap pages %v, got %v", want, pages)
+	}
+
+	// A part of the sitemap starts at an offset
+	entries, err := repo.GetSitemapPage("", 3, 2)
+	if err != nil {
+		t.Fatalf("GetSitemapPage at offset 3: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ArticleID != "d" || entries[1].ArticleID != "e" {
+		t.Errorf("Expected entries d and e at offset 3, got %+v", entries)
+	}
+	if entries, _ := repo.GetSitemapPage("b", 1, 2); len(entries) != 2 || entries[0].ArticleID != "d" {
+		t.Errorf("Expected the offset to count after b, got %+v", entries)
+	}
+}
+
+func testConcurrentWrites(t *testing.T, repo ports.ArticleRepository) {
+	const writers = 8
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := repo.CreateArticle(NewArticle(fmt.Sprint(i), "author-1", "go")); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	titles := map[string]bool{}
+	for i := 0; i < writers; i++ {
+		title := fmt.Sprint("Title ", i)
+		titles[title] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.UpdateArticle("0", &domain.Article{Title: title, Tags: []string{"go"}}); err != nil {
+				errs <- err
+			}
+			if _, err := repo.GetArticlesByAuthor("author-1"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent write failed: %v", err)
+	}
+
+	articles, err := repo.GetArticlesByTag("go")
+	if err != nil || len(*articles) != writers {
+		t.Errorf("Expected %d articles, got %v, %v", writers, IDs(articles), err)
+	}
+	res, err := repo.GetArticleByID("0")
+	if err != nil || !titles[res.Title] {
+		t.Errorf("Expected the title of one of the updates, got %v, %v", res, err)
+	}
+}
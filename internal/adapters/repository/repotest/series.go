@@ -0,0 +1,171 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// SeriesStore is an article repository that also keeps series
+type SeriesStore interface {
+	ports.ArticleRepository
+	ports.SeriesRepository
+}
+
+// NewSeriesRepository returns an empty repository. It is called once per
+// subtest.
+type NewSeriesRepository func(t *testing.T) SeriesStore
+
+// NewSeries returns a series of the articles whose fields are all set
+func NewSeries(id, author_id string, article_ids ...string) *domain.Series {
+	return &domain.Series{
+		SeriesID:    id,
+		AuthorID:    author_id,
+		Title:       "Series " + id,
+		Description: "Description of " + id,
+		ArticleIDs:  article_ids,
+		CreatedDate: publishDate,
+		UpdatedDate: publishDate,
+	}
+}
+
+// RunSeriesRepository runs the series contract against repositories made by
+// newRepository.
+func RunSeriesRepository(t *testing.T, newRepository NewSeriesRepository) {
+	t.Run("Test series", func(t *testing.T) { testSeries(t, newRepository(t)) })
+	t.Run("Test series membership", func(t *testing.T) { testSeriesMembership(t, newRepository(t)) })
+}
+
+func mustCreateSeries(t *testing.T, repo SeriesStore, series ...*domain.Series) {
+	t.Helper()
+	for _, s := range series {
+		if _, err := repo.CreateSeries(s); err != nil {
+			t.Fatalf("CreateSeries(%s): %v", s.SeriesID, err)
+		}
+	}
+}
+
+// assertSeries fails when got differs from want in a stored field
+func assertSeries(t *testing.T, got *domain.Series, want *domain.Series) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("Expected series %s, got nil", want.SeriesID)
+	}
+	gotCopy, wantCopy := *got, *want
+	if !gotCopy.CreatedDate.Equal(wantCopy.CreatedDate) || !gotCopy.UpdatedDate.Equal(wantCopy.UpdatedDate) {
+		t.Errorf("Expected dates %s and %s, got %s and %s", wantCopy.CreatedDate, wantCopy.UpdatedDate, gotCopy.CreatedDate, gotCopy.UpdatedDate)
+	}
+	gotCopy.CreatedDate, wantCopy.CreatedDate = time.Time{}, time.Time{}
+	gotCopy.UpdatedDate, wantCopy.UpdatedDate = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(gotCopy, wantCopy) {
+		t.Errorf("Expected series\n%+v\ngot\n%+v", wantCopy, gotCopy)
+	}
+}
+
+func seriesIDs(list *[]domain.Series) []string {
+	ids := []string{}
+	if list == nil {
+		return ids
+	}
+	for _, series := range *list {
+		ids = append(ids, series.SeriesID)
+	}
+	return ids
+}
+
+func testSeries(t *testing.T, repo SeriesStore) {
+	mustCreate(t, repo, NewArticle("1", "author-1"), NewArticle("2", "author-1"), NewArticle("3", "author-1"))
+	want := NewSeries("s1", "author-1", "2", "1")
+	mustCreateSeries(t, repo, want)
+	// Later series are listed after earlier ones
+	later := NewSeries("s2", "author-2")
+	later.CreatedDate = publishDate.Add(time.Hour)
+	mustCreateSeries(t, repo, later)
+
+	got, err := repo.GetSeries("s1")
+	if err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	assertSeries(t, got, want)
+	if empty, err := repo.GetSeries("s2"); err != nil || empty.ArticleIDs == nil || len(empty.ArticleIDs) != 0 {
+		t.Errorf("Expected a series without articles to hold an empty list, got %+v, %v", empty, err)
+	}
+	if _, err := repo.GetSeries("missing"); err == nil {
+		t.Errorf("Expected an error for a missing series")
+	}
+
+	if list, err := repo.GetSeriesList(""); err != nil || !reflect.DeepEqual(seriesIDs(list), []string{"s1", "s2"}) {
+		t.Errorf("Expected every series oldest first, got %v, %v", seriesIDs(list), err)
+	}
+	if list, err := repo.GetSeriesList("author-2"); err != nil || !reflect.DeepEqual(seriesIDs(list), []string{"s2"}) {
+		t.Errorf("Expected the series of author-2, got %v, %v", seriesIDs(list), err)
+	}
+
+	update := NewSeries("s1", "author-1", "3", "2", "1")
+	update.Title = "Renamed"
+	update.UpdatedDate = publishDate.Add(time.Minute)
+	if _, err := repo.UpdateSeries("s1", update); err != nil {
+		t.Fatalf("UpdateSeries: %v", err)
+	}
+	if got, err = repo.GetSeries("s1"); err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	assertSeries(t, got, update)
+	if _, err := repo.UpdateSeries("missing", NewSeries("missing", "author-1")); err == nil {
+		t.Errorf("Expected updating a missing series to fail")
+	}
+
+	if err := repo.DeleteSeries("s1"); err != nil {
+		t.Fatalf("DeleteSeries: %v", err)
+	}
+	if err := repo.DeleteSeries("s1"); err == nil {
+		t.Errorf("Expected deleting a missing series to fail")
+	}
+	if series, err := repo.GetArticleSeries("1"); err != nil || series != nil {
+		t.Errorf("Expected the articles to leave the deleted series, got %+v, %v", series, err)
+	}
+	if _, err := repo.GetArticleByID("1"); err != nil {
+		t.Errorf("Expected the articles of the deleted series kept, got %v", err)
+	}
+}
+
+func testSeriesMembership(t *testing.T, repo SeriesStore) {
+	mustCreate(t, repo, NewArticle("1", "author-1"), NewArticle("2", "author-1"), NewArticle("3", "author-1"))
+	mustCreateSeries(t, repo, NewSeries("s1", "author-1", "1", "2"))
+
+	series, err := repo.GetArticleSeries("2")
+	if err != nil || series == nil || series.SeriesID != "s1" {
+		t.Fatalf("Expected article 2 in s1, got %+v, %v", series, err)
+	}
+	if series, err := repo.GetArticleSeries("3"); err != nil || series != nil {
+		t.Errorf("Expected article 3 in no series, got %+v, %v", series, err)
+	}
+
+	// An article belongs to one series at most
+	if _, err := repo.CreateSeries(NewSeries("s2", "author-1", "3", "1")); err == nil {
+		t.Errorf("Expected a second series of article 1 to fail")
+	}
+	if _, err := repo.GetSeries("s2"); err == nil {
+		t.Errorf("Expected the failed series not to be stored")
+	}
+	if series, _ := repo.GetArticleSeries("3"); series != nil {
+		t.Errorf("Expected article 3 left out with the failed series, got %+v", series)
+	}
+
+	// Trashed articles keep their place, purged ones leave
+	if err := repo.DeleteArticle("1"); err != nil {
+		t.Fatalf("DeleteArticle: %v", err)
+	}
+	if series, _ := repo.GetSeries("s1"); !reflect.DeepEqual(series.ArticleIDs, []string{"1", "2"}) {
+		t.Errorf("Expected the trashed article kept in the series, got %v", series.ArticleIDs)
+	}
+	if _, _, err := repo.PurgeDeletedArticles(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("PurgeDeletedArticles: %v", err)
+	}
+	if series, _ := repo.GetSeries("s1"); !reflect.DeepEqual(series.ArticleIDs, []string{"2"}) {
+		t.Errorf("Expected the purged article gone from the series, got %v", series.ArticleIDs)
+	}
+}
@@ -0,0 +1,74 @@
+package s3
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// s3Store keeps blobs as objects of an S3 bucket, named by their key.
+type s3Store struct {
+	client s3iface.S3API
+	bucket string
+}
+
+// NewS3Store stores blobs in S3_BUCKET of S3_REGION, or of the S3-compatible
+// store at S3_ENDPOINT. Credentials come from the usual AWS environment
+// variables, shared configuration or instance role.
+func NewS3Store(conf appConfig.Config) (*s3Store, error) {
+	awsConf := aws.NewConfig().
+		WithRegion(conf.S3_REGION).
+		WithS3ForcePathStyle(conf.S3_FORCE_PATH_STYLE)
+	if conf.S3_ENDPOINT != "" {
+		awsConf = awsConf.WithEndpoint(conf.S3_ENDPOINT)
+	}
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, err
+	}
+	return newStore(s3.New(sess), conf.S3_BUCKET), nil
+}
+
+func newStore(client s3iface.S3API, bucket string) *s3Store {
+	return &s3Store{client: client, bucket: bucket}
+}
+
+func (store *s3Store) Put(key string, content_type string, content io.ReadSeeker) error {
+	_, err := store.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(store.bucket),
+		Key:         aws.String(key),
+		Body:        content,
+		ContentType: aws.String(content_type),
+	})
+	return err
+}
+
+func (store *s3Store) Get(key string) (io.ReadCloser, error) {
+	result, err := store.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(store.bucket),
+		Key:    aws.String(key),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
+
+// Delete succeeds for keys that are not stored, as S3 does
+func (store *s3Store) Delete(key string) error {
+	_, err := store.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(store.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
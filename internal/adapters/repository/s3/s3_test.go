@@ -0,0 +1,74 @@
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 keeps the objects of one bucket in memory
+type fakeS3 struct {
+	s3iface.S3API
+	objects      map[string][]byte
+	contentTypes map[string]string
+}
+
+func (f *fakeS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	content, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)] = content
+	f.contentTypes[aws.StringValue(input.Key)] = aws.StringValue(input.ContentType)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	content, ok := f.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+}
+
+func (f *fakeS3) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestPutGetDelete(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, contentTypes: map[string]string{}}
+	store := newStore(fake, "media")
+
+	if err := store.Put("articles/1/a.png", "image/png", strings.NewReader("png")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if string(fake.objects["media/articles/1/a.png"]) != "png" || fake.contentTypes["articles/1/a.png"] != "image/png" {
+		t.Errorf("Expected the object in the bucket with its content type, got %v", fake.objects)
+	}
+
+	blob, err := store.Get("articles/1/a.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	content, _ := io.ReadAll(blob)
+	blob.Close()
+	if string(content) != "png" {
+		t.Errorf("Expected the stored content, got %q", content)
+	}
+
+	if err := store.Delete("articles/1/a.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("articles/1/a.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+const mediaColumns = `media_id, article_id, key, url, filename, content_type, size, width, height, created_date, variants`
+
+func scanMedia(row rowScanner) (*domain.Media, error) {
+	var media domain.Media
+	var createdDate string
+	var variants sql.NullString
+	err := row.Scan(
+		&media.MediaID,
+		&media.ArticleID,
+		&media.Key,
+		&media.URL,
+		&media.Filename,
+		&media.ContentType,
+		&media.Size,
+		&media.Width,
+		&media.Height,
+		&createdDate,
+		&variants,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if media.CreatedDate, err = time.Parse(timeLayout, createdDate); err != nil {
+		return nil, err
+	}
+	if variants.Valid {
+		if err := json.Unmarshal([]byte(variants.String), &media.Variants); err != nil {
+			return nil, err
+		}
+	}
+	return &media, nil
+}
+
+// scanMediaRows reads every row of rows and closes them
+func scanMediaRows(rows *sql.Rows) ([]domain.Media, error) {
+	defer rows.Close()
+	media := []domain.Media{}
+	for rows.Next() {
+		item, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *item)
+	}
+	return media, rows.Err()
+}
+
+func (lite *sqliteDBClient) CreateMedia(media *domain.Media) (*domain.Media, error) {
+	variantsJSON, err := json.Marshal(media.Variants)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, lite.mediaTable, mediaColumns)
+
+	_, err = lite.db.Exec(
+		query,
+		media.MediaID,
+		media.ArticleID,
+		media.Key,
+		media.URL,
+		media.Filename,
+		media.ContentType,
+		media.Size,
+		media.Width,
+		media.Height,
+		formatTime(media.CreatedDate),
+		string(variantsJSON),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (lite *sqliteDBClient) GetMedia(media_id string) (*domain.Media, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE media_id = ?`, mediaColumns, lite.mediaTable)
+	return scanMedia(lite.db.QueryRow(query, media_id))
+}
+
+func (lite *sqliteDBClient) GetArticleMedia(article_id string) (*[]domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE article_id = ?
+		ORDER BY created_date, media_id`, mediaColumns, lite.mediaTable)
+	rows, err := lite.db.Query(query, article_id)
+	if err != nil {
+		return nil, err
+	}
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (lite *sqliteDBClient) DeleteMedia(media_id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE media_id = ?`, lite.mediaTable)
+	res, err := lite.db.Exec(query, media_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) UpdateMediaVariants(media_id string, variants []domain.ImageVariant) error {
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`UPDATE %s SET variants = ? WHERE media_id = ?`, lite.mediaTable)
+	res, err := lite.db.Exec(query, string(variantsJSON), media_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) SetArticleCover(article_id string, cover_image string, updated_date time.Time) (*domain.Article, error) {
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET cover_image = ?, cover_variants = NULL, updated_date = ?
+		WHERE article_id = ? AND deleted_at IS NULL`, lite.tablename)
+	res, err := tx.Exec(query, cover_image, formatTime(updated_date), article_id)
+	if err != nil {
+		return nil, err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return nil, sql.ErrNoRows
+	}
+	query = fmt.Sprintf(`SELECT %s FROM %s WHERE article_id = ?`, articleColumns, lite.tablename)
+	article, err := scanArticle(tx.QueryRow(query, article_id))
+	if err != nil {
+		return nil, err
+	}
+	return article, tx.Commit()
+}
+
+func (lite *sqliteDBClient) SetCoverVariants(article_id string, cover_image string, variants []domain.ImageVariant) error {
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s SET cover_variants = ?
+		WHERE article_id = ? AND cover_image = ?`, lite.tablename)
+	_, err = lite.db.Exec(query, string(variantsJSON), article_id, cover_image)
+	return err
+}
@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// seriesColumns selects a series with its articles in reading order, as a
+// JSON array. The series table is aliased s.
+const seriesColumns = `s.series_id, s.author_id, s.title, s.description, s.created_date, s.updated_date,
+	(SELECT json_group_array(article_id) FROM (SELECT m.article_id FROM %s m WHERE m.series_id = s.series_id ORDER BY m.position))`
+
+func (lite *sqliteDBClient) seriesSelect() string {
+	return fmt.Sprintf(`SELECT `+seriesColumns+` FROM %s s`, lite.memberTable, lite.seriesTable)
+}
+
+func scanSeries(row rowScanner) (*domain.Series, error) {
+	var series domain.Series
+	var createdDate, updatedDate, articleIDs string
+	err := row.Scan(
+		&series.SeriesID,
+		&series.AuthorID,
+		&series.Title,
+		&series.Description,
+		&createdDate,
+		&updatedDate,
+		&articleIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if series.CreatedDate, err = time.Parse(timeLayout, createdDate); err != nil {
+		return nil, err
+	}
+	if series.UpdatedDate, err = time.Parse(timeLayout, updatedDate); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(articleIDs), &series.ArticleIDs); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (lite *sqliteDBClient) CreateSeries(series *domain.Series) (*domain.Series, error) {
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (series_id, author_id, title, description, created_date, updated_date)
+		VALUES (?, ?, ?, ?, ?, ?)`, lite.seriesTable)
+	_, err = tx.Exec(query, series.SeriesID, series.AuthorID, series.Title, series.Description, formatTime(series.CreatedDate), formatTime(series.UpdatedDate))
+	if err != nil {
+		return nil, err
+	}
+	if err := lite.setSeriesArticles(tx, series); err != nil {
+		return nil, err
+	}
+	return series, tx.Commit()
+}
+
+func (lite *sqliteDBClient) GetSeries(series_id string) (*domain.Series, error) {
+	query := lite.seriesSelect() + ` WHERE s.series_id = ?`
+	return scanSeries(lite.db.QueryRow(query, series_id))
+}
+
+func (lite *sqliteDBClient) GetSeriesList(author_id string) (*[]domain.Series, error) {
+	query := lite.seriesSelect()
+	args := []interface{}{}
+	if author_id != "" {
+		query += ` WHERE s.author_id = ?`
+		args = append(args, author_id)
+	}
+	rows, err := lite.db.Query(query+` ORDER BY s.created_date, s.series_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Series{}
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *series)
+	}
+	return &list, rows.Err()
+}
+
+func (lite *sqliteDBClient) UpdateSeries(series_id string, series *domain.Series) (*domain.Series, error) {
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET title = ?, description = ?, updated_date = ?
+		WHERE series_id = ?`, lite.seriesTable)
+	res, err := tx.Exec(query, series.Title, series.Description, formatTime(series.UpdatedDate), series_id)
+	if err != nil {
+		return nil, err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return nil, sql.ErrNoRows
+	}
+	series.SeriesID = series_id
+	if err := lite.setSeriesArticles(tx, series); err != nil {
+		return nil, err
+	}
+	return series, tx.Commit()
+}
+
+// setSeriesArticles replaces the articles of the series, numbering them in
+// the order given
+func (lite *sqliteDBClient) setSeriesArticles(tx *sql.Tx, series *domain.Series) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE series_id = ?`, lite.memberTable)
+	if _, err := tx.Exec(query, series.SeriesID); err != nil {
+		return err
+	}
+	query = fmt.Sprintf(`INSERT INTO %s (article_id, series_id, position) VALUES (?, ?, ?)`, lite.memberTable)
+	for i, article_id := range series.ArticleIDs {
+		if _, err := tx.Exec(query, article_id, series.SeriesID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) DeleteSeries(series_id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE series_id = ?`, lite.seriesTable)
+	res, err := lite.db.Exec(query, series_id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err == nil && count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) GetArticleSeries(article_id string) (*domain.Series, error) {
+	query := lite.seriesSelect() + fmt.Sprintf(`
+		WHERE s.series_id = (SELECT series_id FROM %s WHERE article_id = ?)`, lite.memberTable)
+	series, err := scanSeries(lite.db.QueryRow(query, article_id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return series, err
+}
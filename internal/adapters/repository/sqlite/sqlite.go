@@ -0,0 +1,877 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	_ "modernc.org/sqlite"
+)
+
+// articleColumnNames are the columns selected by every article query, in
+// the order scanArticle expects them.
+var articleColumnNames = []string{
+	"article_id",
+	"title",
+	"subtitle",
+	"introduction",
+	"body",
+	"tags",
+	"publish_date",
+	"updated_date",
+	"author",
+	"author_id",
+	"word_count",
+	"reading_minutes",
+	"excerpt",
+	"slug",
+	"cover_image",
+	"cover_variants",
+	"deleted_at",
+}
+
+// articleColumns is the column list of articleColumnNames
+var articleColumns = selectColumns("", false)
+
+// selectColumns lists articleColumnNames of the table aliased alias, or of
+// the only table when alias is empty. Summaries select an empty body in
+// place of the body.
+func selectColumns(alias string, summary bool) string {
+	columns := make([]string, len(articleColumnNames))
+	for i, name := range articleColumnNames {
+		switch {
+		case summary && name == "body":
+			columns[i] = "'' AS body"
+		case alias != "":
+			columns[i] = alias + "." + name
+		default:
+			columns[i] = name
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// timeLayout stores times in UTC with a fixed width, so that comparing and
+// sorting the text compares the times
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteDBClient stores articles, their media and series in a SQLite
+// database file. Like the DynamoDB client it keeps no outbox or webhook
+// subscriptions.
+type sqliteDBClient struct {
+	db        *sql.DB
+	tablename string
+	// slugTable keeps the previous slugs of articles whose title changed
+	slugTable string
+	// searchTable is the FTS5 index over the text of the articles
+	searchTable string
+	// mediaTable records the media uploaded for articles
+	mediaTable string
+	// seriesTable holds series of articles, memberTable the place of each
+	// article in its series
+	seriesTable string
+	memberTable string
+}
+
+// NewSQLiteClient opens, or creates, the database at SQLITE_PATH and brings
+// its schema up to date.
+func NewSQLiteClient(conf appConfig.Config) (*sqliteDBClient, error) {
+	// SQLite allows a single writer at a time. One connection serialises
+	// writes instead of failing them with SQLITE_BUSY, and lets ":memory:"
+	// databases be shared by every query.
+	db, err := sql.Open("sqlite", conf.SQLITE_PATH+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	tablename := conf.ARTICLE_TABLE
+	if err := migrate(db, tablename); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteDBClient{
+		db:          db,
+		tablename:   tablename,
+		slugTable:   tablename + "_slugs",
+		searchTable: tablename + "_search",
+		mediaTable:  tablename + "_media",
+		seriesTable: tablename + "_series",
+		memberTable: tablename + "_series_articles",
+	}, nil
+}
+
+// migrations are applied in order, each once. Their number is recorded in
+// the migrations table, so new migrations are only ever appended.
+var migrations = []string{
+	`CREATE TABLE %[1]s (
+		id INTEGER PRIMARY KEY,
+		article_id TEXT NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		subtitle TEXT NOT NULL DEFAULT '',
+		introduction TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(tags)),
+		publish_date TEXT NOT NULL,
+		updated_date TEXT NOT NULL,
+		author TEXT NOT NULL CHECK (json_valid(author)),
+		author_id TEXT NOT NULL DEFAULT '',
+		word_count INTEGER NOT NULL DEFAULT 0,
+		reading_minutes INTEGER NOT NULL DEFAULT 0,
+		excerpt TEXT NOT NULL DEFAULT '',
+		slug TEXT NOT NULL,
+		deleted_at TEXT
+	);
+	CREATE UNIQUE INDEX %[1]s_slug_idx ON %[1]s (slug);
+	CREATE INDEX %[1]s_author_idx ON %[1]s (author_id);
+	CREATE TABLE %[1]s_slugs (
+		slug TEXT PRIMARY KEY,
+		article_id TEXT NOT NULL
+	)`,
+	// The search index reads the text from the articles table through its
+	// id, which unlike an implicit rowid survives VACUUM. The triggers keep
+	// the index in step.
+	`CREATE VIRTUAL TABLE %[1]s_search USING fts5(
+		title, subtitle, introduction, body, tags,
+		content = '%[1]s', content_rowid = 'id'
+	);
+	CREATE TRIGGER %[1]s_search_insert AFTER INSERT ON %[1]s BEGIN
+		INSERT INTO %[1]s_search (rowid, title, subtitle, introduction, body, tags)
+		VALUES (new.id, new.title, new.subtitle, new.introduction, new.body, new.tags);
+	END;
+	CREATE TRIGGER %[1]s_search_delete AFTER DELETE ON %[1]s BEGIN
+		INSERT INTO %[1]s_search (%[1]s_search, rowid, title, subtitle, introduction, body, tags)
+		VALUES ('delete', old.id, old.title, old.subtitle, old.introduction, old.body, old.tags);
+	END;
+	CREATE TRIGGER %[1]s_search_update AFTER UPDATE ON %[1]s BEGIN
+		INSERT INTO %[1]s_search (%[1]s_search, rowid, title, subtitle, introduction, body, tags)
+		VALUES ('delete', old.id, old.title, old.subtitle, old.introduction, old.body, old.tags);
+		INSERT INTO %[1]s_search (rowid, title, subtitle, introduction, body, tags)
+		VALUES (new.id, new.title, new.subtitle, new.introduction, new.body, new.tags);
+	END`,
+	`ALTER TABLE %[1]s ADD COLUMN cover_image TEXT NOT NULL DEFAULT '';
+	CREATE TABLE %[1]s_media (
+		media_id TEXT PRIMARY KEY,
+		article_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		url TEXT NOT NULL,
+		filename TEXT NOT NULL DEFAULT '',
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		width INTEGER NOT NULL DEFAULT 0,
+		height INTEGER NOT NULL DEFAULT 0,
+		created_date TEXT NOT NULL
+	);
+	CREATE INDEX %[1]s_media_article_idx ON %[1]s_media (article_id, created_date)`,
+	`ALTER TABLE %[1]s ADD COLUMN cover_variants TEXT;
+	ALTER TABLE %[1]s_media ADD COLUMN variants TEXT`,
+	// The article is the key, so that it belongs to one series at most.
+	// Purged articles leave their series.
+	`CREATE TABLE %[1]s_series (
+		series_id TEXT PRIMARY KEY,
+		author_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		created_date TEXT NOT NULL,
+		updated_date TEXT NOT NULL
+	);
+	CREATE INDEX %[1]s_series_author_idx ON %[1]s_series (author_id, created_date);
+	CREATE TABLE %[1]s_series_articles (
+		article_id TEXT PRIMARY KEY REFERENCES %[1]s (article_id) ON DELETE CASCADE,
+		series_id TEXT NOT NULL REFERENCES %[1]s_series (series_id) ON DELETE CASCADE,
+		position INTEGER NOT NULL
+	);
+	CREATE INDEX %[1]s_series_articles_series_idx ON %[1]s_series_articles (series_id, position)`,
+}
+
+// migrate applies the migrations the database has not seen yet, each in a
+// transaction of its own.
+func migrate(db *sql.DB, tablename string) error {
+	_, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_migrations (version INTEGER PRIMARY KEY)`, tablename))
+	if err != nil {
+		return err
+	}
+	var applied int
+	err = db.QueryRow(fmt.Sprintf(`SELECT COALESCE(MAX(version), 0) FROM %s_migrations`, tablename)).Scan(&applied)
+	if err != nil {
+		return err
+	}
+
+	for version := applied + 1; version <= len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(fmt.Sprintf(migrations[version-1], tablename)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s_migrations (version) VALUES (?)`, tablename), version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArticle(row rowScanner) (*domain.Article, error) {
+	article := &domain.Article{}
+	var tags, author, publishDate, updatedDate string
+	var variants, deletedAt sql.NullString
+	err := row.Scan(
+		&article.ArticleID,
+		&article.Title,
+		&article.Subtitle,
+		&article.Introduction,
+		&article.Body,
+		&tags,
+		&publishDate,
+		&updatedDate,
+		&author,
+		&article.AuthorID,
+		&article.WordCount,
+		&article.ReadingMinutes,
+		&article.Excerpt,
+		&article.Slug,
+		&article.CoverImage,
+		&variants,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &article.Tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(author), &article.Author); err != nil {
+		return nil, err
+	}
+	if variants.Valid {
+		if err := json.Unmarshal([]byte(variants.String), &article.CoverVariants); err != nil {
+			return nil, err
+		}
+	}
+	if article.PublishDate, err = time.Parse(timeLayout, publishDate); err != nil {
+		return nil, err
+	}
+	if article.UpdatedDate, err = time.Parse(timeLayout, updatedDate); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		t, err := time.Parse(timeLayout, deletedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		article.DeletedAt = &t
+	}
+	return article, nil
+}
+
+func scanArticles(rows *sql.Rows) (*[]domain.Article, error) {
+	defer rows.Close()
+	articles := []domain.Article{}
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, *article)
+	}
+	return &articles, rows.Err()
+}
+
+// articleValues are the values of articleColumns for article
+func articleValues(article *domain.Article) ([]interface{}, error) {
+	tags := article.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+	authorJSON, err := json.Marshal(article.Author)
+	if err != nil {
+		return nil, err
+	}
+	variantsJSON, err := json.Marshal(article.CoverVariants)
+	if err != nil {
+		return nil, err
+	}
+	var deletedAt interface{}
+	if article.DeletedAt != nil {
+		deletedAt = formatTime(*article.DeletedAt)
+	}
+	return []interface{}{
+		article.ArticleID,
+		article.Title,
+		article.Subtitle,
+		article.Introduction,
+		article.Body,
+		string(tagsJSON),
+		formatTime(article.PublishDate),
+		formatTime(article.UpdatedDate),
+		string(authorJSON),
+		article.AuthorID,
+		article.WordCount,
+		article.ReadingMinutes,
+		article.Excerpt,
+		article.Slug,
+		article.CoverImage,
+		string(variantsJSON),
+		deletedAt,
+	}, nil
+}
+
+func (lite *sqliteDBClient) CreateArticle(article *domain.Article) (*domain.Article, error) {
+	values, err := articleValues(article)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, lite.tablename, articleColumns)
+	if _, err := lite.db.Exec(query, values...); err != nil {
+		return nil, lite.slugError(err)
+	}
+	return article, nil
+}
+
+// slugError wraps violations of the unique slug index in
+// domain.ErrSlugTaken
+func (lite *sqliteDBClient) slugError(err error) error {
+	if strings.Contains(err.Error(), "UNIQUE constraint failed: "+lite.tablename+".slug") {
+		return fmt.Errorf("%w: %v", domain.ErrSlugTaken, err)
+	}
+	return err
+}
+
+func (lite *sqliteDBClient) GetArticleByID(article_id string) (*domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE article_id = ? AND deleted_at IS NULL`, articleColumns, lite.tablename)
+	return scanArticle(lite.db.QueryRow(query, article_id))
+}
+
+func (lite *sqliteDBClient) GetArticleBySlug(slug string) (*domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE slug = ? AND deleted_at IS NULL`, articleColumns, lite.tablename)
+	article, err := scanArticle(lite.db.QueryRow(query, slug))
+	if err != sql.ErrNoRows {
+		return article, err
+	}
+
+	var article_id string
+	query = fmt.Sprintf(`SELECT article_id FROM %s WHERE slug = ?`, lite.slugTable)
+	if err := lite.db.QueryRow(query, slug).Scan(&article_id); err != nil {
+		return nil, err
+	}
+	return lite.GetArticleByID(article_id)
+}
+
+func (lite *sqliteDBClient) IsSlugTaken(slug string, article_id string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM %s WHERE slug = ?1 AND article_id <> ?2)
+			OR EXISTS (SELECT 1 FROM %s WHERE slug = ?1 AND article_id <> ?2)`,
+		lite.tablename,
+		lite.slugTable,
+	)
+	var taken bool
+	err := lite.db.QueryRow(query, slug, article_id).Scan(&taken)
+	return taken, err
+}
+
+func (lite *sqliteDBClient) GetArticles() (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NULL`, articleColumns, lite.tablename)
+	rows, err := lite.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (lite *sqliteDBClient) GetArticlesByAuthor(author_id string) (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE author_id = ? AND deleted_at IS NULL`, articleColumns, lite.tablename)
+	rows, err := lite.db.Query(query, author_id)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (lite *sqliteDBClient) GetArticlesByTag(tag string) (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?) AND deleted_at IS NULL`,
+		articleColumns,
+		lite.tablename,
+	)
+	rows, err := lite.db.Query(query, tag)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (lite *sqliteDBClient) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	queryString, args, err := buildFindArticlesQuery(lite.tablename, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := lite.db.Query(queryString, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func buildFindArticlesQuery(tablename string, query domain.ArticleQuery, now time.Time) (string, []interface{}, error) {
+	if err := query.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if query.AuthorID != "" {
+		addCondition("author_id = ?", query.AuthorID)
+	}
+	if query.Tag != "" {
+		addCondition("EXISTS (SELECT 1 FROM json_each(tags) WHERE lower(value) = lower(?))", query.Tag)
+	}
+	if !query.From.IsZero() {
+		addCondition("publish_date >= ?", formatTime(query.From))
+	}
+	if !query.To.IsZero() {
+		addCondition("publish_date <= ?", formatTime(query.To))
+	}
+	switch query.Status {
+	case domain.ArticleStatusPublished:
+		addCondition("publish_date <= ?", formatTime(now))
+	case domain.ArticleStatusScheduled:
+		addCondition("publish_date > ?", formatTime(now))
+	}
+
+	sortField, desc := query.SortField()
+	direction, seek := "ASC", ">"
+	if desc {
+		direction, seek = "DESC", "<"
+	}
+	if query.After.ArticleID != "" {
+		// Seek past the cursor, article_id breaking ties in the sort key
+		key, err := query.AfterKey()
+		if err != nil {
+			return "", nil, err
+		}
+		if date, ok := key.(time.Time); ok {
+			key = formatTime(date)
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND article_id > ?))", sortField, seek))
+		args = append(args, key, key, query.After.ArticleID)
+	}
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = "LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	// Summary listings leave out the body, the only column that grows with
+	// the article
+	columns := selectColumns("", query.Summary)
+
+	queryString := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE %s
+		ORDER BY %s %s, article_id ASC
+		%s`,
+		columns,
+		tablename,
+		strings.Join(conditions, " AND "),
+		sortField,
+		direction,
+		limit,
+	)
+	return queryString, args, nil
+}
+
+// SearchArticles returns up to limit articles containing every word of text
+// in their title, subtitle, introduction, body or tags, best matches first.
+func (lite *sqliteDBClient) SearchArticles(text string, limit int) (*[]domain.Article, error) {
+	// Every word is quoted, so that FTS5 operators typed by readers are
+	// searched for rather than interpreted
+	terms := []string{}
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	if len(terms) == 0 {
+		return &[]domain.Article{}, nil
+	}
+
+	columns := selectColumns("article", false)
+	query := fmt.Sprintf(`
+		SELECT %[1]s
+		FROM %[2]s AS article
+		JOIN %[3]s ON %[3]s.rowid = article.id
+		WHERE %[3]s MATCH ? AND article.deleted_at IS NULL
+		ORDER BY %[3]s.rank
+		LIMIT ?`, columns, lite.tablename, lite.searchTable)
+	rows, err := lite.db.Query(query, strings.Join(terms, " "), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (lite *sqliteDBClient) GetAuthors(author_ids []string) (map[string]domain.Author, error) {
+	authors := map[string]domain.Author{}
+	if len(author_ids) == 0 {
+		return authors, nil
+	}
+	ids, err := json.Marshal(author_ids)
+	if err != nil {
+		return nil, err
+	}
+	// Articles carry a copy of the author profile, the latest write wins
+	query := fmt.Sprintf(`
+		SELECT author_id, author
+		FROM %s
+		WHERE author_id IN (SELECT value FROM json_each(?)) AND deleted_at IS NULL
+		ORDER BY updated_date ASC`, lite.tablename)
+	rows, err := lite.db.Query(query, string(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var author_id, author string
+		if err := rows.Scan(&author_id, &author); err != nil {
+			return nil, err
+		}
+		var res domain.Author
+		if err := json.Unmarshal([]byte(author), &res); err != nil {
+			return nil, err
+		}
+		authors[author_id] = res
+	}
+	return authors, rows.Err()
+}
+
+func (lite *sqliteDBClient) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE article_id = ? AND deleted_at IS NULL`, articleColumns, lite.tablename)
+	before, err := scanArticle(tx.QueryRow(query, article_id))
+	if err != nil {
+		return nil, err
+	}
+	res := before.ApplyUpdate(article)
+
+	values, err := articleValues(&res)
+	if err != nil {
+		return nil, err
+	}
+	query = fmt.Sprintf(`
+		UPDATE %s SET
+			title = ?2,
+			subtitle = ?3,
+			introduction = ?4,
+			body = ?5,
+			tags = ?6,
+			publish_date = ?7,
+			updated_date = ?8,
+			author = ?9,
+			author_id = ?10,
+			word_count = ?11,
+			reading_minutes = ?12,
+			excerpt = ?13,
+			slug = ?14
+		WHERE article_id = ?1`, lite.tablename)
+	if _, err := tx.Exec(query, values[:14]...); err != nil {
+		return nil, lite.slugError(err)
+	}
+
+	if res.Slug != before.Slug {
+		// The new slug may be one this article used before
+		_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE slug = ? AND article_id = ?`, lite.slugTable), res.Slug, res.ArticleID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(fmt.Sprintf(`
+			INSERT INTO %s (slug, article_id)
+			VALUES (?, ?)
+			ON CONFLICT (slug) DO UPDATE SET article_id = excluded.article_id`, lite.slugTable), before.Slug, res.ArticleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (lite *sqliteDBClient) DeleteArticle(article_id string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = ?
+		WHERE article_id = ? AND deleted_at IS NULL`, lite.tablename)
+	res, err := lite.db.Exec(query, formatTime(time.Now()), article_id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) DeleteArticleAll() error {
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at = ? WHERE deleted_at IS NULL`, lite.tablename)
+	res, err := lite.db.Exec(query, formatTime(time.Now()))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("no Articles to delete")
+	}
+	return nil
+}
+
+func (lite *sqliteDBClient) GetDeletedArticles() (*[]domain.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`, articleColumns, lite.tablename)
+	rows, err := lite.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	return scanArticles(rows)
+}
+
+func (lite *sqliteDBClient) RestoreArticle(article_id string) (*domain.Article, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NULL
+		WHERE article_id = ? AND deleted_at IS NOT NULL
+		RETURNING %s`, lite.tablename, articleColumns)
+	return scanArticle(lite.db.QueryRow(query, article_id))
+}
+
+func (lite *sqliteDBClient) PurgeDeletedArticles(before time.Time) (int64, []domain.Media, error) {
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE article_id IN (SELECT article_id FROM %s WHERE deleted_at < ?)`, lite.slugTable, lite.tablename)
+	if _, err := tx.Exec(query, formatTime(before)); err != nil {
+		return 0, nil, err
+	}
+
+	query = fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE article_id IN (SELECT article_id FROM %s WHERE deleted_at < ?)
+		RETURNING %s`, lite.mediaTable, lite.tablename, mediaColumns)
+	rows, err := tx.Query(query, formatTime(before))
+	if err != nil {
+		return 0, nil, err
+	}
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE deleted_at < ?`, lite.tablename)
+	res, err := tx.Exec(query, formatTime(before))
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, media, tx.Commit()
+}
+
+// StreamArticles reads the articles in pages rather than holding a query
+// open, as the only connection would be busy until fn returned.
+func (lite *sqliteDBClient) StreamArticles(fn func(article *domain.Article) error) error {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE deleted_at IS NULL AND article_id > ?
+		ORDER BY article_id
+		LIMIT 500`, articleColumns, lite.tablename)
+
+	after := ""
+	for {
+		rows, err := lite.db.Query(query, after)
+		if err != nil {
+			return err
+		}
+		articles, err := scanArticles(rows)
+		if err != nil {
+			return err
+		}
+		if len(*articles) == 0 {
+			return nil
+		}
+		for i := range *articles {
+			if err := fn(&(*articles)[i]); err != nil {
+				return err
+			}
+		}
+		after = (*articles)[len(*articles)-1].ArticleID
+	}
+}
+
+func (lite *sqliteDBClient) UpsertArticles(articles []domain.Article) ([]error, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT (article_id) DO UPDATE SET
+			title = excluded.title,
+			subtitle = excluded.subtitle,
+			introduction = excluded.introduction,
+			body = excluded.body,
+			tags = excluded.tags,
+			publish_date = excluded.publish_date,
+			updated_date = excluded.updated_date,
+			author = excluded.author,
+			author_id = excluded.author_id,
+			word_count = excluded.word_count,
+			reading_minutes = excluded.reading_minutes,
+			excerpt = excluded.excerpt,
+			slug = excluded.slug,
+			cover_image = excluded.cover_image,
+			cover_variants = excluded.cover_variants,
+			deleted_at = excluded.deleted_at`, lite.tablename, articleColumns)
+
+	tx, err := lite.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Each row runs inside its own savepoint so that one bad article does
+	// not abort the rest of the batch.
+	results := make([]error, len(articles))
+	for i := range articles {
+		values, err := articleValues(&articles[i])
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		if _, err := tx.Exec("SAVEPOINT article_upsert"); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(query, values...); err != nil {
+			results[i] = err
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT article_upsert"); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT article_upsert"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (lite *sqliteDBClient) CountPublishedArticles() (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE deleted_at IS NULL AND publish_date <= ?`, lite.tablename)
+
+	var count int
+	if err := lite.db.QueryRow(query, formatTime(time.Now())).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (lite *sqliteDBClient) GetSitemapPage(after string, offset, limit int) ([]domain.SitemapEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT article_id, slug, updated_date
+		FROM %s
+		WHERE deleted_at IS NULL AND publish_date <= ? AND article_id > ?
+		ORDER BY article_id
+		LIMIT ? OFFSET ?`, lite.tablename)
+
+	rows, err := lite.db.Query(query, formatTime(time.Now()), after, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.SitemapEntry{}
+	for rows.Next() {
+		var entry domain.SitemapEntry
+		var updatedDate string
+		if err := rows.Scan(&entry.ArticleID, &entry.Slug, &updatedDate); err != nil {
+			return nil, err
+		}
+		if entry.UpdatedDate, err = time.Parse(timeLayout, updatedDate); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
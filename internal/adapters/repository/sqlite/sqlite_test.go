@@ -0,0 +1,221 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/repotest"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+func newTestClient(t *testing.T, path string) *sqliteDBClient {
+	lite, err := NewSQLiteClient(appConfig.Config{ARTICLE_TABLE: "Articles", SQLITE_PATH: path})
+	if err != nil {
+		t.Fatalf("NewSQLiteClient: %v", err)
+	}
+	t.Cleanup(func() { lite.db.Close() })
+	return lite
+}
+
+func testArticle(id, title, body string, tags ...string) *domain.Article {
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &domain.Article{
+		ArticleID:   id,
+		Title:       title,
+		Body:        body,
+		Tags:        tags,
+		PublishDate: date,
+		UpdatedDate: date,
+		Author:      domain.Author{AuthorID: "author-" + id, Firstname: "Ada"},
+		AuthorID:    "author-" + id,
+		Slug:        id + "-slug",
+	}
+}
+
+func TestMigrationsApplyOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "articles.db")
+	first := newTestClient(t, path)
+	if _, err := first.CreateArticle(testArticle("1", "Kept", "")); err != nil {
+		t.Fatalf("CreateArticle: %v", err)
+	}
+	first.db.Close()
+
+	second := newTestClient(t, path)
+	var version int
+	if err := second.db.QueryRow(`SELECT MAX(version) FROM Articles_migrations`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected version %d, got %d", len(migrations), version)
+	}
+	if _, err := second.GetArticleByID("1"); err != nil {
+		t.Errorf("Expected the article to survive reopening, got %v", err)
+	}
+}
+
+func TestCreateAndGetArticle(t *testing.T) {
+	lite := newTestClient(t, ":memory:")
+	article := testArticle("1", "Hello", "Body", "go", "sqlite")
+	if _, err := lite.CreateArticle(article); err != nil {
+		t.Fatalf("CreateArticle: %v", err)
+	}
+
+	res, err := lite.GetArticleByID("1")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	if res.Title != "Hello" || len(res.Tags) != 2 || res.Author.Firstname != "Ada" || !res.PublishDate.Equal(article.PublishDate) {
+		t.Errorf("Expected the stored article back, got %+v", res)
+	}
+	if _, err := lite.GetArticleBySlug("1-slug"); err != nil {
+		t.Errorf("GetArticleBySlug: %v", err)
+	}
+	if _, err := lite.GetArticleByID("missing"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	byTag, err := lite.GetArticlesByTag("sqlite")
+	if err != nil || len(*byTag) != 1 {
+		t.Errorf("Expected one article tagged sqlite, got %v, %v", byTag, err)
+	}
+	byAuthor, err := lite.GetArticlesByAuthor("author-1")
+	if err != nil || len(*byAuthor) != 1 {
+		t.Errorf("Expected one article by author-1, got %v, %v", byAuthor, err)
+	}
+}
+
+func TestUpdateKeepsOldSlug(t *testing.T) {
+	lite := newTestClient(t, ":memory:")
+	if _, err := lite.CreateArticle(testArticle("1", "Hello", "")); err != nil {
+		t.Fatalf("CreateArticle: %v", err)
+	}
+
+	res, err := lite.UpdateArticle("1", &domain.Article{Title: "Renamed", Slug: "renamed", Tags: []string{"news"}})
+	if err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	if res.Title != "Renamed" || res.Slug != "renamed" || res.Tags[0] != "news" {
+		t.Errorf("Expected the update applied, got %+v", res)
+	}
+	old, err := lite.GetArticleBySlug("1-slug")
+	if err != nil || old.ArticleID != "1" {
+		t.Errorf("Expected the old slug to find the article, got %v, %v", old, err)
+	}
+	if taken, _ := lite.IsSlugTaken("1-slug", "2"); !taken {
+		t.Errorf("Expected the old slug to stay taken")
+	}
+}
+
+func TestTrash(t *testing.T) {
+	lite := newTestClient(t, ":memory:")
+	if _, err := lite.CreateArticle(testArticle("1", "Hello", "")); err != nil {
+		t.Fatalf("CreateArticle: %v", err)
+	}
+	if err := lite.DeleteArticle("1"); err != nil {
+		t.Fatalf("DeleteArticle: %v", err)
+	}
+	if err := lite.DeleteArticle("1"); err != sql.ErrNoRows {
+		t.Errorf("Expected deleting twice to fail, got %v", err)
+	}
+	if _, err := lite.GetArticleByID("1"); err != sql.ErrNoRows {
+		t.Errorf("Expected deleted articles to be hidden, got %v", err)
+	}
+
+	restored, err := lite.RestoreArticle("1")
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("Expected the article restored, got %v, %v", restored, err)
+	}
+
+	lite.DeleteArticle("1")
+	purged, _, err := lite.PurgeDeletedArticles(time.Now().Add(time.Minute))
+	if err != nil || purged != 1 {
+		t.Errorf("Expected one article purged, got %d, %v", purged, err)
+	}
+}
+
+func TestSearchArticles(t *testing.T) {
+	lite := newTestClient(t, ":memory:")
+	lite.CreateArticle(testArticle("1", "Writing Go services", "Ports and adapters"))
+	lite.CreateArticle(testArticle("2", "Baking bread", "Flour, water and salt", "kitchen"))
+	lite.CreateArticle(testArticle("3", "Go at the bakery", "Bread for gophers"))
+
+	search := func(text string) []string {
+		res, err := lite.SearchArticles(text, 10)
+		if err != nil {
+			t.Fatalf("SearchArticles(%q): %v", text, err)
+		}
+		ids := []string{}
+		for _, article := range *res {
+			ids = append(ids, article.ArticleID)
+		}
+		return ids
+	}
+
+	if ids := search("bread"); len(ids) != 2 {
+		t.Errorf("Expected two articles about bread, got %v", ids)
+	}
+	if ids := search("go bread"); len(ids) != 1 || ids[0] != "3" {
+		t.Errorf("Expected every word to match, got %v", ids)
+	}
+	if ids := search("kitchen"); len(ids) != 1 || ids[0] != "2" {
+		t.Errorf("Expected tags to be searched, got %v", ids)
+	}
+	// Operators are searched for as words
+	if ids := search(`bread OR "salt`); len(ids) != 0 {
+		t.Errorf("Expected no article with the word OR, got %v", ids)
+	}
+
+	lite.UpdateArticle("1", &domain.Article{Title: "Writing Go bread"})
+	lite.DeleteArticle("3")
+	if ids := search("go bread"); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("Expected the index to follow updates and deletes, got %v", ids)
+	}
+}
+
+func TestUpsertArticles(t *testing.T) {
+	lite := newTestClient(t, ":memory:")
+	lite.CreateArticle(testArticle("1", "Hello", ""))
+
+	clash := testArticle("3", "Clash", "")
+	clash.Slug = "1-slug"
+	results, err := lite.UpsertArticles([]domain.Article{
+		*testArticle("1", "Replaced", ""),
+		*testArticle("2", "New", ""),
+		*clash,
+	})
+	if err != nil {
+		t.Fatalf("UpsertArticles: %v", err)
+	}
+	if results[0] != nil || results[1] != nil || results[2] == nil {
+		t.Errorf("Expected only the slug clash to fail, got %v", results)
+	}
+	articles, _ := lite.GetArticles()
+	if len(*articles) != 2 {
+		t.Errorf("Expected two articles, got %d", len(*articles))
+	}
+
+	streamed := 0
+	lite.StreamArticles(func(article *domain.Article) error {
+		streamed++
+		return nil
+	})
+	if streamed != 2 {
+		t.Errorf("Expected two articles streamed, got %d", streamed)
+	}
+}
+
+func TestContract(t *testing.T) {
+	repotest.RunArticleRepository(t, func(t *testing.T) ports.ArticleRepository {
+		return newTestClient(t, ":memory:")
+	})
+	repotest.RunSeriesRepository(t, func(t *testing.T) repotest.SeriesStore {
+		return newTestClient(t, ":memory:")
+	})
+	repotest.RunMediaRepository(t, func(t *testing.T) repotest.MediaStore {
+		return newTestClient(t, ":memory:")
+	})
+}
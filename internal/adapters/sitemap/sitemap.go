@@ -0,0 +1,85 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	ContentType = "application/xml; charset=utf-8"
+	// MaxURLs is the most URLs the sitemap protocol allows in one file
+	MaxURLs = 50000
+
+	namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+type url struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+type indexEntry struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+}
+
+// urlSetWriter writes a urlset one URL at a time, so a sitemap never has to
+// be held in memory.
+type urlSetWriter struct {
+	encoder *xml.Encoder
+}
+
+// NewURLSetWriter writes the urlset header to w. Close writes the footer.
+func NewURLSetWriter(w io.Writer) (*urlSetWriter, error) {
+	return openSet(w, "urlset")
+}
+
+func (s *urlSetWriter) Add(loc string, lastMod time.Time) error {
+	entry := url{Loc: loc}
+	if !lastMod.IsZero() {
+		entry.LastMod = lastMod.UTC().Format(time.RFC3339)
+	}
+	return s.encoder.Encode(entry)
+}
+
+func (s *urlSetWriter) Close() error {
+	return closeSet(s.encoder, "urlset")
+}
+
+// WriteIndex writes a sitemap index pointing at the sitemaps at locs.
+func WriteIndex(w io.Writer, locs []string) error {
+	s, err := openSet(w, "sitemapindex")
+	if err != nil {
+		return err
+	}
+	for _, loc := range locs {
+		if err := s.encoder.Encode(indexEntry{Loc: loc}); err != nil {
+			return err
+		}
+	}
+	return closeSet(s.encoder, "sitemapindex")
+}
+
+func openSet(w io.Writer, name string) (*urlSetWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	encoder := xml.NewEncoder(w)
+	start := xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return nil, err
+	}
+	return &urlSetWriter{encoder: encoder}, nil
+}
+
+func closeSet(encoder *xml.Encoder, name string) error {
+	if err := encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
@@ -1,20 +1,45 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
 )
 
 type Article struct {
 	ArticleID    string    `json:"article_id"`
+	Slug         string    `json:"slug"`
 	Title        string    `json:"title"`
 	Subtitle     string    `json:"subtitle"`
 	Introduction string    `json:"introduction"`
 	Body         string    `json:"body"`
+	BodyHTML     string    `json:"body_html,omitempty"`
 	Tags         []string  `json:"tags"`
 	PublishDate  time.Time `json:"publish_date"`
 	UpdatedDate  time.Time `json:"updated_date"`
 	Author       Author    `json:"author"`
 	AuthorID     string    `json:"author_id"`
+	// WordCount, ReadingMinutes and Excerpt are derived from Introduction
+	// and Body whenever the article is written
+	WordCount      int    `json:"word_count"`
+	ReadingMinutes int    `json:"reading_minutes"`
+	Excerpt        string `json:"excerpt"`
+	// CoverImage is the URL of the image shown above the article and in
+	// listings
+	CoverImage string `json:"cover_image"`
+	// CoverVariants are the resized copies of the cover image, derived in
+	// the background after it is uploaded
+	CoverVariants []ImageVariant `json:"cover_variants,omitempty"`
+	// Series places the article within the series it belongs to. It is only
+	// set when a single article is read, and never stored.
+	Series *SeriesNavigation `json:"series,omitempty"`
+	// DeletedAt is set while the article sits in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Author struct {
@@ -34,3 +59,372 @@ type LogMessage struct {
 	Message  string `json:"message"`
 	Service  string `json:"service"`
 }
+
+// ErrSearchUnsupported is returned when the article store offers no
+// full-text search
+var ErrSearchUnsupported = errors.New("search is not supported by the article store")
+
+const (
+	ArticleStatusPublished = "published"
+	ArticleStatusScheduled = "scheduled"
+)
+
+// ArticleQuery combines the optional filters and sort order accepted by
+// FindArticles. Zero values mean "no filter".
+type ArticleQuery struct {
+	AuthorID string    `json:"author_id"`
+	Tag      string    `json:"tag"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Status   string    `json:"status"`
+	Sort     string    `json:"sort"`
+	// Summary leaves the body out of the returned articles
+	Summary bool `json:"summary"`
+	// Limit caps the number of returned articles
+	Limit int `json:"limit"`
+	// After makes the results start behind the article it points at
+	After ArticleCursor `json:"after"`
+}
+
+// ArticleCursor points at an article within the results of an ArticleQuery
+// by its sort key and ID, so that the next page can be read by seeking
+// rather than skipping.
+type ArticleCursor struct {
+	Key       string `json:"key"`
+	ArticleID string `json:"article_id"`
+}
+
+// articleSortFields lists the article fields a query may be sorted by.
+var articleSortFields = map[string]bool{
+	"publish_date": true,
+	"updated_date": true,
+	"title":        true,
+}
+
+// SortField returns the field to sort by and whether the order is
+// descending. A leading "-" on Sort selects descending order; an empty Sort
+// defaults to newest first.
+func (q ArticleQuery) SortField() (string, bool) {
+	if q.Sort == "" {
+		return "publish_date", true
+	}
+	if strings.HasPrefix(q.Sort, "-") {
+		return strings.TrimPrefix(q.Sort, "-"), true
+	}
+	return q.Sort, false
+}
+
+// CursorOf returns the cursor pointing at article under the sort order of q.
+func (q ArticleQuery) CursorOf(article Article) ArticleCursor {
+	cursor := ArticleCursor{ArticleID: article.ArticleID}
+	switch field, _ := q.SortField(); field {
+	case "publish_date":
+		cursor.Key = article.PublishDate.UTC().Format(time.RFC3339Nano)
+	case "updated_date":
+		cursor.Key = article.UpdatedDate.UTC().Format(time.RFC3339Nano)
+	case "title":
+		cursor.Key = article.Title
+	}
+	return cursor
+}
+
+// AfterKey returns the sort key of After: a time.Time when sorting by a
+// date and a string when sorting by title.
+func (q ArticleQuery) AfterKey() (interface{}, error) {
+	if field, _ := q.SortField(); field == "title" {
+		return q.After.Key, nil
+	}
+	key, err := time.Parse(time.RFC3339Nano, q.After.Key)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return key, nil
+}
+
+func (q ArticleQuery) Validate() error {
+	if field, _ := q.SortField(); !articleSortFields[field] {
+		return fmt.Errorf("invalid sort field [%s]", field)
+	}
+	if q.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if q.After.ArticleID != "" {
+		if _, err := q.AfterKey(); err != nil {
+			return err
+		}
+	}
+	switch q.Status {
+	case "", ArticleStatusPublished, ArticleStatusScheduled:
+	default:
+		return fmt.Errorf("invalid status [%s]", q.Status)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return errors.New("from must not be after to")
+	}
+	return nil
+}
+
+// SitemapEntry is the part of a published article listed in a sitemap
+type SitemapEntry struct {
+	ArticleID   string    `json:"article_id"`
+	Slug        string    `json:"slug"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+// ImportReport summarises an NDJSON import. Line numbers in Errors are 1-based
+// and refer to the input stream.
+type ImportReport struct {
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// maxSlugLength keeps generated slugs comfortably inside the slug column
+const maxSlugLength = 80
+
+// ErrSlugTaken is returned by repositories writing an article with a slug
+// another article already holds
+var ErrSlugTaken = errors.New("slug is taken")
+
+// Slugify turns an article title into a lowercase, dash separated URL path
+// segment. Accented letters are folded to their base letter and any other
+// character that is not a letter or digit becomes a separator.
+func Slugify(title string) string {
+	decomposed := norm.NFKD.String(strings.ToLower(title))
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range decomposed {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left over from decomposing accented letters
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		default:
+			pendingDash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+const (
+	EventArticleCreated   = "article.created"
+	EventArticleUpdated   = "article.updated"
+	EventArticlePublished = "article.published"
+	EventArticleDeleted   = "article.deleted"
+	EventArticleRestored  = "article.restored"
+)
+
+// Event is a domain event about an article, carrying a snapshot of the
+// article as it was right after the change.
+type Event struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	ArticleID  string    `json:"article_id"`
+	Article    *Article  `json:"article"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Attempts counts failed deliveries so far
+	Attempts int `json:"attempts"`
+}
+
+func NewArticleEvent(eventType string, article *Article) Event {
+	return Event{
+		EventID:    uuid.New().String(),
+		EventType:  eventType,
+		ArticleID:  article.ArticleID,
+		Article:    article,
+		OccurredAt: time.Now(),
+	}
+}
+
+// IsPublished reports whether the article is publicly visible at the given
+// time.
+func (a *Article) IsPublished(at time.Time) bool {
+	return !a.PublishDate.After(at)
+}
+
+// ApplyUpdate returns the article with the editable fields of update written
+// over it. The slug and publish date are only replaced when update sets them.
+// An update never moves the article to another author, it only refreshes the
+// author profile when it names the same author. The cover image and its
+// variants are managed by the media service and are never written by an
+// update.
+func (a Article) ApplyUpdate(update *Article) Article {
+	a.Title = update.Title
+	a.Subtitle = update.Subtitle
+	a.Introduction = update.Introduction
+	a.Body = update.Body
+	a.Tags = update.Tags
+	a.UpdatedDate = update.UpdatedDate
+	a.WordCount = update.WordCount
+	a.ReadingMinutes = update.ReadingMinutes
+	a.Excerpt = update.Excerpt
+	if update.Slug != "" {
+		a.Slug = update.Slug
+	}
+	if !update.PublishDate.IsZero() {
+		a.PublishDate = update.PublishDate
+	}
+	if update.AuthorID != "" && update.AuthorID == a.AuthorID {
+		a.Author = update.Author
+	}
+	return a
+}
+
+// ArticleWriteEvents returns the events for writing an article. before is
+// nil when the article is new. An article.published event is added when the
+// write makes the article visible.
+func ArticleWriteEvents(before, after *Article) []Event {
+	now := time.Now()
+	if before == nil {
+		events := []Event{NewArticleEvent(EventArticleCreated, after)}
+		if after.IsPublished(now) {
+			events = append(events, NewArticleEvent(EventArticlePublished, after))
+		}
+		return events
+	}
+
+	events := []Event{NewArticleEvent(EventArticleUpdated, after)}
+	if !before.IsPublished(now) && after.IsPublished(now) {
+		events = append(events, NewArticleEvent(EventArticlePublished, after))
+	}
+	return events
+}
+
+// WebhookSubscription registers a partner URL for article events. An empty
+// Events list subscribes to every event type.
+type WebhookSubscription struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret,omitempty"`
+	Events         []string  `json:"events"`
+	Active         bool      `json:"active"`
+	CreatedDate    time.Time `json:"created_date"`
+	UpdatedDate    time.Time `json:"updated_date"`
+}
+
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead marks deliveries that ran out of attempts
+	DeliveryDead = "dead"
+)
+
+// WebhookDelivery tracks sending one event to one subscription, with a log
+// entry for every attempt.
+type WebhookDelivery struct {
+	DeliveryID     string           `json:"delivery_id"`
+	SubscriptionID string           `json:"subscription_id"`
+	Event          Event            `json:"event"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  time.Time        `json:"next_attempt_at"`
+	Log            []WebhookAttempt `json:"log"`
+	CreatedDate    time.Time        `json:"created_date"`
+	UpdatedDate    time.Time        `json:"updated_date"`
+}
+
+type WebhookAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+var (
+	// ErrMediaType is returned for uploads whose content is not of an
+	// allowed type
+	ErrMediaType = errors.New("unsupported media type")
+	// ErrMediaTooLarge is returned for uploads over the size limit
+	ErrMediaTooLarge = errors.New("media too large")
+)
+
+// Media is a file uploaded for an article, such as an inline image or its
+// cover. Key locates the content in the blob store, URL is where readers
+// load it from. Width and Height are set for images.
+type Media struct {
+	MediaID     string    `json:"media_id"`
+	ArticleID   string    `json:"article_id"`
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	CreatedDate time.Time `json:"created_date"`
+	// Variants are derived from cover images once they are processed
+	Variants []ImageVariant `json:"variants,omitempty"`
+}
+
+// ImageVariant is a resized copy of an image, such as its thumbnail or one
+// of the responsive widths, in one of the derived formats.
+type ImageVariant struct {
+	// Name is "thumbnail" or the width prefixed with w, such as "w960"
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Series groups the articles of an author that are meant to be read in
+// order, such as the parts of a tutorial. ArticleIDs are in reading order.
+// An article belongs to at most one series.
+type Series struct {
+	SeriesID    string    `json:"series_id"`
+	AuthorID    string    `json:"author_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ArticleIDs  []string  `json:"article_ids"`
+	CreatedDate time.Time `json:"created_date"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+// SeriesNavigation is the place of an article within its series. Position
+// counts from 1. Previous and Next are the nearest published articles
+// either side, nil at the ends of the series.
+type SeriesNavigation struct {
+	SeriesID string       `json:"series_id"`
+	Title    string       `json:"title"`
+	Position int          `json:"position"`
+	Total    int          `json:"total"`
+	Previous *ArticleLink `json:"previous"`
+	Next     *ArticleLink `json:"next"`
+}
+
+// ArticleLink is enough of an article to link to it
+type ArticleLink struct {
+	ArticleID string `json:"article_id"`
+	Slug      string `json:"slug"`
+	Title     string `json:"title"`
+}
@@ -1,27 +1,235 @@
 package ports
 
-import "github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+import (
+	"image"
+	"io"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
 
 type ArticleService interface {
 	CreateArticle(article *domain.Article) (*domain.Article, error)
 	GetArticleByID(article_id string) (*domain.Article, error)
+	GetArticleHTML(article_id string) (*domain.Article, error)
+	GetArticleBySlug(slug string) (*domain.Article, error)
 	GetArticles() (*[]domain.Article, error)
 	GetArticlesByAuthor(author_id string) (*[]domain.Article, error)
 	GetArticlesByTag(tag string) (*[]domain.Article, error)
+	FindArticles(query domain.ArticleQuery) (*[]domain.Article, error)
+	GetAuthors(author_ids []string) (map[string]domain.Author, error)
 	UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error)
 	DeleteArticle(article_id string) error
 	DeleteArticleAll() error
+	GetDeletedArticles() (*[]domain.Article, error)
+	RestoreArticle(article_id string) (*domain.Article, error)
+	ExportArticles(w io.Writer) error
+	ImportArticles(r io.Reader) (*domain.ImportReport, error)
+	CountPublishedArticles() (int, error)
+	StreamSitemapEntries(offset, limit int, fn func(entry domain.SitemapEntry) error) error
+	// SearchArticles returns domain.ErrSearchUnsupported when the repository
+	// is not an ArticleSearcher
+	SearchArticles(text string, limit int) (*[]domain.Article, error)
 }
 
 type ArticleRepository interface {
 	CreateArticle(article *domain.Article) (*domain.Article, error)
 	GetArticleByID(article_id string) (*domain.Article, error)
+	// GetArticleBySlug resolves both current and previous slugs. The returned
+	// article always carries its current slug.
+	GetArticleBySlug(slug string) (*domain.Article, error)
+	// IsSlugTaken reports whether slug is, or once was, used by an article
+	// other than article_id.
+	IsSlugTaken(slug string, article_id string) (bool, error)
 	GetArticles() (*[]domain.Article, error)
 	GetArticlesByAuthor(author_id string) (*[]domain.Article, error)
 	GetArticlesByTag(tag string) (*[]domain.Article, error)
+	FindArticles(query domain.ArticleQuery) (*[]domain.Article, error)
+	// GetAuthors returns the most recently written profile of each author,
+	// keyed by author ID. Authors without articles are left out.
+	GetAuthors(author_ids []string) (map[string]domain.Author, error)
+	// UpdateArticle keeps the previous slug resolvable when the slug changes
 	UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error)
+	// DeleteArticle and DeleteArticleAll move articles to the trash. Trashed
+	// articles are left out of every other query until they are restored.
 	DeleteArticle(article_id string) error
 	DeleteArticleAll() error
+	GetDeletedArticles() (*[]domain.Article, error)
+	RestoreArticle(article_id string) (*domain.Article, error)
+	// PurgeDeletedArticles permanently removes articles trashed before the
+	// given time, with their media records, and returns how many articles
+	// were removed and the media removed with them. Stores without media
+	// return no media. The content of the media is left to the caller.
+	PurgeDeletedArticles(before time.Time) (int64, []domain.Media, error)
+	// StreamArticles calls fn for every stored article without loading the
+	// whole table into memory. Iteration stops at the first error fn returns.
+	StreamArticles(fn func(article *domain.Article) error) error
+	// UpsertArticles inserts or replaces articles in a single transaction. The
+	// returned slice holds one entry per article, nil when it was written.
+	UpsertArticles(articles []domain.Article) ([]error, error)
+	CountPublishedArticles() (int, error)
+	// GetSitemapPage returns up to limit published articles ordered by ID,
+	// skipping the first offset of those after the article_id after. An
+	// empty after starts at the beginning.
+	GetSitemapPage(after string, offset, limit int) ([]domain.SitemapEntry, error)
+}
+
+// ArticleSearcher is implemented by repositories offering full-text search.
+// SearchArticles returns up to limit articles containing every word of text,
+// best matches first.
+type ArticleSearcher interface {
+	SearchArticles(text string, limit int) (*[]domain.Article, error)
+}
+
+type WebhookService interface {
+	CreateSubscription(subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	GetSubscription(subscription_id string) (*domain.WebhookSubscription, error)
+	GetSubscriptions() (*[]domain.WebhookSubscription, error)
+	UpdateSubscription(subscription_id string, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteSubscription(subscription_id string) error
+	GetDeliveries(subscription_id string) (*[]domain.WebhookDelivery, error)
+	GetDeadDeliveries() (*[]domain.WebhookDelivery, error)
+	RetryDelivery(delivery_id string) (*domain.WebhookDelivery, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	GetSubscription(subscription_id string) (*domain.WebhookSubscription, error)
+	GetSubscriptions() (*[]domain.WebhookSubscription, error)
+	UpdateSubscription(subscription_id string, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteSubscription(subscription_id string) error
+	CreateDeliveries(deliveries []domain.WebhookDelivery) error
+	GetDelivery(delivery_id string) (*domain.WebhookDelivery, error)
+	GetDeliveries(subscription_id string) (*[]domain.WebhookDelivery, error)
+	GetDeadDeliveries() (*[]domain.WebhookDelivery, error)
+	// ClaimDueDeliveries returns pending deliveries whose next attempt is due
+	// and pushes their next attempt back by lease, so that concurrent
+	// workers do not send the same delivery at once.
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(delivery *domain.WebhookDelivery) error
+}
+
+// OutboxRepository gives the relay access to domain events written
+// alongside article changes. Events stay pending until marked delivered
+// or dead.
+type OutboxRepository interface {
+	// ClaimPendingEvents returns due events oldest first and pushes their
+	// next attempt back by lease, so that concurrent relays do not publish
+	// the same event at once. An event is only due once every earlier
+	// pending event of its article is delivered or dead.
+	ClaimPendingEvents(limit int, lease time.Duration) ([]domain.Event, error)
+	MarkEventDelivered(event_id string) error
+	// MarkEventFailed counts a failed attempt and schedules the next one
+	MarkEventFailed(event_id string, reason string, next_attempt_at time.Time) error
+	// MarkEventDead gives up on an event, unblocking the later events of
+	// its article
+	MarkEventDead(event_id string, reason string) error
+}
+
+// MediaRepository records the media uploaded for articles
+type MediaRepository interface {
+	CreateMedia(media *domain.Media) (*domain.Media, error)
+	GetMedia(media_id string) (*domain.Media, error)
+	// GetArticleMedia returns the media of an article, oldest first
+	GetArticleMedia(article_id string) (*[]domain.Media, error)
+	DeleteMedia(media_id string) error
+	// UpdateMediaVariants records the variants derived from the media
+	UpdateMediaVariants(media_id string, variants []domain.ImageVariant) error
+	// SetArticleCover makes cover_image the cover of the article, without
+	// the variants of the previous cover, and returns the article
+	SetArticleCover(article_id string, cover_image string, updated_date time.Time) (*domain.Article, error)
+	// SetCoverVariants records the variants of the cover of the article
+	// while cover_image is still its cover, writing no other field
+	SetCoverVariants(article_id string, cover_image string, variants []domain.ImageVariant) error
+}
+
+// SeriesRepository keeps series of articles. Writing a series with an
+// article that belongs to another series fails. Articles leave their series
+// when they are purged.
+type SeriesRepository interface {
+	CreateSeries(series *domain.Series) (*domain.Series, error)
+	GetSeries(series_id string) (*domain.Series, error)
+	// GetSeriesList returns the series of author_id, or every series when
+	// it is empty, oldest first
+	GetSeriesList(author_id string) (*[]domain.Series, error)
+	// UpdateSeries replaces the title, description and articles of a series
+	UpdateSeries(series_id string, series *domain.Series) (*domain.Series, error)
+	// DeleteSeries removes the series, leaving its articles be
+	DeleteSeries(series_id string) error
+	// GetArticleSeries returns the series the article belongs to, nil when
+	// it belongs to none
+	GetArticleSeries(article_id string) (*domain.Series, error)
+}
+
+type SeriesService interface {
+	CreateSeries(series *domain.Series) (*domain.Series, error)
+	GetSeries(series_id string) (*domain.Series, error)
+	GetSeriesList(author_id string) (*[]domain.Series, error)
+	UpdateSeries(series_id string, series *domain.Series) (*domain.Series, error)
+	DeleteSeries(series_id string) error
+}
+
+// BlobStore keeps file contents under slash separated keys. Get returns an
+// error wrapping fs.ErrNotExist for keys that are not stored.
+type BlobStore interface {
+	Put(key string, content_type string, content io.ReadSeeker) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// ImageProcessor reads uploaded images and writes the variants derived from
+// them
+type ImageProcessor interface {
+	// StripMetadata removes EXIF, XMP and similar metadata from an encoded
+	// image, keeping only its orientation. Content it cannot parse is
+	// returned as it is.
+	StripMetadata(content []byte) ([]byte, error)
+	// Dimensions reads the width and height of an encoded image from its
+	// header, without decoding the pixels
+	Dimensions(content []byte) (int, int, error)
+	// Decode reads an image turned upright according to its orientation
+	Decode(content []byte) (image.Image, error)
+	// Resize scales img to width, keeping its aspect ratio when height is 0
+	// and cropping it to fill width by height otherwise
+	Resize(img image.Image, width int, height int) image.Image
+	// Encode writes img in format, "jpeg"
+	Encode(w io.Writer, img image.Image, format string) error
+}
+
+// ImagePipeline derives the variants of uploaded images in the background
+type ImagePipeline interface {
+	// Enqueue schedules media for processing, reporting false when too much
+	// is waiting already
+	Enqueue(media domain.Media) bool
+}
+
+type MediaService interface {
+	// UploadMedia stores content for the article after checking its type
+	// and size
+	UploadMedia(article_id string, filename string, content io.Reader) (*domain.Media, error)
+	// SetCoverImage uploads content and makes it the cover of the article.
+	// The variants of the cover are added once they are derived.
+	SetCoverImage(article_id string, filename string, content io.Reader) (*domain.Article, error)
+	GetArticleMedia(article_id string) (*[]domain.Media, error)
+	// DeleteMedia removes the media and its content, and the cover of the
+	// article when it showed the media
+	DeleteMedia(article_id string, media_id string) error
+	// OpenMedia returns the content stored under key and its type
+	OpenMedia(key string) (io.ReadCloser, string, error)
+}
+
+// EventPublisher delivers a domain event to other services. Publish must only
+// return nil once the event has been accepted.
+type EventPublisher interface {
+	Publish(event domain.Event) error
+}
+
+// ContentRenderer turns a stored article body into HTML that is safe to
+// embed in a page.
+type ContentRenderer interface {
+	RenderHTML(source string) (string, error)
+	// PlainText strips all markup from source, leaving the readable text
+	PlainText(source string) (string, error)
 }
 
 type LoggingService interface {
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// variantExtensions name the files of each derived format
+var variantExtensions = map[string]string{
+	"jpeg": ".jpg",
+}
+
+// imagePipeline derives the variants of cover images: a square thumbnail and
+// each responsive width narrower than the image, in every format. A fixed
+// number of workers take media from a bounded queue, so uploads only wait
+// to be queued. Media still queued when the service stops keeps no variants
+// until its cover is uploaded again.
+type imagePipeline struct {
+	repo   ports.MediaRepository
+	blobs  ports.BlobStore
+	images ports.ImageProcessor
+	logger ports.LoggingService
+	// baseURL is prefixed to blob keys to give the URL of variants
+	baseURL       string
+	widths        []int
+	thumbnailSize int
+	formats       []string
+	maxPixels     int
+	workers       int
+	queue         chan domain.Media
+}
+
+// NewImagePipeline derives variants of the given widths and thumbnailSize
+// in formats with images, storing them in blobs next to the media. Images
+// over maxPixels are not decoded. Up to queueSize media wait for one of the
+// workers.
+func NewImagePipeline(repo ports.MediaRepository, blobs ports.BlobStore, images ports.ImageProcessor, logger ports.LoggingService, baseURL string, widths []int, thumbnailSize int, formats []string, maxPixels int, workers int, queueSize int) *imagePipeline {
+	pipeline := imagePipeline{
+		repo:          repo,
+		blobs:         blobs,
+		images:        images,
+		logger:        logger,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		widths:        widths,
+		thumbnailSize: thumbnailSize,
+		formats:       formats,
+		maxPixels:     maxPixels,
+		workers:       workers,
+		queue:         make(chan domain.Media, queueSize),
+	}
+	return &pipeline
+}
+
+func (p *imagePipeline) Enqueue(media domain.Media) bool {
+	select {
+	case p.queue <- media:
+		return true
+	default:
+		logEntry := domain.LogMessage{
+			LogLevel: "WARNING",
+			Service:  "articles",
+			Message:  fmt.Sprintf("image queue full, %s of article %s gets no variants", media.MediaID, media.ArticleID),
+		}
+		p.logger.LogWarning(logEntry)
+		return false
+	}
+}
+
+// Run processes queued media with the workers until stop is closed. Media
+// being processed is finished first.
+func (p *imagePipeline) Run(stop chan struct{}) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				case media := <-p.queue:
+					p.process(media)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// process derives the variants of media and records them with it, and with
+// the article while the media is still its cover
+func (p *imagePipeline) process(media domain.Media) {
+	variants, err := p.derive(media)
+	if err != nil {
+		p.logError(fmt.Errorf("deriving variants of media %s: %w", media.MediaID, err))
+		return
+	}
+	if err := p.repo.UpdateMediaVariants(media.MediaID, variants); err != nil {
+		// The media may have been deleted meanwhile, leaving nothing to
+		// delete its variants with
+		p.deleteVariants(variants)
+		p.logError(err)
+		return
+	}
+	// Only the variants are written, edits made to the article while they
+	// were derived are kept
+	if err := p.repo.SetCoverVariants(media.ArticleID, media.URL, variants); err != nil {
+		p.logError(err)
+	}
+}
+
+func (p *imagePipeline) derive(media domain.Media) ([]domain.ImageVariant, error) {
+	content, err := p.blobs.Get(media.Key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		return nil, err
+	}
+	// Media stored before the limit was lowered is checked again
+	width, height, err := p.images.Dimensions(data)
+	if err != nil {
+		return nil, err
+	}
+	if tooManyPixels(width, height, p.maxPixels) {
+		return nil, fmt.Errorf("%w, %dx%d is over the limit of %d pixels", domain.ErrMediaTooLarge, width, height, p.maxPixels)
+	}
+	img, err := p.images.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	type size struct {
+		name          string
+		width, height int
+	}
+	bounds := img.Bounds()
+	thumbnail := p.thumbnailSize
+	if thumbnail > bounds.Dx() {
+		thumbnail = bounds.Dx()
+	}
+	if thumbnail > bounds.Dy() {
+		thumbnail = bounds.Dy()
+	}
+	sizes := []size{{"thumbnail", thumbnail, thumbnail}}
+	// Widths are never scaled up, readers are better off with the original
+	for _, width := range p.widths {
+		if width < bounds.Dx() {
+			sizes = append(sizes, size{fmt.Sprintf("w%d", width), width, 0})
+		}
+	}
+
+	// Variants sit next to the media, in a directory named after it
+	dir := path.Join(path.Dir(media.Key), media.MediaID)
+	variants := []domain.ImageVariant{}
+	for _, size := range sizes {
+		resized := p.images.Resize(img, size.width, size.height)
+		for _, format := range p.formats {
+			var buf bytes.Buffer
+			if err := p.images.Encode(&buf, resized, format); err != nil {
+				p.deleteVariants(variants)
+				return nil, err
+			}
+			key := path.Join(dir, size.name+variantExtensions[format])
+			if err := p.blobs.Put(key, "image/"+format, bytes.NewReader(buf.Bytes())); err != nil {
+				p.deleteVariants(variants)
+				return nil, err
+			}
+			variants = append(variants, domain.ImageVariant{
+				Name:   size.name,
+				Format: format,
+				Key:    key,
+				URL:    p.baseURL + "/" + key,
+				Width:  resized.Bounds().Dx(),
+				Height: resized.Bounds().Dy(),
+			})
+		}
+	}
+	return variants, nil
+}
+
+func (p *imagePipeline) deleteVariants(variants []domain.ImageVariant) {
+	for _, variant := range variants {
+		if err := p.blobs.Delete(variant.Key); err != nil {
+			p.logError(err)
+		}
+	}
+}
+
+func (p *imagePipeline) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	p.logger.LogError(logEntry)
+}
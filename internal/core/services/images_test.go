@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/imaging"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/localfs"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/sqlite"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+func TestImagePipeline(t *testing.T) {
+	repo, err := sqlite.NewSQLiteClient(appConfig.Config{ARTICLE_TABLE: "Articles", SQLITE_PATH: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := localfs.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.CreateArticle(&domain.Article{ArticleID: "1", Title: "Article 1", Slug: "article-1", PublishDate: date, UpdatedDate: date})
+
+	images := imaging.NewImageProcessor(80)
+	pipeline := NewImagePipeline(repo, blobs, images, stubLogger{}, "https://cdn.example.com", []int{50, 100, 400}, 64, []string{"jpeg"}, 1<<20, 1, 1)
+	svc := NewMediaManagementService(repo, repo, blobs, images, pipeline, stubLogger{}, "https://cdn.example.com", 1<<20, 1<<20, []string{"image/png"})
+
+	// Queued, but left to be processed below
+	article, err := svc.SetCoverImage("1", "cover.png", bytes.NewReader(testPNG(t, 200, 40)))
+	if err != nil {
+		t.Fatalf("SetCoverImage: %v", err)
+	}
+	media := <-pipeline.queue
+	if media.URL != article.CoverImage {
+		t.Fatalf("Expected the cover queued, got %+v", media)
+	}
+	pipeline.Enqueue(media)
+	if pipeline.Enqueue(media) {
+		t.Errorf("Expected a full queue to refuse media")
+	}
+	<-pipeline.queue
+	small := NewImagePipeline(repo, blobs, images, stubLogger{}, "https://cdn.example.com", nil, 64, []string{"jpeg"}, 100, 1, 1)
+	if _, err := small.derive(media); !errors.Is(err, domain.ErrMediaTooLarge) {
+		t.Errorf("Expected images over the pixel limit left undecoded, got %v", err)
+	}
+	pipeline.process(media)
+
+	updated := article.UpdatedDate
+	article, _ = repo.GetArticleByID("1")
+	if !article.UpdatedDate.Equal(updated) {
+		t.Errorf("Expected the variants written without touching the article, got updated date %s", article.UpdatedDate)
+	}
+	want := []struct {
+		name          string
+		width, height int
+	}{{"thumbnail", 40, 40}, {"w50", 50, 10}, {"w100", 100, 20}}
+	if len(article.CoverVariants) != len(want) {
+		t.Fatalf("Expected a thumbnail and the narrower widths, got %+v", article.CoverVariants)
+	}
+	for i, variant := range article.CoverVariants {
+		size := want[i]
+		if variant.Name != size.name || variant.Format != "jpeg" || variant.Width != size.width || variant.Height != size.height {
+			t.Errorf("Expected %s jpeg of %dx%d, got %+v", size.name, size.width, size.height, variant)
+		}
+		if variant.URL != "https://cdn.example.com/"+variant.Key {
+			t.Errorf("Expected the URL under the base URL, got %q", variant.URL)
+		}
+		content, err := blobs.Get(variant.Key)
+		if err != nil {
+			t.Fatalf("Expected the variant stored, got %v", err)
+		}
+		data, _ := io.ReadAll(content)
+		content.Close()
+		img, err := images.Decode(data)
+		if err != nil || img.Bounds().Dx() != size.width {
+			t.Errorf("Expected a %s of width %d, got %v", size.name, size.width, err)
+		}
+	}
+
+	// Media that is not the cover leaves the variants of the cover be
+	other, err := svc.UploadMedia("1", "other.png", bytes.NewReader(testPNG(t, 80, 80)))
+	if err != nil {
+		t.Fatalf("UploadMedia: %v", err)
+	}
+	pipeline.process(*other)
+	if stored, _ := repo.GetArticleByID("1"); len(stored.CoverVariants) != len(article.CoverVariants) || stored.CoverVariants[0].Key != article.CoverVariants[0].Key {
+		t.Errorf("Expected the cover variants kept, got %+v", stored.CoverVariants)
+	}
+	if err := svc.DeleteMedia("1", other.MediaID); err != nil {
+		t.Fatalf("DeleteMedia: %v", err)
+	}
+
+	list, _ := svc.GetArticleMedia("1")
+	if len(*list) != 1 || len((*list)[0].Variants) != len(article.CoverVariants) {
+		t.Errorf("Expected the variants recorded with the media, got %+v", list)
+	}
+
+	if err := svc.DeleteMedia("1", media.MediaID); err != nil {
+		t.Fatalf("DeleteMedia: %v", err)
+	}
+	for _, variant := range (*list)[0].Variants {
+		if _, err := blobs.Get(variant.Key); err == nil {
+			t.Errorf("Expected variant %s deleted with the media", variant.Key)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// mediaExtensions names the files of the usual media types, mime picking an
+// arbitrary one of several extensions otherwise
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type mediaManagementService struct {
+	repo     ports.MediaRepository
+	articles ports.ArticleRepository
+	blobs    ports.BlobStore
+	images   ports.ImageProcessor
+	pipeline ports.ImagePipeline
+	logger   ports.LoggingService
+	// baseURL is prefixed to blob keys to give the URL of media
+	baseURL      string
+	maxSize      int64
+	maxPixels    int
+	allowedTypes []string
+	now          func() time.Time
+}
+
+// NewMediaManagementService stores uploads of the allowedTypes up to maxSize
+// bytes, and images up to maxPixels, in blobs, stripped of their metadata by images, and records them in
+// repo. The URL of media is its blob key appended to baseURL. Cover images
+// are queued with pipeline for their variants.
+func NewMediaManagementService(repo ports.MediaRepository, articles ports.ArticleRepository, blobs ports.BlobStore, images ports.ImageProcessor, pipeline ports.ImagePipeline, logger ports.LoggingService, baseURL string, maxSize int, maxPixels int, allowedTypes []string) *mediaManagementService {
+	svc := mediaManagementService{
+		repo:         repo,
+		articles:     articles,
+		blobs:        blobs,
+		images:       images,
+		pipeline:     pipeline,
+		logger:       logger,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		maxSize:      int64(maxSize),
+		maxPixels:    maxPixels,
+		allowedTypes: allowedTypes,
+		now:          time.Now,
+	}
+	return &svc
+}
+
+func (svc *mediaManagementService) UploadMedia(article_id string, filename string, content io.Reader) (*domain.Media, error) {
+	if _, err := svc.articles.GetArticleByID(article_id); err != nil {
+		return nil, err
+	}
+
+	// One byte over the limit tells a file of exactly maxSize bytes from a
+	// larger one
+	data, err := io.ReadAll(io.LimitReader(content, svc.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > svc.maxSize {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", domain.ErrMediaTooLarge, svc.maxSize)
+	}
+	// The type is detected from the content, clients cannot be trusted to
+	// name it
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !svc.allowed(contentType) {
+		return nil, fmt.Errorf("%w %s, expected one of %s", domain.ErrMediaType, contentType, strings.Join(svc.allowedTypes, ", "))
+	}
+	// The dimensions of images are read from the header, before anything
+	// decodes the pixels. An image whose header cannot be read is refused
+	// rather than let through unchecked.
+	width, height := 0, 0
+	if strings.HasPrefix(contentType, "image/") {
+		if width, height, err = svc.images.Dimensions(data); err != nil {
+			return nil, fmt.Errorf("%w %s, the image cannot be read: %v", domain.ErrMediaType, contentType, err)
+		}
+		if tooManyPixels(width, height, svc.maxPixels) {
+			return nil, fmt.Errorf("%w, %dx%d is over the limit of %d pixels", domain.ErrMediaTooLarge, width, height, svc.maxPixels)
+		}
+	}
+	// Where and with what a picture was taken is not for readers to know
+	if data, err = svc.images.StripMetadata(data); err != nil {
+		return nil, err
+	}
+
+	media := &domain.Media{
+		MediaID:     uuid.New().String(),
+		ArticleID:   article_id,
+		Filename:    path.Base(strings.ReplaceAll(filename, `\`, "/")),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedDate: svc.now().UTC().Truncate(time.Second),
+	}
+	if media.Filename == "." || media.Filename == "/" {
+		media.Filename = ""
+	}
+	media.Key = path.Join("articles", url.PathEscape(article_id), media.MediaID+extension(contentType))
+	media.URL = svc.baseURL + "/" + media.Key
+	media.Width, media.Height = width, height
+
+	if err := svc.blobs.Put(media.Key, contentType, bytes.NewReader(data)); err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	if _, err := svc.repo.CreateMedia(media); err != nil {
+		svc.logError(err)
+		// Content without a record could never be found again
+		svc.blobs.Delete(media.Key)
+		return nil, err
+	}
+	return media, nil
+}
+
+func (svc *mediaManagementService) SetCoverImage(article_id string, filename string, content io.Reader) (*domain.Article, error) {
+	media, err := svc.UploadMedia(article_id, filename, content)
+	if err != nil {
+		return nil, err
+	}
+	article, err := svc.setCover(article_id, media.URL)
+	if err != nil {
+		return nil, err
+	}
+	svc.pipeline.Enqueue(*media)
+	return article, nil
+}
+
+// setCover writes cover over the cover image of the article, leaving the
+// rest of it as it is
+func (svc *mediaManagementService) setCover(article_id string, cover string) (*domain.Article, error) {
+	article, err := svc.repo.SetArticleCover(article_id, cover, svc.now())
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return article, nil
+}
+
+func (svc *mediaManagementService) GetArticleMedia(article_id string) (*[]domain.Media, error) {
+	media, err := svc.repo.GetArticleMedia(article_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return media, nil
+}
+
+func (svc *mediaManagementService) DeleteMedia(article_id string, media_id string) error {
+	media, err := svc.repo.GetMedia(media_id)
+	if err != nil {
+		return err
+	}
+	if media.ArticleID != article_id {
+		return errors.New("media not found")
+	}
+	if err := svc.repo.DeleteMedia(media_id); err != nil {
+		svc.logError(err)
+		return err
+	}
+	// The record is gone, content left behind only costs storage
+	for _, key := range mediaKeys(*media) {
+		if err := svc.blobs.Delete(key); err != nil {
+			svc.logError(err)
+		}
+	}
+
+	article, err := svc.articles.GetArticleByID(article_id)
+	if err == nil && article.CoverImage == media.URL {
+		_, err = svc.setCover(article_id, "")
+	}
+	return err
+}
+
+func (svc *mediaManagementService) OpenMedia(key string) (io.ReadCloser, string, error) {
+	content, err := svc.blobs.Get(key)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return content, contentType, nil
+}
+
+func (svc *mediaManagementService) allowed(contentType string) bool {
+	for _, allowed := range svc.allowedTypes {
+		if strings.EqualFold(allowed, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (svc *mediaManagementService) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	svc.logger.LogError(logEntry)
+}
+
+// tooManyPixels reports whether an image of width by height is over
+// maxPixels, without overflowing
+func tooManyPixels(width int, height int, maxPixels int) bool {
+	return int64(width)*int64(height) > int64(maxPixels)
+}
+
+// mediaKeys returns the blob keys of media and of its variants
+func mediaKeys(media domain.Media) []string {
+	keys := []string{media.Key}
+	for _, variant := range media.Variants {
+		keys = append(keys, variant.Key)
+	}
+	return keys
+}
+
+func extension(contentType string) string {
+	if ext, ok := mediaExtensions[contentType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
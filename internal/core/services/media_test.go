@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/imaging"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/localfs"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/sqlite"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// stubPipeline records the media queued for variants
+type stubPipeline struct {
+	queued []domain.Media
+}
+
+func (p *stubPipeline) Enqueue(media domain.Media) bool {
+	p.queued = append(p.queued, media)
+	return true
+}
+
+func TestMediaManagementService(t *testing.T) {
+	repo, err := sqlite.NewSQLiteClient(appConfig.Config{ARTICLE_TABLE: "Articles", SQLITE_PATH: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := localfs.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "2"} {
+		repo.CreateArticle(&domain.Article{ArticleID: id, Title: "Article " + id, Slug: "article-" + id, PublishDate: date, UpdatedDate: date})
+	}
+	pipeline := &stubPipeline{}
+	svc := NewMediaManagementService(repo, repo, blobs, imaging.NewImageProcessor(80), pipeline, stubLogger{}, "https://cdn.example.com/", 1024, 100, []string{"image/png"})
+
+	image := testPNG(t, 3, 2)
+	if _, err := svc.UploadMedia("1", "big.png", bytes.NewReader(append(image, make([]byte, 1024)...))); !errors.Is(err, domain.ErrMediaTooLarge) {
+		t.Errorf("Expected ErrMediaTooLarge, got %v", err)
+	}
+	// The name does not make text an image
+	if _, err := svc.UploadMedia("1", "text.png", strings.NewReader("plain text")); !errors.Is(err, domain.ErrMediaType) {
+		t.Errorf("Expected ErrMediaType, got %v", err)
+	}
+	// Few bytes can hold many pixels
+	if _, err := svc.UploadMedia("1", "wide.png", bytes.NewReader(testPNG(t, 101, 1))); !errors.Is(err, domain.ErrMediaTooLarge) {
+		t.Errorf("Expected ErrMediaTooLarge over the pixel limit, got %v", err)
+	}
+	// An image whose size cannot be read is not let through unchecked
+	if _, err := svc.UploadMedia("1", "broken.png", strings.NewReader("\x89PNG\r\n\x1a\nbroken header")); !errors.Is(err, domain.ErrMediaType) {
+		t.Errorf("Expected ErrMediaType for an unreadable image, got %v", err)
+	}
+	if _, err := svc.UploadMedia("missing", "a.png", bytes.NewReader(image)); err == nil {
+		t.Errorf("Expected uploads to missing articles to fail")
+	}
+
+	media, err := svc.UploadMedia("1", `C:\photos\a.png`, bytes.NewReader(image))
+	if err != nil {
+		t.Fatalf("UploadMedia: %v", err)
+	}
+	if media.Filename != "a.png" || media.ContentType != "image/png" || media.Width != 3 || media.Height != 2 || media.Size != int64(len(image)) {
+		t.Errorf("Expected the upload described, got %+v", media)
+	}
+	if !strings.HasPrefix(media.Key, "articles/1/") || media.URL != "https://cdn.example.com/"+media.Key {
+		t.Errorf("Expected the key under the article and the URL under the base URL, got %q, %q", media.Key, media.URL)
+	}
+
+	article, err := svc.SetCoverImage("1", "cover.png", bytes.NewReader(image))
+	if err != nil {
+		t.Fatalf("SetCoverImage: %v", err)
+	}
+	list, err := svc.GetArticleMedia("1")
+	if err != nil || len(*list) != 2 {
+		t.Fatalf("Expected two media, got %v, %v", list, err)
+	}
+	// Both uploads fall in the same second, leaving their order to the ids
+	cover := (*list)[0]
+	if cover.MediaID == media.MediaID {
+		cover = (*list)[1]
+	}
+	if article.CoverImage != cover.URL || article.Title != "Article 1" {
+		t.Errorf("Expected only the cover image to change, got %+v", article)
+	}
+	if len(pipeline.queued) != 1 || pipeline.queued[0].MediaID != cover.MediaID {
+		t.Errorf("Expected only the cover queued for variants, got %+v", pipeline.queued)
+	}
+
+	// Updates of the article leave its cover be, whatever they carry
+	variants := []domain.ImageVariant{{Name: "thumbnail", Format: "jpeg", Key: "thumbnail.jpg"}}
+	if err := repo.SetCoverVariants("1", cover.URL, variants); err != nil {
+		t.Fatalf("SetCoverVariants: %v", err)
+	}
+	articles := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+	if _, err := articles.UpdateArticle("1", &domain.Article{Title: "Article 1", Body: "New body"}); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	if _, err := articles.UpdateArticle("1", &domain.Article{Title: "Article 1", CoverImage: "https://elsewhere.example/a.png"}); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	if article, _ := repo.GetArticleByID("1"); article.CoverImage != cover.URL || len(article.CoverVariants) != 1 {
+		t.Errorf("Expected the cover and its variants kept by updates, got %q, %+v", article.CoverImage, article.CoverVariants)
+	}
+
+	content, contentType, err := svc.OpenMedia(cover.Key)
+	if err != nil {
+		t.Fatalf("OpenMedia: %v", err)
+	}
+	stored, _ := io.ReadAll(content)
+	content.Close()
+	if contentType != "image/png" || !bytes.Equal(stored, image) {
+		t.Errorf("Expected the uploaded image back, got %s of %d bytes", contentType, len(stored))
+	}
+
+	if err := svc.DeleteMedia("2", cover.MediaID); err == nil {
+		t.Errorf("Expected media of another article to stay")
+	}
+	if err := svc.DeleteMedia("1", cover.MediaID); err != nil {
+		t.Fatalf("DeleteMedia: %v", err)
+	}
+	if _, _, err := svc.OpenMedia(cover.Key); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the content deleted, got %v", err)
+	}
+	if article, _ := repo.GetArticleByID("1"); article.CoverImage != "" {
+		t.Errorf("Expected the cover image cleared, got %q", article.CoverImage)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// purgeWorker permanently removes articles that have been in the trash for
+// longer than the retention period, along with the content of their media.
+type purgeWorker struct {
+	repo ports.ArticleRepository
+	// blobs is nil for stores without media
+	blobs     ports.BlobStore
+	logger    ports.LoggingService
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurgeWorker(repo ports.ArticleRepository, blobs ports.BlobStore, logger ports.LoggingService, retention, interval time.Duration) *purgeWorker {
+	worker := purgeWorker{
+		repo:      repo,
+		blobs:     blobs,
+		logger:    logger,
+		retention: retention,
+		interval:  interval,
+	}
+	return &worker
+}
+
+// Run purges once straight away and then on every interval until stop is
+// closed.
+func (w *purgeWorker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Purge()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *purgeWorker) Purge() (int64, error) {
+	count, media, err := w.repo.PurgeDeletedArticles(time.Now().Add(-w.retention))
+	if err != nil {
+		w.logError(err)
+		return 0, err
+	}
+	// The records are gone, content left behind only costs storage
+	if w.blobs != nil {
+		for _, item := range media {
+			for _, key := range mediaKeys(item) {
+				if err := w.blobs.Delete(key); err != nil {
+					w.logError(err)
+				}
+			}
+		}
+	}
+	if count > 0 {
+		logEntry := domain.LogMessage{
+			LogLevel: "INFO",
+			Service:  "articles",
+			Message:  fmt.Sprintf("%d deleted articles purged", count),
+		}
+		w.logger.LogInfo(logEntry)
+	}
+	return count, nil
+}
+
+func (w *purgeWorker) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	w.logger.LogError(logEntry)
+}
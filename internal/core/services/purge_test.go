@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/localfs"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+type purgeRepository struct {
+	ports.ArticleRepository
+	before time.Time
+	media  []domain.Media
+}
+
+func (repo *purgeRepository) PurgeDeletedArticles(before time.Time) (int64, []domain.Media, error) {
+	repo.before = before
+	return 2, repo.media, nil
+}
+
+func TestPurgeWorker(t *testing.T) {
+	blobs, err := localfs.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{"articles/1/m1.png", "articles/1/m1-480.jpeg", "articles/2/m2.png"}
+	for _, key := range keys {
+		if err := blobs.Put(key, "image/png", strings.NewReader("content")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := &purgeRepository{media: []domain.Media{
+		{MediaID: "m1", Key: keys[0], Variants: []domain.ImageVariant{{Key: keys[1]}}},
+		{MediaID: "m2", Key: keys[2]},
+	}}
+	worker := NewPurgeWorker(repo, blobs, stubLogger{}, 48*time.Hour, time.Hour)
+
+	count, err := worker.Purge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 purged articles, got %d", count)
+	}
+	cutoff := time.Now().Add(-48 * time.Hour)
+	if repo.before.Sub(cutoff) > time.Second || cutoff.Sub(repo.before) > time.Second {
+		t.Errorf("Expected purge cutoff around %s, got %s", cutoff, repo.before)
+	}
+	for _, key := range keys {
+		if _, err := blobs.Get(key); err == nil {
+			t.Errorf("Expected the content under %s to be deleted", key)
+		}
+	}
+
+	// Stores without media have no blob store
+	if _, err := NewPurgeWorker(repo, nil, stubLogger{}, 48*time.Hour, time.Hour).Purge(); err != nil {
+		t.Errorf("Expected a purge without a blob store, got %v", err)
+	}
+}
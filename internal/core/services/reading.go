@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+const (
+	// wordsPerMinute is the average adult silent reading speed
+	wordsPerMinute = 200
+	// excerptLength is the maximum excerpt length in characters
+	excerptLength = 200
+)
+
+// setReadingStats derives the word count, reading time and excerpt of an
+// article from its introduction and body.
+func (svc *articleManagementService) setReadingStats(article *domain.Article) error {
+	introduction, err := svc.renderer.PlainText(article.Introduction)
+	if err != nil {
+		return err
+	}
+	body, err := svc.renderer.PlainText(article.Body)
+	if err != nil {
+		return err
+	}
+
+	words := len(strings.Fields(introduction)) + len(strings.Fields(body))
+	article.WordCount = words
+	article.ReadingMinutes = (words + wordsPerMinute - 1) / wordsPerMinute
+
+	if introduction != "" {
+		article.Excerpt = excerpt(introduction, excerptLength)
+	} else {
+		article.Excerpt = excerpt(body, excerptLength)
+	}
+	return nil
+}
+
+// excerpt shortens text to at most limit characters, cutting at a word
+// boundary and marking the cut with an ellipsis.
+func excerpt(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)[:limit]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+func TestSetReadingStats(t *testing.T) {
+	articleService := NewArticleManagementService(nil, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	t.Run("Test stats from markdown body", func(t *testing.T) {
+		article := &domain.Article{
+			Body: "# Heading\n\nSome **bold** text and a [link](https://example.com).\n\n" + strings.Repeat("word ", 395),
+		}
+		if err := articleService.setReadingStats(article); err != nil {
+			t.Fatal(err)
+		}
+		if article.WordCount != 402 {
+			t.Errorf("Expected 402 words, got %d", article.WordCount)
+		}
+		if article.ReadingMinutes != 3 {
+			t.Errorf("Expected 3 reading minutes, got %d", article.ReadingMinutes)
+		}
+		if !strings.HasPrefix(article.Excerpt, "Heading Some bold text and a link. word") {
+			t.Errorf("Expected plain text excerpt, got %q", article.Excerpt)
+		}
+		if !strings.HasSuffix(article.Excerpt, "…") || len([]rune(article.Excerpt)) > excerptLength+1 {
+			t.Errorf("Expected excerpt cut at %d characters, got %q", excerptLength, article.Excerpt)
+		}
+	})
+
+	t.Run("Test excerpt prefers introduction", func(t *testing.T) {
+		article := &domain.Article{
+			Introduction: "A short _introduction_.",
+			Body:         "The body.",
+		}
+		if err := articleService.setReadingStats(article); err != nil {
+			t.Fatal(err)
+		}
+		if article.Excerpt != "A short introduction." {
+			t.Errorf("Expected introduction as excerpt, got %q", article.Excerpt)
+		}
+		if article.WordCount != 5 || article.ReadingMinutes != 1 {
+			t.Errorf("Expected 5 words and 1 minute, got %d and %d", article.WordCount, article.ReadingMinutes)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// eventLease is how long a claimed event is hidden from other relays while
+// it is being published
+const eventLease = time.Minute
+
+// outboxRelay delivers the events written to the outbox. An event is only
+// marked delivered after the publisher accepted it, so a crash between the
+// two steps delivers it again: consumers get every event at least once and
+// must tolerate duplicates, which they can spot by event_id.
+type outboxRelay struct {
+	outbox      ports.OutboxRepository
+	publisher   ports.EventPublisher
+	logger      ports.LoggingService
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	retryBase   time.Duration
+	now         func() time.Time
+}
+
+// NewOutboxRelay relays events every interval. A failed event is retried
+// with exponential backoff from retryBase and dead after maxAttempts.
+func NewOutboxRelay(outbox ports.OutboxRepository, publisher ports.EventPublisher, logger ports.LoggingService, interval time.Duration, batchSize int, maxAttempts int, retryBase time.Duration) *outboxRelay {
+	relay := outboxRelay{
+		outbox:      outbox,
+		publisher:   publisher,
+		logger:      logger,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		retryBase:   retryBase,
+		now:         time.Now,
+	}
+	return &relay
+}
+
+// Run relays pending events every interval until stop is closed.
+func (r *outboxRelay) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while events are delivered so a backlog drains
+		// without waiting for the next tick
+		for {
+			delivered, err := r.RelayPending()
+			if err != nil || delivered == 0 {
+				break
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of due events and returns how many were
+// delivered. A failed event is rescheduled, or dead once it has run out of
+// attempts, without holding up the events of other articles; the later
+// events of its own article wait for it, as the repository only hands out
+// the oldest pending event of each article.
+func (r *outboxRelay) RelayPending() (int, error) {
+	events, err := r.outbox.ClaimPendingEvents(r.batchSize, eventLease)
+	if err != nil {
+		r.logError(err)
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		if err := r.publisher.Publish(event); err != nil {
+			r.failed(event, err)
+			continue
+		}
+		if err := r.outbox.MarkEventDelivered(event.EventID); err != nil {
+			r.logError(err)
+			continue
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// failed records a failed attempt at publishing event.
+func (r *outboxRelay) failed(event domain.Event, err error) {
+	attempts := event.Attempts + 1
+	if attempts >= r.maxAttempts {
+		r.logError(fmt.Errorf("event [%s] is dead after %d attempts: %v", event.EventID, attempts, err))
+		if markErr := r.outbox.MarkEventDead(event.EventID, err.Error()); markErr != nil {
+			r.logError(markErr)
+		}
+		return
+	}
+
+	r.logError(fmt.Errorf("publishing event [%s] failed: %v", event.EventID, err))
+	next := r.now().Add(backoff(r.retryBase, attempts))
+	if markErr := r.outbox.MarkEventFailed(event.EventID, err.Error(), next); markErr != nil {
+		r.logError(markErr)
+	}
+}
+
+func (r *outboxRelay) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	r.logger.LogError(logEntry)
+}
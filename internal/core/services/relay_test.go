@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/events/memory"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// stubOutbox is an in memory outbox that records delivery attempts
+type stubOutbox struct {
+	events    []domain.Event
+	delivered map[string]bool
+	dead      map[string]bool
+	next      map[string]time.Time
+	now       time.Time
+}
+
+func newStubOutbox(events ...domain.Event) *stubOutbox {
+	return &stubOutbox{
+		events:    events,
+		delivered: map[string]bool{},
+		dead:      map[string]bool{},
+		next:      map[string]time.Time{},
+		now:       time.Now(),
+	}
+}
+
+func (o *stubOutbox) ClaimPendingEvents(limit int, lease time.Duration) ([]domain.Event, error) {
+	claimed := []domain.Event{}
+	blocked := map[string]bool{}
+	for _, event := range o.events {
+		if o.delivered[event.EventID] || o.dead[event.EventID] || blocked[event.ArticleID] {
+			continue
+		}
+		blocked[event.ArticleID] = true
+		if o.next[event.EventID].After(o.now) || len(claimed) == limit {
+			continue
+		}
+		o.next[event.EventID] = o.now.Add(lease)
+		claimed = append(claimed, event)
+	}
+	return claimed, nil
+}
+
+func (o *stubOutbox) MarkEventDelivered(event_id string) error {
+	o.delivered[event_id] = true
+	return nil
+}
+
+func (o *stubOutbox) MarkEventFailed(event_id string, reason string, next_attempt_at time.Time) error {
+	o.attempted(event_id)
+	o.next[event_id] = next_attempt_at
+	return nil
+}
+
+func (o *stubOutbox) MarkEventDead(event_id string, reason string) error {
+	o.attempted(event_id)
+	o.dead[event_id] = true
+	return nil
+}
+
+func (o *stubOutbox) attempted(event_id string) {
+	for i := range o.events {
+		if o.events[i].EventID == event_id {
+			o.events[i].Attempts++
+		}
+	}
+}
+
+// articlePublisher fails the events of the articles in failing
+type articlePublisher struct {
+	ports.EventPublisher
+	failing map[string]bool
+}
+
+func (p *articlePublisher) Publish(event domain.Event) error {
+	if p.failing[event.ArticleID] {
+		return errors.New("subscriber down")
+	}
+	return p.EventPublisher.Publish(event)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	article := &domain.Article{ArticleID: "a1", PublishDate: time.Now().Add(-time.Hour)}
+	outbox := newStubOutbox(domain.ArticleWriteEvents(nil, article)...)
+	outbox.events = append(outbox.events, domain.NewArticleEvent(domain.EventArticleDeleted, article))
+	outbox.events = append(outbox.events, domain.NewArticleEvent(domain.EventArticleCreated, &domain.Article{ArticleID: "a2"}))
+	memoryPublisher := memory.NewMemoryPublisher()
+	publisher := &articlePublisher{EventPublisher: memoryPublisher, failing: map[string]bool{"a1": true}}
+	relay := NewOutboxRelay(outbox, publisher, stubLogger{}, time.Second, 10, 5, time.Minute)
+	relay.now = func() time.Time { return outbox.now }
+
+	delivered, err := relay.RelayPending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 1 {
+		t.Fatalf("Expected the event of a2 to be delivered past the failing a1, got %d delivered", delivered)
+	}
+	if outbox.events[0].Attempts != 1 || outbox.delivered[outbox.events[0].EventID] {
+		t.Errorf("Expected failed event to stay pending with one attempt")
+	}
+	if !outbox.next[outbox.events[0].EventID].Equal(outbox.now.Add(time.Minute)) {
+		t.Errorf("Expected failed event to be retried after the base delay, got %v", outbox.next[outbox.events[0].EventID])
+	}
+	if delivered, _ := relay.RelayPending(); delivered != 0 {
+		t.Errorf("Expected the failed event to back off, got %d delivered", delivered)
+	}
+
+	publisher.failing = map[string]bool{}
+	outbox.now = outbox.now.Add(time.Minute)
+	for {
+		delivered, err := relay.RelayPending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivered == 0 {
+			break
+		}
+	}
+
+	types := []string{}
+	for _, event := range memoryPublisher.Events() {
+		if event.ArticleID == "a1" {
+			types = append(types, event.EventType)
+		}
+	}
+	expected := []string{domain.EventArticleCreated, domain.EventArticlePublished, domain.EventArticleDeleted}
+	if len(types) != len(expected) {
+		t.Fatalf("Expected events %v in order, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("Expected events %v in order, got %v", expected, types)
+		}
+	}
+}
+
+func TestOutboxRelayDeadEvent(t *testing.T) {
+	article := &domain.Article{ArticleID: "a1"}
+	outbox := newStubOutbox(
+		domain.NewArticleEvent(domain.EventArticleCreated, article),
+		domain.NewArticleEvent(domain.EventArticleDeleted, article),
+	)
+	memoryPublisher := memory.NewMemoryPublisher()
+	publisher := &articlePublisher{EventPublisher: memoryPublisher, failing: map[string]bool{"a1": true}}
+	relay := NewOutboxRelay(outbox, publisher, stubLogger{}, time.Second, 10, 2, time.Minute)
+	relay.now = func() time.Time { return outbox.now }
+
+	first := outbox.events[0].EventID
+	for attempt := 0; attempt < 2; attempt++ {
+		relay.RelayPending()
+		outbox.now = outbox.now.Add(time.Hour)
+	}
+	if !outbox.dead[first] || outbox.events[0].Attempts != 2 {
+		t.Fatalf("Expected the event to be dead after 2 attempts, got %d attempts", outbox.events[0].Attempts)
+	}
+
+	publisher.failing = map[string]bool{}
+	if delivered, _ := relay.RelayPending(); delivered != 1 {
+		t.Fatalf("Expected the next event of the article to go ahead, got %d delivered", delivered)
+	}
+	if events := memoryPublisher.Events(); events[0].EventType != domain.EventArticleDeleted {
+		t.Errorf("Expected the deleted event, got %s", events[0].EventType)
+	}
+}
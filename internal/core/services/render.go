@@ -0,0 +1,92 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// renderCacheSize is the number of rendered article versions kept in memory
+const renderCacheSize = 1000
+
+// GetArticleHTML returns the article with BodyHTML set to its rendered,
+// sanitized body. Rendering is cached by the content of the body, so any
+// change to it misses the cache, whether or not the updated date moves.
+func (svc *articleManagementService) GetArticleHTML(article_id string) (*domain.Article, error) {
+	article, err := svc.GetArticleByID(article_id)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(article.Body))
+	key := hex.EncodeToString(sum[:])
+	if html, ok := svc.renderCache.get(key); ok {
+		article.BodyHTML = html
+		return article, nil
+	}
+
+	html, err := svc.renderer.RenderHTML(article.Body)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	svc.renderCache.add(key, html)
+	article.BodyHTML = html
+	return article, nil
+}
+
+// renderCache is a fixed size LRU cache of rendered article bodies
+type renderCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type renderCacheEntry struct {
+	key  string
+	html string
+}
+
+func newRenderCache(capacity int) *renderCache {
+	return &renderCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (c *renderCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*renderCacheEntry).html, true
+}
+
+func (c *renderCache) add(key, html string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*renderCacheEntry).html = html
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&renderCacheEntry{key: key, html: html})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*renderCacheEntry).key)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/sqlite"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// countingRenderer wraps bodies in a paragraph and counts its calls
+type countingRenderer struct {
+	ports.ContentRenderer
+	calls int
+}
+
+func (r *countingRenderer) RenderHTML(source string) (string, error) {
+	r.calls++
+	return "<p>" + source + "</p>", nil
+}
+
+func TestGetArticleHTML(t *testing.T) {
+	repo, err := sqlite.NewSQLiteClient(appConfig.Config{ARTICLE_TABLE: "Articles", SQLITE_PATH: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.CreateArticle(&domain.Article{ArticleID: "1", Title: "Article 1", Slug: "article-1", Body: "first", PublishDate: date, UpdatedDate: date})
+	renderer := &countingRenderer{ContentRenderer: markdown.NewMarkdownRenderer()}
+	svc := NewArticleManagementService(repo, stubLogger{}, renderer)
+
+	render := func(want string) {
+		t.Helper()
+		article, err := svc.GetArticleHTML("1")
+		if err != nil {
+			t.Fatalf("GetArticleHTML: %v", err)
+		}
+		if article.BodyHTML != want {
+			t.Errorf("Expected %q, got %q", want, article.BodyHTML)
+		}
+	}
+
+	render("<p>first</p>")
+	render("<p>first</p>")
+	if renderer.calls != 1 {
+		t.Errorf("Expected the second read served from the cache, got %d renders", renderer.calls)
+	}
+
+	// An import may replace the body while keeping the updated date
+	if _, err := repo.UpsertArticles([]domain.Article{{ArticleID: "1", Title: "Article 1", Slug: "article-1", Body: "second", PublishDate: date, UpdatedDate: date}}); err != nil {
+		t.Fatalf("UpsertArticles: %v", err)
+	}
+	render("<p>second</p>")
+	if renderer.calls != 2 {
+		t.Errorf("Expected the new body rendered, got %d renders", renderer.calls)
+	}
+}
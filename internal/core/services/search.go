@@ -0,0 +1,24 @@
+package services
+
+import (
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+func (svc *articleManagementService) SearchArticles(text string, limit int) (*[]domain.Article, error) {
+	searcher, ok := svc.repo.(ports.ArticleSearcher)
+	if !ok {
+		return nil, domain.ErrSearchUnsupported
+	}
+	articles, err := searcher.SearchArticles(text, limit)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	return articles, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+type seriesManagementService struct {
+	repo     ports.SeriesRepository
+	articles ports.ArticleRepository
+	logger   ports.LoggingService
+	now      func() time.Time
+}
+
+// NewSeriesManagementService keeps series in repo. The articles of a series
+// are looked up in articles to check they belong to its author.
+func NewSeriesManagementService(repo ports.SeriesRepository, articles ports.ArticleRepository, logger ports.LoggingService) *seriesManagementService {
+	svc := seriesManagementService{
+		repo:     repo,
+		articles: articles,
+		logger:   logger,
+		now:      time.Now,
+	}
+	return &svc
+}
+
+func (svc *seriesManagementService) CreateSeries(series *domain.Series) (*domain.Series, error) {
+	series.SeriesID = uuid.New().String()
+	if strings.TrimSpace(series.AuthorID) == "" {
+		return nil, errors.New("series author_id is required")
+	}
+	if err := svc.validate(series); err != nil {
+		return nil, err
+	}
+	series.CreatedDate = svc.now()
+	series.UpdatedDate = series.CreatedDate
+
+	series, err := svc.repo.CreateSeries(series)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	svc.logInfo(fmt.Sprintf("Series with ID [%s] created successufly", series.SeriesID))
+	return series, nil
+}
+
+func (svc *seriesManagementService) GetSeries(series_id string) (*domain.Series, error) {
+	series, err := svc.repo.GetSeries(series_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return series, nil
+}
+
+func (svc *seriesManagementService) GetSeriesList(author_id string) (*[]domain.Series, error) {
+	list, err := svc.repo.GetSeriesList(author_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateSeries replaces the title, description and articles of a series.
+// The series stays with the author who created it.
+func (svc *seriesManagementService) UpdateSeries(series_id string, series *domain.Series) (*domain.Series, error) {
+	existing, err := svc.repo.GetSeries(series_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	if series.AuthorID != "" && series.AuthorID != existing.AuthorID {
+		return nil, errors.New("series cannot change author")
+	}
+	series.SeriesID = series_id
+	series.AuthorID = existing.AuthorID
+	if err := svc.validate(series); err != nil {
+		return nil, err
+	}
+	series.CreatedDate = existing.CreatedDate
+	series.UpdatedDate = svc.now()
+
+	series, err = svc.repo.UpdateSeries(series_id, series)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	svc.logInfo(fmt.Sprintf("Series with ID [%s] updated successufly", series_id))
+	return series, nil
+}
+
+func (svc *seriesManagementService) DeleteSeries(series_id string) error {
+	if err := svc.repo.DeleteSeries(series_id); err != nil {
+		svc.logError(err)
+		return err
+	}
+	svc.logInfo(fmt.Sprintf("Series with ID [%s] deleted successufly", series_id))
+	return nil
+}
+
+// validate checks that every article of series is one of its author's, is
+// listed once and belongs to no other series
+func (svc *seriesManagementService) validate(series *domain.Series) error {
+	if strings.TrimSpace(series.Title) == "" {
+		return errors.New("series title is required")
+	}
+	if series.ArticleIDs == nil {
+		series.ArticleIDs = []string{}
+	}
+	seen := map[string]bool{}
+	for _, article_id := range series.ArticleIDs {
+		if seen[article_id] {
+			return fmt.Errorf("article %s is listed more than once", article_id)
+		}
+		seen[article_id] = true
+
+		article, err := svc.articles.GetArticleByID(article_id)
+		if err != nil {
+			return fmt.Errorf("article %s not found", article_id)
+		}
+		if article.AuthorID != series.AuthorID {
+			return fmt.Errorf("article %s is not written by the author of the series", article_id)
+		}
+		other, err := svc.repo.GetArticleSeries(article_id)
+		if err != nil {
+			svc.logError(err)
+			return err
+		}
+		if other != nil && other.SeriesID != series.SeriesID {
+			return fmt.Errorf("article %s already belongs to series %s", article_id, other.SeriesID)
+		}
+	}
+	return nil
+}
+
+func (svc *seriesManagementService) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	svc.logger.LogError(logEntry)
+}
+
+func (svc *seriesManagementService) logInfo(message string) {
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  message,
+	}
+	svc.logger.LogInfo(logEntry)
+}
+
+// seriesNavigation places article within its series when the repository
+// keeps series. Failing to do so leaves the article without navigation
+// rather than failing the read.
+func (svc *articleManagementService) seriesNavigation(article *domain.Article) *domain.SeriesNavigation {
+	repo, ok := svc.repo.(ports.SeriesRepository)
+	if !ok {
+		return nil
+	}
+	series, err := repo.GetArticleSeries(article.ArticleID)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil
+	}
+	if series == nil {
+		return nil
+	}
+
+	position := -1
+	for i, article_id := range series.ArticleIDs {
+		if article_id == article.ArticleID {
+			position = i
+			break
+		}
+	}
+	if position < 0 {
+		return nil
+	}
+	return &domain.SeriesNavigation{
+		SeriesID: series.SeriesID,
+		Title:    series.Title,
+		Position: position + 1,
+		Total:    len(series.ArticleIDs),
+		Previous: svc.seriesNeighbour(series.ArticleIDs, position, -1),
+		Next:     svc.seriesNeighbour(series.ArticleIDs, position, 1),
+	}
+}
+
+// seriesNeighbour links to the nearest published article from position in
+// the given direction. Trashed and scheduled articles are skipped.
+func (svc *articleManagementService) seriesNeighbour(article_ids []string, position int, direction int) *domain.ArticleLink {
+	now := time.Now()
+	for i := position + direction; i >= 0 && i < len(article_ids); i += direction {
+		article, err := svc.repo.GetArticleByID(article_ids[i])
+		if err != nil || !article.IsPublished(now) {
+			continue
+		}
+		return &domain.ArticleLink{ArticleID: article.ArticleID, Slug: article.Slug, Title: article.Title}
+	}
+	return nil
+}
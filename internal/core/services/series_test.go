@@ -0,0 +1,107 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appConfig "github.com/AntonyIS/notelify-articles-service/config"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/repository/sqlite"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+func TestSeriesManagementService(t *testing.T) {
+	repo, err := sqlite.NewSQLiteClient(appConfig.Config{ARTICLE_TABLE: "Articles", SQLITE_PATH: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "2", "3", "4", "other"} {
+		article := &domain.Article{ArticleID: id, Title: "Part " + id, Slug: "part-" + id, AuthorID: "author-1", PublishDate: date, UpdatedDate: date}
+		if id == "other" {
+			article.AuthorID = "author-2"
+		}
+		// Part 3 is scheduled
+		if id == "3" {
+			article.PublishDate = time.Now().Add(time.Hour)
+		}
+		repo.CreateArticle(article)
+	}
+	svc := NewSeriesManagementService(repo, repo, stubLogger{})
+	articleService := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	invalid := map[string]*domain.Series{
+		"no title":        {AuthorID: "author-1"},
+		"no author":       {Title: "Tutorial"},
+		"missing article": {AuthorID: "author-1", Title: "Tutorial", ArticleIDs: []string{"missing"}},
+		"other author":    {AuthorID: "author-1", Title: "Tutorial", ArticleIDs: []string{"other"}},
+		"listed twice":    {AuthorID: "author-1", Title: "Tutorial", ArticleIDs: []string{"1", "1"}},
+	}
+	for name, series := range invalid {
+		if _, err := svc.CreateSeries(series); err == nil {
+			t.Errorf("Expected a series with %s to be refused", name)
+		}
+	}
+
+	series, err := svc.CreateSeries(&domain.Series{AuthorID: "author-1", Title: "Tutorial", ArticleIDs: []string{"1", "2", "3", "4"}})
+	if err != nil {
+		t.Fatalf("CreateSeries: %v", err)
+	}
+	if _, err := svc.CreateSeries(&domain.Series{AuthorID: "author-1", Title: "Again", ArticleIDs: []string{"2"}}); err == nil || !strings.Contains(err.Error(), series.SeriesID) {
+		t.Errorf("Expected articles to belong to one series, got %v", err)
+	}
+
+	article, err := articleService.GetArticleByID("4")
+	if err != nil {
+		t.Fatalf("GetArticleByID: %v", err)
+	}
+	want := &domain.SeriesNavigation{
+		SeriesID: series.SeriesID,
+		Title:    "Tutorial",
+		Position: 4,
+		Total:    4,
+		// The scheduled part is skipped
+		Previous: &domain.ArticleLink{ArticleID: "2", Slug: "part-2", Title: "Part 2"},
+	}
+	if !reflect.DeepEqual(article.Series, want) {
+		t.Errorf("Expected %+v, got %+v", want, article.Series)
+	}
+	if article, _ := articleService.GetArticleByID("1"); article.Series == nil || article.Series.Previous != nil || article.Series.Next.ArticleID != "2" {
+		t.Errorf("Expected the first part to lead to the second, got %+v", article.Series)
+	}
+	if article, _ := articleService.GetArticleByID("other"); article.Series != nil {
+		t.Errorf("Expected no navigation outside a series, got %+v", article.Series)
+	}
+
+	if _, err := svc.UpdateSeries(series.SeriesID, &domain.Series{AuthorID: "author-2", Title: "Tutorial"}); err == nil {
+		t.Errorf("Expected the author of a series to stay")
+	}
+	updated, err := svc.UpdateSeries(series.SeriesID, &domain.Series{Title: "Tutorial, reordered", ArticleIDs: []string{"4", "2"}})
+	if err != nil {
+		t.Fatalf("UpdateSeries: %v", err)
+	}
+	if updated.AuthorID != "author-1" || !updated.CreatedDate.Equal(series.CreatedDate) {
+		t.Errorf("Expected the author and creation date kept, got %+v", updated)
+	}
+	if article, _ := articleService.GetArticleByID("4"); article.Series == nil || article.Series.Position != 1 || article.Series.Next.ArticleID != "2" {
+		t.Errorf("Expected the reordered series, got %+v", article.Series)
+	}
+	if article, _ := articleService.GetArticleByID("1"); article.Series != nil {
+		t.Errorf("Expected article 1 out of the series, got %+v", article.Series)
+	}
+
+	if list, err := svc.GetSeriesList("author-1"); err != nil || len(*list) != 1 {
+		t.Errorf("Expected the series of author-1, got %v, %v", list, err)
+	}
+	if err := svc.DeleteSeries(series.SeriesID); err != nil {
+		t.Fatalf("DeleteSeries: %v", err)
+	}
+	if _, err := svc.GetSeries(series.SeriesID); err == nil {
+		t.Errorf("Expected the series deleted")
+	}
+	if article, err := articleService.GetArticleByID("4"); err != nil || article.Series != nil {
+		t.Errorf("Expected the article kept without navigation, got %+v, %v", article, err)
+	}
+}
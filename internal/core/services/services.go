@@ -3,6 +3,7 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -15,14 +16,18 @@ import (
 )
 
 type articleManagementService struct {
-	repo   ports.ArticleRepository
-	logger ports.LoggingService
+	repo        ports.ArticleRepository
+	logger      ports.LoggingService
+	renderer    ports.ContentRenderer
+	renderCache *renderCache
 }
 
-func NewArticleManagementService(repo ports.ArticleRepository, logger ports.LoggingService) *articleManagementService {
+func NewArticleManagementService(repo ports.ArticleRepository, logger ports.LoggingService, renderer ports.ContentRenderer) *articleManagementService {
 	svc := articleManagementService{
-		repo:   repo,
-		logger: logger,
+		repo:        repo,
+		logger:      logger,
+		renderer:    renderer,
+		renderCache: newRenderCache(renderCacheSize),
 	}
 	return &svc
 }
@@ -37,8 +42,25 @@ func (svc *articleManagementService) CreateArticle(article *domain.Article) (*do
 	article.ArticleID = uuid.New().String()
 	article.PublishDate = time.Now()
 	article.UpdatedDate = time.Now()
+	// Covers are uploaded through the media service
+	article.CoverImage, article.CoverVariants = "", nil
 
-	article, err := svc.repo.CreateArticle(article)
+	if err := svc.setReadingStats(article); err != nil {
+		return nil, err
+	}
+	// Another article may take the slug between checking and writing it,
+	// the next free one is tried then
+	var created *domain.Article
+	var err error
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		if article.Slug, err = svc.uniqueSlug(article.Title, article.ArticleID, nil); err != nil {
+			return nil, err
+		}
+		if created, err = svc.repo.CreateArticle(article); !errors.Is(err, domain.ErrSlugTaken) {
+			break
+		}
+	}
+	article = created
 	if err != nil {
 		logEntry := domain.LogMessage{
 			LogLevel: "ERROR",
@@ -74,6 +96,7 @@ func (svc *articleManagementService) GetArticleByID(article_id string) (*domain.
 		Message:  "Article with ID [%s] found successufly",
 	}
 	svc.logger.LogInfo(logEntry)
+	article.Series = svc.seriesNavigation(article)
 	return article, nil
 }
 
@@ -128,6 +151,43 @@ func (svc *articleManagementService) GetArticlesByTag(tag string) (*[]domain.Art
 	return &articleArray, nil
 }
 
+func (svc *articleManagementService) FindArticles(query domain.ArticleQuery) (*[]domain.Article, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	articles, err := svc.repo.FindArticles(query)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  "Articles matching query found successufly",
+	}
+	svc.logger.LogInfo(logEntry)
+	return articles, nil
+}
+
+func (svc *articleManagementService) GetAuthors(author_ids []string) (map[string]domain.Author, error) {
+	authors, err := svc.repo.GetAuthors(author_ids)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	return authors, nil
+}
+
 func (svc *articleManagementService) GetArticles() (*[]domain.Article, error) {
 	artciles, err := svc.repo.GetArticles()
 	if err != nil {
@@ -149,7 +209,31 @@ func (svc *articleManagementService) GetArticles() (*[]domain.Article, error) {
 }
 
 func (svc *articleManagementService) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
-	article, err := svc.repo.UpdateArticle(article_id, article)
+	article.UpdatedDate = time.Now()
+
+	if err := svc.setReadingStats(article); err != nil {
+		return nil, err
+	}
+	existing, err := svc.repo.GetArticleByID(article_id)
+	if err != nil {
+		return nil, err
+	}
+	// Only a title change moves the article to a new slug; the repository
+	// keeps the old one resolvable. Another article may take the new slug
+	// between checking and writing it, the next free one is tried then
+	var updated *domain.Article
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		article.Slug = existing.Slug
+		if article.Title != existing.Title {
+			if article.Slug, err = svc.uniqueSlug(article.Title, article_id, nil); err != nil {
+				return nil, err
+			}
+		}
+		if updated, err = svc.repo.UpdateArticle(article_id, article); !errors.Is(err, domain.ErrSlugTaken) {
+			break
+		}
+	}
+	article = updated
 	if err != nil {
 		logEntry := domain.LogMessage{
 			LogLevel: "ERROR",
@@ -208,6 +292,46 @@ func (svc *articleManagementService) DeleteArticleAll() error {
 	return nil
 }
 
+func (svc *articleManagementService) GetDeletedArticles() (*[]domain.Article, error) {
+	articles, err := svc.repo.GetDeletedArticles()
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  "Deleted articles found successufly",
+	}
+	svc.logger.LogInfo(logEntry)
+	return articles, nil
+}
+
+func (svc *articleManagementService) RestoreArticle(article_id string) (*domain.Article, error) {
+	article, err := svc.repo.RestoreArticle(article_id)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("Article with ID [%s] restored successufly", article_id),
+	}
+	svc.logger.LogInfo(logEntry)
+	return article, nil
+}
+
 type loggingManagementService struct {
 	loggerURL string
 }
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+)
+
+// sitemapPageSize is the number of entries read from the repository at once
+const sitemapPageSize = 1000
+
+func (svc *articleManagementService) CountPublishedArticles() (int, error) {
+	count, err := svc.repo.CountPublishedArticles()
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return 0, err
+	}
+	return count, nil
+}
+
+// StreamSitemapEntries calls fn for up to limit published articles, ordered
+// by ID and starting after the first offset ones. The repository skips to
+// the offset, then articles are read a page at a time, so memory use does
+// not grow with the number of articles.
+func (svc *articleManagementService) StreamSitemapEntries(offset, limit int, fn func(entry domain.SitemapEntry) error) error {
+	after := ""
+	for limit > 0 {
+		size := sitemapPageSize
+		if limit < size {
+			size = limit
+		}
+		page, err := svc.repo.GetSitemapPage(after, offset, size)
+		if err != nil {
+			logEntry := domain.LogMessage{
+				LogLevel: "ERROR",
+				Service:  "articles",
+				Message:  err.Error(),
+			}
+			svc.logger.LogError(logEntry)
+			return err
+		}
+		for _, entry := range page {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(page) < size {
+			break
+		}
+		after, offset = page[len(page)-1].ArticleID, 0
+		limit -= len(page)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// pagedRepository serves sitemap pages from a sorted list of IDs and records
+// how many entries it read to serve them.
+type pagedRepository struct {
+	ports.ArticleRepository
+	ids   []string
+	pages int
+	read  int
+}
+
+func (repo *pagedRepository) GetSitemapPage(after string, offset, limit int) ([]domain.SitemapEntry, error) {
+	repo.pages++
+	entries := []domain.SitemapEntry{}
+	for _, id := range repo.ids {
+		if id <= after {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if len(entries) < limit {
+			entries = append(entries, domain.SitemapEntry{ArticleID: id})
+		}
+	}
+	repo.read += len(entries)
+	return entries, nil
+}
+
+func TestStreamSitemapEntries(t *testing.T) {
+	repo := &pagedRepository{}
+	for i := 0; i < 2500; i++ {
+		repo.ids = append(repo.ids, fmt.Sprintf("a%04d", i))
+	}
+	articleService := NewArticleManagementService(repo, stubLogger{}, nil)
+
+	var got []string
+	err := articleService.StreamSitemapEntries(1200, 1000, func(entry domain.SitemapEntry) error {
+		got = append(got, entry.ArticleID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1000 || got[0] != "a1200" || got[999] != "a2199" {
+		t.Fatalf("Unexpected entries %d from %v", len(got), got[:1])
+	}
+	if repo.pages != 1 || repo.read != 1000 {
+		t.Errorf("Expected the entries to be read in one page from the offset, got %d pages of %d entries", repo.pages, repo.read)
+	}
+
+	// The last part stops at the end of the articles
+	got = nil
+	articleService.StreamSitemapEntries(2000, 1000, func(entry domain.SitemapEntry) error {
+		got = append(got, entry.ArticleID)
+		return nil
+	})
+	if len(got) != 500 {
+		t.Errorf("Expected 500 entries, got %d", len(got))
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// maxSlugSuffix is the highest numeric suffix tried before falling back to a
+// random one
+const maxSlugSuffix = 100
+
+// maxSlugAttempts bounds the writes of a new article losing its slug to a
+// concurrent write
+const maxSlugAttempts = 5
+
+func (svc *articleManagementService) GetArticleBySlug(slug string) (*domain.Article, error) {
+	article, err := svc.repo.GetArticleBySlug(slug)
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return nil, err
+	}
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("Article with slug [%s] found successufly", slug),
+	}
+	svc.logger.LogInfo(logEntry)
+	return article, nil
+}
+
+// uniqueSlug derives a slug from title that no other article uses or used,
+// appending -2, -3, ... on collisions. Slugs in reserved are taken by
+// articles that are not stored yet.
+func (svc *articleManagementService) uniqueSlug(title string, article_id string, reserved map[string]bool) (string, error) {
+	base := domain.Slugify(title)
+	if base == "" {
+		base = "article"
+	}
+
+	slug := base
+	for suffix := 2; suffix <= maxSlugSuffix; suffix++ {
+		if !reserved[slug] {
+			taken, err := svc.repo.IsSlugTaken(slug, article_id)
+			if err != nil {
+				return "", err
+			}
+			if !taken {
+				return slug, nil
+			}
+		}
+		slug = fmt.Sprintf("%s-%d", base, suffix)
+	}
+	return fmt.Sprintf("%s-%s", base, uuid.New().String()[:8]), nil
+}
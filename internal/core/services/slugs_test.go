@@ -0,0 +1,150 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// slugRepository reports the slugs in taken as used by another article
+type slugRepository struct {
+	ports.ArticleRepository
+	taken map[string]bool
+}
+
+func (repo slugRepository) IsSlugTaken(slug string, article_id string) (bool, error) {
+	return repo.taken[slug], nil
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Hello, World!":                "hello-world",
+		"  Go 1.21: what's new?  ":     "go-1-21-what-s-new",
+		"Crème brûlée à la française":  "creme-brulee-a-la-francaise",
+		"日本語":                          "",
+		"Hexagonal   architecture -- ": "hexagonal-architecture",
+	}
+	for title, expected := range cases {
+		if slug := domain.Slugify(title); slug != expected {
+			t.Errorf("Slugify(%q) = %q, expected %q", title, slug, expected)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	repo := slugRepository{taken: map[string]bool{
+		"hello-world":   true,
+		"hello-world-2": true,
+	}}
+	articleService := NewArticleManagementService(repo, stubLogger{}, nil)
+
+	slug, err := articleService.uniqueSlug("Hello World", "a1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slug != "hello-world-3" {
+		t.Errorf("Expected hello-world-3, got %s", slug)
+	}
+
+	slug, err = articleService.uniqueSlug("¡¿", "a1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slug != "article" {
+		t.Errorf("Expected fallback slug article, got %s", slug)
+	}
+
+	slug, err = articleService.uniqueSlug("Hello World", "a1", map[string]bool{"hello-world-3": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slug != "hello-world-4" {
+		t.Errorf("Expected the reserved slug skipped, got %s", slug)
+	}
+}
+
+// racingRepository loses the first slug it is asked to write to another
+// article created meanwhile. It holds a single article to update.
+type racingRepository struct {
+	slugRepository
+	raced   bool
+	created []domain.Article
+	updated []domain.Article
+}
+
+func (repo *racingRepository) GetArticleByID(article_id string) (*domain.Article, error) {
+	return &domain.Article{ArticleID: article_id, Title: "Hello", Slug: "hello"}, nil
+}
+
+func (repo *racingRepository) UpdateArticle(article_id string, article *domain.Article) (*domain.Article, error) {
+	if !repo.raced {
+		repo.raced = true
+		repo.taken[article.Slug] = true
+		return nil, domain.ErrSlugTaken
+	}
+	repo.updated = append(repo.updated, *article)
+	return article, nil
+}
+
+func (repo *racingRepository) CreateArticle(article *domain.Article) (*domain.Article, error) {
+	if !repo.raced {
+		repo.raced = true
+		repo.taken[article.Slug] = true
+		return nil, domain.ErrSlugTaken
+	}
+	repo.created = append(repo.created, *article)
+	return article, nil
+}
+
+func TestCreateArticleSlugRace(t *testing.T) {
+	repo := &racingRepository{slugRepository: slugRepository{taken: map[string]bool{}}}
+	articleService := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	article, err := articleService.CreateArticle(&domain.Article{Title: "Hello World"})
+	if err != nil {
+		t.Fatalf("CreateArticle: %v", err)
+	}
+	if article.Slug != "hello-world-2" || len(repo.created) != 1 {
+		t.Errorf("Expected the next slug written, got %q after %d writes", article.Slug, len(repo.created))
+	}
+}
+
+func TestUpdateArticleSlugRace(t *testing.T) {
+	repo := &racingRepository{slugRepository: slugRepository{taken: map[string]bool{}}}
+	articleService := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	article, err := articleService.UpdateArticle("1", &domain.Article{Title: "Hello World"})
+	if err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	if article.Slug != "hello-world-2" || len(repo.updated) != 1 {
+		t.Errorf("Expected the next slug written, got %q after %d writes", article.Slug, len(repo.updated))
+	}
+}
+
+func TestImportArticlesSlugs(t *testing.T) {
+	repo := &stubRepository{}
+	articleService := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	input := strings.Join([]string{
+		`{"title":"Same title"}`,
+		`{"title":"Same title"}`,
+		`{"title":"Other","slug":"same-title-3"}`,
+		`{"title":"Same title"}`,
+	}, "\n")
+	if _, err := articleService.ImportArticles(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	slugs := []string{}
+	for _, article := range repo.articles {
+		slugs = append(slugs, article.Slug)
+	}
+	want := []string{"same-title", "same-title-2", "same-title-3", "same-title-4"}
+	if !reflect.DeepEqual(slugs, want) {
+		t.Errorf("Expected distinct slugs within the batch %v, got %v", want, slugs)
+	}
+}
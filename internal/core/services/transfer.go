@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// importBatchSize is the number of articles written per transaction
+	importBatchSize = 100
+	// maxImportLineSize bounds a single NDJSON line, i.e. one article
+	maxImportLineSize = 10 * 1024 * 1024
+)
+
+// ExportArticles writes every article to w as newline delimited JSON, one
+// article per line.
+func (svc *articleManagementService) ExportArticles(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := svc.repo.StreamArticles(func(article *domain.Article) error {
+		count++
+		return encoder.Encode(article)
+	})
+	if err != nil {
+		logEntry := domain.LogMessage{
+			LogLevel: "ERROR",
+			Service:  "articles",
+			Message:  err.Error(),
+		}
+		svc.logger.LogError(logEntry)
+		return err
+	}
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("%d articles exported successufly", count),
+	}
+	svc.logger.LogInfo(logEntry)
+	return nil
+}
+
+// ImportArticles reads newline delimited JSON articles from r and upserts
+// them in batches. Lines that cannot be decoded or written are reported in
+// the returned ImportReport rather than aborting the import.
+func (svc *articleManagementService) ImportArticles(r io.Reader) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{Errors: []domain.ImportError{}}
+	batch := []domain.Article{}
+	batchLines := []int{}
+	// reserved holds the slugs given to the articles imported so far, the
+	// repository only knows those of flushed batches
+	reserved := map[string]bool{}
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := svc.repo.UpsertArticles(batch)
+		if err != nil {
+			return err
+		}
+		for i, err := range results {
+			if err != nil {
+				report.Errors = append(report.Errors, domain.ImportError{Line: batchLines[i], Error: err.Error()})
+				report.Failed++
+				continue
+			}
+			report.Imported++
+		}
+		batch = batch[:0]
+		batchLines = batchLines[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		article, err := decodeImportedArticle(text)
+		if err == nil {
+			err = svc.setReadingStats(article)
+		}
+		if err == nil && article.Slug == "" {
+			article.Slug, err = svc.uniqueSlug(article.Title, article.ArticleID, reserved)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, domain.ImportError{Line: line, Error: err.Error()})
+			report.Failed++
+			continue
+		}
+		reserved[article.Slug] = true
+		batch = append(batch, *article)
+		batchLines = append(batchLines, line)
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return svc.failImport(report, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return svc.failImport(report, err)
+	}
+	if err := flush(); err != nil {
+		return svc.failImport(report, err)
+	}
+
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  fmt.Sprintf("%d articles imported, %d failed", report.Imported, report.Failed),
+	}
+	svc.logger.LogInfo(logEntry)
+	return report, nil
+}
+
+func (svc *articleManagementService) failImport(report *domain.ImportReport, err error) (*domain.ImportReport, error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	svc.logger.LogError(logEntry)
+	return report, err
+}
+
+func decodeImportedArticle(line string) (*domain.Article, error) {
+	var article domain.Article
+	if err := json.Unmarshal([]byte(line), &article); err != nil {
+		return nil, err
+	}
+	if article.Title == "" {
+		return nil, errors.New("article title is required")
+	}
+	if article.ArticleID == "" {
+		article.ArticleID = uuid.New().String()
+	}
+	now := time.Now()
+	if article.PublishDate.IsZero() {
+		article.PublishDate = now
+	}
+	if article.UpdatedDate.IsZero() {
+		article.UpdatedDate = now
+	}
+	return &article, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/adapters/markdown"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// stubRepository records upserted articles. Methods the tests do not need
+// are left to the embedded nil interface.
+type stubRepository struct {
+	ports.ArticleRepository
+	articles []domain.Article
+	batches  int
+}
+
+func (repo *stubRepository) StreamArticles(fn func(article *domain.Article) error) error {
+	for i := range repo.articles {
+		if err := fn(&repo.articles[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *stubRepository) IsSlugTaken(slug string, article_id string) (bool, error) {
+	return false, nil
+}
+
+func (repo *stubRepository) UpsertArticles(articles []domain.Article) ([]error, error) {
+	repo.batches++
+	results := make([]error, len(articles))
+	for i, article := range articles {
+		if article.Title == "duplicate" {
+			results[i] = errors.New("duplicate article")
+			continue
+		}
+		repo.articles = append(repo.articles, article)
+	}
+	return results, nil
+}
+
+type stubLogger struct{}
+
+func (stubLogger) SendLog(domain.LogMessage)    {}
+func (stubLogger) LogDebug(domain.LogMessage)   {}
+func (stubLogger) LogInfo(domain.LogMessage)    {}
+func (stubLogger) LogWarning(domain.LogMessage) {}
+func (stubLogger) LogError(domain.LogMessage)   {}
+
+func TestImportExportArticles(t *testing.T) {
+	repo := &stubRepository{}
+	articleService := NewArticleManagementService(repo, stubLogger{}, markdown.NewMarkdownRenderer())
+
+	input := strings.Join([]string{
+		`{"article_id":"a1","title":"First"}`,
+		`not json`,
+		``,
+		`{"subtitle":"missing title"}`,
+		`{"title":"duplicate"}`,
+		`{"title":"Second"}`,
+	}, "\n")
+
+	report, err := articleService.ImportArticles(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("Expected 2 imported and 3 failed, got %+v", report)
+	}
+	lines := []int{}
+	for _, importErr := range report.Errors {
+		lines = append(lines, importErr.Line)
+	}
+	if len(lines) != 3 || lines[0] != 2 || lines[1] != 4 || lines[2] != 5 {
+		t.Errorf("Expected errors on lines 2, 4 and 5, got %v", lines)
+	}
+	if repo.articles[1].ArticleID == "" {
+		t.Error("Expected an article ID to be generated for imported article")
+	}
+
+	var out bytes.Buffer
+	if err := articleService.ExportArticles(&out); err != nil {
+		t.Fatal(err)
+	}
+	decoder := json.NewDecoder(&out)
+	exported := 0
+	for decoder.More() {
+		var article domain.Article
+		if err := decoder.Decode(&article); err != nil {
+			t.Fatal(err)
+		}
+		exported++
+	}
+	if exported != 2 {
+		t.Errorf("Expected 2 exported articles, got %d", exported)
+	}
+}
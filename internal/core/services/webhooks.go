@@ -0,0 +1,412 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+const (
+	// SignatureHeader carries "sha256=<hex>", the HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed with the subscription secret
+	SignatureHeader = "X-Notelify-Signature"
+	// TimestampHeader carries the unix time the signature was made at, so
+	// receivers can reject replayed deliveries
+	TimestampHeader = "X-Notelify-Timestamp"
+	EventTypeHeader = "X-Notelify-Event"
+	DeliveryHeader  = "X-Notelify-Delivery"
+
+	// deliveryLease is how long a claimed delivery is hidden from other
+	// workers while it is being sent
+	deliveryLease = time.Minute
+	// maxRetryDelay caps the exponential backoff between attempts
+	maxRetryDelay = 6 * time.Hour
+)
+
+type webhookManagementService struct {
+	repo        ports.WebhookRepository
+	logger      ports.LoggingService
+	client      *http.Client
+	maxAttempts int
+	retryBase   time.Duration
+	now         func() time.Time
+}
+
+// NewWebhookManagementService manages webhook subscriptions and delivers
+// events to them. It is also the ports.EventPublisher the outbox relay hands
+// events to: publishing records one delivery per matching subscription,
+// which DeliverDue then sends.
+func NewWebhookManagementService(repo ports.WebhookRepository, logger ports.LoggingService, timeout time.Duration, maxAttempts int, retryBase time.Duration) *webhookManagementService {
+	svc := webhookManagementService{
+		repo:        repo,
+		logger:      logger,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		retryBase:   retryBase,
+		now:         time.Now,
+	}
+	return &svc
+}
+
+func (svc *webhookManagementService) CreateSubscription(subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+	// New subscriptions start active, they are paused with UpdateSubscription
+	subscription.Active = true
+	subscription.SubscriptionID = uuid.New().String()
+	subscription.CreatedDate = svc.now()
+	subscription.UpdatedDate = subscription.CreatedDate
+
+	subscription, err := svc.repo.CreateSubscription(subscription)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	svc.logInfo(fmt.Sprintf("Webhook subscription with ID [%s] created successufly", subscription.SubscriptionID))
+	// The secret is only shown when the subscription is created
+	return subscription, nil
+}
+
+func (svc *webhookManagementService) GetSubscription(subscription_id string) (*domain.WebhookSubscription, error) {
+	subscription, err := svc.repo.GetSubscription(subscription_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (svc *webhookManagementService) GetSubscriptions() (*[]domain.WebhookSubscription, error) {
+	subscriptions, err := svc.repo.GetSubscriptions()
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	for i := range *subscriptions {
+		(*subscriptions)[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription replaces the URL, event filter and active flag of a
+// subscription. The secret is rotated only when a new one is given.
+func (svc *webhookManagementService) UpdateSubscription(subscription_id string, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	existing, err := svc.repo.GetSubscription(subscription_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = existing.Secret
+	}
+	subscription.SubscriptionID = subscription_id
+	subscription.CreatedDate = existing.CreatedDate
+	subscription.UpdatedDate = svc.now()
+
+	subscription, err = svc.repo.UpdateSubscription(subscription_id, subscription)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	svc.logInfo(fmt.Sprintf("Webhook subscription with ID [%s] updated successufly", subscription_id))
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (svc *webhookManagementService) DeleteSubscription(subscription_id string) error {
+	if err := svc.repo.DeleteSubscription(subscription_id); err != nil {
+		svc.logError(err)
+		return err
+	}
+	svc.logInfo(fmt.Sprintf("Webhook subscription with ID [%s] deleted successufly", subscription_id))
+	return nil
+}
+
+func (svc *webhookManagementService) GetDeliveries(subscription_id string) (*[]domain.WebhookDelivery, error) {
+	deliveries, err := svc.repo.GetDeliveries(subscription_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (svc *webhookManagementService) GetDeadDeliveries() (*[]domain.WebhookDelivery, error) {
+	deliveries, err := svc.repo.GetDeadDeliveries()
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery moves a dead delivery back to pending with a fresh set of
+// attempts. It is picked up by the next DeliverDue run.
+func (svc *webhookManagementService) RetryDelivery(delivery_id string) (*domain.WebhookDelivery, error) {
+	delivery, err := svc.repo.GetDelivery(delivery_id)
+	if err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	if delivery.Status != domain.DeliveryDead {
+		return nil, fmt.Errorf("delivery is %s, only dead deliveries can be retried", delivery.Status)
+	}
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = svc.now()
+	delivery.UpdatedDate = delivery.NextAttemptAt
+	if err := svc.repo.UpdateDelivery(delivery); err != nil {
+		svc.logError(err)
+		return nil, err
+	}
+	svc.logInfo(fmt.Sprintf("Webhook delivery with ID [%s] queued for retry successufly", delivery_id))
+	return delivery, nil
+}
+
+// Publish records a pending delivery of event for every active subscription
+// whose filter matches it.
+func (svc *webhookManagementService) Publish(event domain.Event) error {
+	subscriptions, err := svc.repo.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	now := svc.now()
+	deliveries := []domain.WebhookDelivery{}
+	for _, subscription := range *subscriptions {
+		if !subscription.Matches(event.EventType) {
+			continue
+		}
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			DeliveryID:     uuid.New().String(),
+			SubscriptionID: subscription.SubscriptionID,
+			Event:          event,
+			Status:         domain.DeliveryPending,
+			NextAttemptAt:  now,
+			Log:            []domain.WebhookAttempt{},
+			CreatedDate:    now,
+			UpdatedDate:    now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return svc.repo.CreateDeliveries(deliveries)
+}
+
+// Run sends due deliveries every interval until stop is closed.
+func (svc *webhookManagementService) Run(stop <-chan struct{}, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			sent, err := svc.DeliverDue(batchSize)
+			if err != nil || sent < batchSize {
+				break
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were
+// attempted. Failed attempts are rescheduled with exponential backoff until
+// the subscription's delivery runs out of attempts and becomes dead.
+// Deliveries of paused subscriptions stay pending until they are resumed.
+func (svc *webhookManagementService) DeliverDue(batchSize int) (int, error) {
+	deliveries, err := svc.repo.ClaimDueDeliveries(batchSize, deliveryLease)
+	if err != nil {
+		svc.logError(err)
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, err := svc.repo.GetSubscription(delivery.SubscriptionID)
+		if err != nil {
+			// Deliveries go with their subscription, so one left without
+			// it cannot be sent. It can still be retried from the dead
+			// letters.
+			svc.bury(delivery, fmt.Errorf("loading subscription [%s] failed: %v", delivery.SubscriptionID, err))
+		} else if !svc.attempt(subscription, delivery) {
+			continue
+		}
+		if err := svc.repo.UpdateDelivery(delivery); err != nil {
+			svc.logError(err)
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// bury moves delivery to the dead letters without sending it.
+func (svc *webhookManagementService) bury(delivery *domain.WebhookDelivery, err error) {
+	delivery.UpdatedDate = svc.now()
+	delivery.Status = domain.DeliveryDead
+	delivery.Log = append(delivery.Log, domain.WebhookAttempt{AttemptedAt: delivery.UpdatedDate, Error: err.Error()})
+	svc.logError(fmt.Errorf("webhook delivery [%s] is dead: %v", delivery.DeliveryID, err))
+}
+
+// attempt sends delivery once and records the outcome on it. It returns
+// false, leaving the delivery untouched, when the subscription is paused.
+func (svc *webhookManagementService) attempt(subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) bool {
+	if !subscription.Active {
+		return false
+	}
+
+	started := svc.now()
+	statusCode, err := svc.send(subscription, delivery)
+
+	entry := domain.WebhookAttempt{
+		AttemptedAt: started,
+		StatusCode:  statusCode,
+		DurationMS:  svc.now().Sub(started).Milliseconds(),
+	}
+	delivery.Attempts++
+	delivery.UpdatedDate = svc.now()
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.DeliverySucceeded
+	case delivery.Attempts >= svc.maxAttempts:
+		entry.Error = err.Error()
+		delivery.Status = domain.DeliveryDead
+		svc.logError(fmt.Errorf("webhook delivery [%s] is dead after %d attempts: %v", delivery.DeliveryID, delivery.Attempts, err))
+	default:
+		entry.Error = err.Error()
+		delivery.NextAttemptAt = delivery.UpdatedDate.Add(backoff(svc.retryBase, delivery.Attempts))
+	}
+	delivery.Log = append(delivery.Log, entry)
+	return true
+}
+
+func (svc *webhookManagementService) send(subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	// Marshal the event into JSON
+	payloadBytes, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(svc.now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, delivery.Event.EventType)
+	req.Header.Set(DeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+SignPayload(subscription.Secret, timestamp, payloadBytes))
+
+	resp, err := svc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles base for every failed attempt after the first, up to
+// maxRetryDelay.
+func backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func (svc *webhookManagementService) logError(err error) {
+	logEntry := domain.LogMessage{
+		LogLevel: "ERROR",
+		Service:  "articles",
+		Message:  err.Error(),
+	}
+	svc.logger.LogError(logEntry)
+}
+
+func (svc *webhookManagementService) logInfo(message string) {
+	logEntry := domain.LogMessage{
+		LogLevel: "INFO",
+		Service:  "articles",
+		Message:  message,
+	}
+	svc.logger.LogInfo(logEntry)
+}
+
+// SignPayload returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// secret, the value receivers recompute to verify a delivery.
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateSubscription(subscription *domain.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+	known := map[string]bool{
+		domain.EventArticleCreated:   true,
+		domain.EventArticleUpdated:   true,
+		domain.EventArticlePublished: true,
+		domain.EventArticleDeleted:   true,
+		domain.EventArticleRestored:  true,
+	}
+	for _, eventType := range subscription.Events {
+		if !known[eventType] {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AntonyIS/notelify-articles-service/internal/core/domain"
+	"github.com/AntonyIS/notelify-articles-service/internal/core/ports"
+)
+
+// stubWebhookRepository keeps subscriptions and deliveries in memory
+type stubWebhookRepository struct {
+	ports.WebhookRepository
+	subscriptions []domain.WebhookSubscription
+	deliveries    []domain.WebhookDelivery
+	now           func() time.Time
+}
+
+func (r *stubWebhookRepository) GetSubscription(subscription_id string) (*domain.WebhookSubscription, error) {
+	for _, subscription := range r.subscriptions {
+		if subscription.SubscriptionID == subscription_id {
+			return &subscription, nil
+		}
+	}
+	return nil, io.EOF
+}
+
+func (r *stubWebhookRepository) GetSubscriptions() (*[]domain.WebhookSubscription, error) {
+	subscriptions := append([]domain.WebhookSubscription{}, r.subscriptions...)
+	return &subscriptions, nil
+}
+
+func (r *stubWebhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *stubWebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	due := []domain.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.DeliveryPending && !delivery.NextAttemptAt.After(r.now()) && len(due) < limit {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (r *stubWebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].DeliveryID == delivery.DeliveryID {
+			r.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	status := http.StatusInternalServerError
+	var signature, timestamp string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubWebhookRepository{
+		subscriptions: []domain.WebhookSubscription{
+			{SubscriptionID: "all", URL: server.URL, Secret: "s3cret", Active: true},
+			{SubscriptionID: "deletes", URL: server.URL, Secret: "other", Events: []string{domain.EventArticleDeleted}, Active: true},
+			{SubscriptionID: "inactive", URL: server.URL, Secret: "other", Active: false},
+		},
+		now: func() time.Time { return now },
+	}
+	svc := NewWebhookManagementService(repo, stubLogger{}, time.Second, 3, 30*time.Second)
+	svc.now = repo.now
+
+	article := &domain.Article{ArticleID: "a1"}
+	if err := svc.Publish(domain.NewArticleEvent(domain.EventArticleUpdated, article)); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.deliveries) != 1 || repo.deliveries[0].SubscriptionID != "all" {
+		t.Fatalf("Expected one delivery to the matching subscription, got %+v", repo.deliveries)
+	}
+
+	// A failing receiver is retried with exponential backoff
+	for attempt, wait := range []time.Duration{30 * time.Second, time.Minute} {
+		if sent, err := svc.DeliverDue(10); err != nil || sent != 1 {
+			t.Fatalf("Attempt %d: expected one delivery sent, got %d (%v)", attempt+1, sent, err)
+		}
+		delivery := repo.deliveries[0]
+		if delivery.Status != domain.DeliveryPending || delivery.Attempts != attempt+1 {
+			t.Fatalf("Attempt %d: expected pending delivery, got %s with %d attempts", attempt+1, delivery.Status, delivery.Attempts)
+		}
+		if !delivery.NextAttemptAt.Equal(now.Add(wait)) {
+			t.Errorf("Attempt %d: expected next attempt after %s, got %s", attempt+1, wait, delivery.NextAttemptAt.Sub(now))
+		}
+		if sent, _ := svc.DeliverDue(10); sent != 0 {
+			t.Errorf("Attempt %d: expected no delivery before the backoff elapsed", attempt+1)
+		}
+		now = delivery.NextAttemptAt
+	}
+
+	if want := "sha256=" + SignPayload("s3cret", timestamp, body); signature != want {
+		t.Errorf("Expected signature %q, got %q", want, signature)
+	}
+
+	// The last attempt moves the delivery to the dead letters
+	svc.DeliverDue(10)
+	delivery := repo.deliveries[0]
+	if delivery.Status != domain.DeliveryDead || len(delivery.Log) != 3 {
+		t.Fatalf("Expected dead delivery with three logged attempts, got %s with %d", delivery.Status, len(delivery.Log))
+	}
+	if delivery.Log[2].StatusCode != http.StatusInternalServerError || delivery.Log[2].Error == "" {
+		t.Errorf("Expected failed attempt to be logged, got %+v", delivery.Log[2])
+	}
+
+	status = http.StatusNoContent
+	repo.deliveries[0].Status = domain.DeliveryPending
+	repo.deliveries[0].NextAttemptAt = now
+	svc.DeliverDue(10)
+	if repo.deliveries[0].Status != domain.DeliverySucceeded {
+		t.Errorf("Expected delivery to succeed, got %s", repo.deliveries[0].Status)
+	}
+}
+
+func TestWebhookDeliveryWithoutActiveSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := domain.NewArticleEvent(domain.EventArticleUpdated, &domain.Article{ArticleID: "a1"})
+	repo := &stubWebhookRepository{
+		subscriptions: []domain.WebhookSubscription{
+			{SubscriptionID: "paused", URL: server.URL, Secret: "s3cret", Active: false},
+		},
+		deliveries: []domain.WebhookDelivery{
+			{DeliveryID: "d1", SubscriptionID: "paused", Event: event, Status: domain.DeliveryPending, NextAttemptAt: now},
+			{DeliveryID: "d2", SubscriptionID: "removed", Event: event, Status: domain.DeliveryPending, NextAttemptAt: now},
+		},
+		now: func() time.Time { return now },
+	}
+	svc := NewWebhookManagementService(repo, stubLogger{}, time.Second, 3, 30*time.Second)
+	svc.now = repo.now
+
+	if sent, err := svc.DeliverDue(10); err != nil || sent != 1 {
+		t.Fatalf("Expected only the delivery of the removed subscription to be handled, got %d (%v)", sent, err)
+	}
+	if delivery := repo.deliveries[0]; delivery.Status != domain.DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("Expected the delivery of the paused subscription to stay pending, got %s with %d attempts", delivery.Status, delivery.Attempts)
+	}
+	if delivery := repo.deliveries[1]; delivery.Status != domain.DeliveryDead || len(delivery.Log) != 1 {
+		t.Errorf("Expected the delivery of the removed subscription to be dead, got %s", delivery.Status)
+	}
+
+	repo.subscriptions[0].Active = true
+	svc.DeliverDue(10)
+	if repo.deliveries[0].Status != domain.DeliverySucceeded {
+		t.Errorf("Expected the delivery to be sent once the subscription is resumed, got %s", repo.deliveries[0].Status)
+	}
+}
+
+func TestValidateSubscription(t *testing.T) {
+	tests := []struct {
+		subscription domain.WebhookSubscription
+		valid        bool
+	}{
+		{domain.WebhookSubscription{URL: "https://partner.example/hooks"}, true},
+		{domain.WebhookSubscription{URL: "https://partner.example/hooks", Events: []string{domain.EventArticlePublished}}, true},
+		{domain.WebhookSubscription{URL: "https://partner.example/hooks", Events: []string{"article.liked"}}, false},
+		{domain.WebhookSubscription{URL: "/hooks"}, false},
+		{domain.WebhookSubscription{URL: "ftp://partner.example/hooks"}, false},
+	}
+	for _, test := range tests {
+		err := validateSubscription(&test.subscription)
+		if (err == nil) != test.valid {
+			t.Errorf("validateSubscription(%+v) = %v, want valid %v", test.subscription, err, test.valid)
+		}
+	}
+}
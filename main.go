@@ -1,9 +1,11 @@
 package main
 
 import (
+	"os"
+
 	"github.com/AntonyIS/notelify-articles-service/cmd"
 )
 
 func main() {
-	cmd.RunService()
+	cmd.Execute(os.Args[1:])
 }